@@ -0,0 +1,55 @@
+// Package blobstore menyediakan abstraksi penyimpanan file (blob) seperti gambar marker,
+// dengan implementasi Google Cloud Storage dan filesystem lokal.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrNotFound dikembalikan ketika blob dengan key tertentu tidak ada.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore adalah antarmuka penyimpanan blob. Key berupa path relatif dengan pemisah "/",
+// misalnya "markers/<marker_id>/<image_id>.jpg".
+type BlobStore interface {
+	// Put menyimpan data ke key tertentu, menimpa blob lama jika ada.
+	Put(ctx context.Context, key string, data io.Reader, contentType string) error
+	// Get membuka blob untuk dibaca. Pemanggil wajib menutup reader yang dikembalikan.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete menghapus blob. Menghapus blob yang tidak ada tidak dianggap error.
+	Delete(ctx context.Context, key string) error
+	// URL mengembalikan URL publik untuk mengakses blob.
+	URL(key string) string
+}
+
+// NewFromEnv membuat BlobStore berdasarkan variabel lingkungan:
+//   - BLOB_STORE: "local" (default) atau "gcs"
+//   - LOCAL_STORAGE_DIR (default "./uploads") dan LOCAL_STORAGE_URL_PREFIX (default "/uploads") untuk local
+//   - GCS_BUCKET, GCS_PUBLIC_BASE_URL (opsional), dan GOOGLE_APPLICATION_CREDENTIALS_JSON (opsional) untuk gcs
+func NewFromEnv(ctx context.Context) (BlobStore, error) {
+	switch strings.ToLower(os.Getenv("BLOB_STORE")) {
+	case "", "local":
+		dir := os.Getenv("LOCAL_STORAGE_DIR")
+		if dir == "" {
+			dir = "./uploads"
+		}
+		prefix := os.Getenv("LOCAL_STORAGE_URL_PREFIX")
+		if prefix == "" {
+			prefix = "/uploads"
+		}
+		return NewLocalStore(dir, prefix)
+	case "gcs":
+		bucket := os.Getenv("GCS_BUCKET")
+		if bucket == "" {
+			return nil, fmt.Errorf("GCS_BUCKET must be set when BLOB_STORE=gcs")
+		}
+		return NewGCSStore(ctx, bucket, os.Getenv("GCS_PUBLIC_BASE_URL"), os.Getenv("GOOGLE_APPLICATION_CREDENTIALS_JSON"))
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE %q (use local or gcs)", os.Getenv("BLOB_STORE"))
+	}
+}
@@ -0,0 +1,77 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// GCSStore menyimpan blob di bucket Google Cloud Storage.
+type GCSStore struct {
+	client        *storage.Client
+	bucket        string
+	publicBaseURL string
+}
+
+// NewGCSStore membuat GCSStore. Jika credentialsJSON kosong, Application Default Credentials dipakai
+// (misalnya GOOGLE_APPLICATION_CREDENTIALS atau service account Cloud Run).
+// publicBaseURL opsional; default-nya https://storage.googleapis.com/<bucket>.
+func NewGCSStore(ctx context.Context, bucket, publicBaseURL, credentialsJSON string) (*GCSStore, error) {
+	var opts []option.ClientOption
+	if credentialsJSON != "" {
+		opts = append(opts, option.WithCredentialsJSON([]byte(credentialsJSON)))
+	}
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	if publicBaseURL == "" {
+		publicBaseURL = "https://storage.googleapis.com/" + bucket
+	}
+	return &GCSStore{client: client, bucket: bucket, publicBaseURL: strings.TrimSuffix(publicBaseURL, "/")}, nil
+}
+
+// Put mengunggah blob ke bucket.
+func (s *GCSStore) Put(ctx context.Context, key string, data io.Reader, contentType string) error {
+	w := s.client.Bucket(s.bucket).Object(key).NewWriter(ctx)
+	w.ContentType = contentType
+	w.CacheControl = "public, max-age=31536000, immutable"
+	if _, err := io.Copy(w, data); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return w.Close()
+}
+
+// Get membuka reader untuk blob di bucket.
+func (s *GCSStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	r, err := s.client.Bucket(s.bucket).Object(key).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, ErrNotFound
+	}
+	return r, err
+}
+
+// Delete menghapus blob dari bucket.
+func (s *GCSStore) Delete(ctx context.Context, key string) error {
+	err := s.client.Bucket(s.bucket).Object(key).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
+
+// URL mengembalikan URL publik objek.
+func (s *GCSStore) URL(key string) string {
+	return s.publicBaseURL + "/" + strings.TrimPrefix(key, "/")
+}
+
+// Close menutup koneksi klien GCS.
+func (s *GCSStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,90 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore menyimpan blob di filesystem lokal. Cocok untuk pengembangan atau deployment satu server;
+// file disajikan oleh router pada URLPrefix.
+type LocalStore struct {
+	Dir       string // Direktori root penyimpanan
+	URLPrefix string // Prefix URL publik, misalnya "/uploads"
+}
+
+// NewLocalStore membuat LocalStore dan memastikan direktori root tersedia.
+func NewLocalStore(dir, urlPrefix string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create local storage dir: %w", err)
+	}
+	return &LocalStore{Dir: dir, URLPrefix: strings.TrimSuffix(urlPrefix, "/")}, nil
+}
+
+// pathFor mengubah key menjadi path file dan menolak key yang keluar dari direktori root.
+func (s *LocalStore) pathFor(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(cleaned)), nil
+}
+
+// Put menulis blob ke file sementara lalu me-rename-nya agar pembaca tidak melihat file setengah jadi.
+func (s *LocalStore) Put(ctx context.Context, key string, data io.Reader, contentType string) error {
+	target, err := s.pathFor(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// Get membuka file blob.
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	target, err := s.pathFor(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(target)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete menghapus file blob jika ada.
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	target, err := s.pathFor(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// URL mengembalikan URL publik blob di bawah URLPrefix.
+func (s *LocalStore) URL(key string) string {
+	return s.URLPrefix + path.Clean("/"+key)
+}
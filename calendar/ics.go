@@ -0,0 +1,200 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ICSEvent adalah satu VEVENT di feed iCalendar.
+type ICSEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time // Dalam zona waktu event; BYDAY pada Rule dihitung di zona ini
+	End         time.Time
+	Rule        *Rule
+	Latitude    float64
+	Longitude   float64
+	Updated     time.Time
+}
+
+// icsTimezoneYears adalah rentang tahun setelah event terakhir yang transisinya ikut ditulis di VTIMEZONE.
+const icsTimezoneYears = 10
+
+// WriteICS menulis feed iCalendar (RFC 5545) berisi events. DTSTART dan DTEND ditulis sebagai waktu lokal
+// dengan TZID zona waktu Start, disertai komponen VTIMEZONE untuk setiap zona, sehingga RRULE (termasuk BYDAY)
+// dihitung aplikasi kalender di zona yang sama dengan ekspansi di server, juga untuk zona yang memakai
+// daylight saving. Start dengan zona UTC atau Local ditulis dalam UTC.
+func WriteICS(w io.Writer, name string, events []ICSEvent) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//ulyngo//Events//ID",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeText(name),
+	}
+
+	// Rentang waktu yang perlu dicakup VTIMEZONE untuk setiap zona, urut sesuai kemunculan pertama
+	zoneNames := []string{}
+	zones := map[string]*timezoneSpan{}
+	for _, event := range events {
+		id := tzid(event.Start)
+		if id == "" {
+			continue
+		}
+		until := event.End.AddDate(icsTimezoneYears, 0, 0)
+		if span, ok := zones[id]; ok {
+			if event.Start.Before(span.from) {
+				span.from = event.Start
+			}
+			if until.After(span.to) {
+				span.to = until
+			}
+			continue
+		}
+		zoneNames = append(zoneNames, id)
+		zones[id] = &timezoneSpan{location: event.Start.Location(), from: event.Start, to: until}
+	}
+	for _, id := range zoneNames {
+		lines = append(lines, zones[id].lines()...)
+	}
+
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+event.UID,
+			"DTSTAMP:"+formatUTC(event.Updated),
+			"LAST-MODIFIED:"+formatUTC(event.Updated),
+			"DTSTART"+formatDateTime(event.Start),
+			"DTEND"+formatDateTime(event.End.In(event.Start.Location())),
+			"SUMMARY:"+escapeText(event.Summary),
+		)
+		if event.Rule != nil {
+			lines = append(lines, "RRULE:"+event.Rule.String())
+		}
+		if event.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if event.Location != "" {
+			lines = append(lines, "LOCATION:"+escapeText(event.Location))
+		}
+		lines = append(lines, fmt.Sprintf("GEO:%.6f;%.6f", event.Latitude, event.Longitude))
+		if event.URL != "" {
+			lines = append(lines, "URL:"+event.URL)
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldLine(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// formatDateTime mengembalikan parameter dan nilai properti tanggal-waktu, misalnya
+// ";TZID=Asia/Jakarta:20250101T190000", atau ":20250101T120000Z" untuk waktu tanpa TZID.
+func formatDateTime(t time.Time) string {
+	if id := tzid(t); id != "" {
+		return ";TZID=" + id + ":" + t.Format("20060102T150405")
+	}
+	return ":" + formatUTC(t)
+}
+
+// tzid mengembalikan nama zona IANA dari t, atau string kosong jika t memakai UTC atau zona Local
+// yang namanya tidak bisa dipakai sebagai TZID.
+func tzid(t time.Time) string {
+	switch name := t.Location().String(); name {
+	case "", "UTC", "Local":
+		return ""
+	default:
+		return name
+	}
+}
+
+// timezoneSpan adalah zona waktu dan rentang waktu yang transisinya perlu ditulis di VTIMEZONE.
+type timezoneSpan struct {
+	location *time.Location
+	from, to time.Time
+}
+
+// lines menyusun komponen VTIMEZONE dari data zona waktu Go. Setiap periode offset antara from dan to
+// ditulis sebagai satu observance STANDARD atau DAYLIGHT dengan DTSTART pada saat transisinya.
+func (z *timezoneSpan) lines() []string {
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + z.location.String()}
+	t := z.from.In(z.location)
+	for {
+		name, offset := t.Zone()
+		start, end := t.ZoneBounds()
+		previousOffset := offset
+		// DTSTART observance adalah waktu lokal menurut offset sebelum transisi
+		dtstart := "19700101T000000"
+		if !start.IsZero() {
+			_, previousOffset = start.Add(-time.Second).Zone()
+			dtstart = start.UTC().Add(time.Duration(previousOffset) * time.Second).Format("20060102T150405")
+		}
+		kind := "STANDARD"
+		if t.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		lines = append(lines,
+			"BEGIN:"+kind,
+			"DTSTART:"+dtstart,
+			"TZOFFSETFROM:"+formatOffset(previousOffset),
+			"TZOFFSETTO:"+formatOffset(offset),
+			"TZNAME:"+name,
+			"END:"+kind,
+		)
+		if end.IsZero() || !end.Before(z.to) {
+			break
+		}
+		t = end
+	}
+	return append(lines, "END:VTIMEZONE")
+}
+
+// formatOffset mengubah offset dalam detik menjadi format UTC-OFFSET iCalendar, misalnya "+0700".
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+}
+
+// escapeText meng-escape karakter khusus pada nilai TEXT iCalendar.
+func escapeText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// foldLine memecah baris yang lebih panjang dari 75 oktet menjadi beberapa baris lanjutan,
+// tanpa memotong karakter UTF-8 multi-byte.
+func foldLine(line string) string {
+	const maxOctets = 75
+	if len(line) <= maxOctets {
+		return line
+	}
+	var builder strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > maxOctets {
+			builder.WriteString("\r\n ")
+			width = 1
+		}
+		builder.WriteRune(r)
+		width += size
+	}
+	return builder.String()
+}
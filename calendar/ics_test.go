@@ -0,0 +1,180 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestWriteICS(t *testing.T) {
+	jakarta := mustLocation(t, "Asia/Jakarta")
+	newYork := mustLocation(t, "America/New_York")
+	updated := time.Date(2024, 12, 20, 8, 30, 0, 0, time.UTC)
+	weekly := func(start time.Time, value string) *Rule {
+		rule, err := ParseRule(value, start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rule
+	}
+	jazz := ICSEvent{
+		UID:       "event-1@ulyngo",
+		Summary:   "Jazz; Blues, and more",
+		Location:  "Braga, Bandung",
+		URL:       "https://ulyngo.example/events/1",
+		Start:     time.Date(2025, 1, 3, 19, 0, 0, 0, jakarta),
+		End:       time.Date(2025, 1, 3, 15, 0, 0, 0, time.UTC), // Zona End berbeda dari Start
+		Latitude:  -6.917464,
+		Longitude: 107.609505,
+		Updated:   updated,
+	}
+	jazz.Rule = weekly(jazz.Start, "FREQ=WEEKLY;BYDAY=FR")
+
+	tests := []struct {
+		name        string
+		events      []ICSEvent
+		wantLines   []string
+		wantMissing []string
+		wantCount   map[string]int
+	}{
+		{
+			name:   "local time with tzid and vtimezone",
+			events: []ICSEvent{jazz},
+			wantLines: []string{
+				"X-WR-CALNAME:Acara Bandung\\, Jawa Barat",
+				"BEGIN:VTIMEZONE", "TZID:Asia/Jakarta", "TZOFFSETTO:+0700", "TZNAME:WIB",
+				"DTSTART;TZID=Asia/Jakarta:20250103T190000",
+				"DTEND;TZID=Asia/Jakarta:20250103T220000",
+				"DTSTAMP:20241220T083000Z",
+				"RRULE:FREQ=WEEKLY;BYDAY=FR",
+				"SUMMARY:Jazz\\; Blues\\, and more",
+				"LOCATION:Braga\\, Bandung",
+				"GEO:-6.917464;107.609505",
+			},
+			wantMissing: []string{"DESCRIPTION:"},
+		},
+		{
+			name: "utc start has no vtimezone",
+			events: []ICSEvent{{
+				UID: "event-2@ulyngo", Summary: "Pasar malam", Updated: updated,
+				Start: time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC), End: time.Date(2025, 1, 3, 14, 0, 0, 0, time.UTC),
+			}},
+			wantLines:   []string{"DTSTART:20250103T120000Z", "DTEND:20250103T140000Z"},
+			wantMissing: []string{"BEGIN:VTIMEZONE", "RRULE:", "URL:"},
+		},
+		{
+			name: "daylight saving transitions",
+			events: []ICSEvent{{
+				UID: "event-3@ulyngo", Summary: "Brunch", Updated: updated,
+				Start: time.Date(2025, 3, 1, 10, 0, 0, 0, newYork), End: time.Date(2025, 3, 1, 12, 0, 0, 0, newYork),
+				Rule: weekly(time.Date(2025, 3, 1, 10, 0, 0, 0, newYork), "FREQ=WEEKLY;BYDAY=SA"),
+			}},
+			wantLines: []string{
+				"TZID:America/New_York",
+				"DTSTART;TZID=America/New_York:20250301T100000",
+				// Transisi ke EDT: 9 Maret 2025 02:00 waktu standar, dan kembali ke EST 2 November 02:00 waktu musim panas
+				"BEGIN:DAYLIGHT", "DTSTART:20250309T020000", "TZOFFSETFROM:-0500", "TZOFFSETTO:-0400", "TZNAME:EDT",
+				"BEGIN:STANDARD", "DTSTART:20251102T020000", "TZOFFSETFROM:-0400", "TZOFFSETTO:-0500", "TZNAME:EST",
+			},
+		},
+		{
+			name: "one vtimezone per zone",
+			events: []ICSEvent{jazz, {
+				UID: "event-4@ulyngo", Summary: "Angklung", Updated: updated,
+				Start: time.Date(2025, 2, 1, 9, 0, 0, 0, jakarta), End: time.Date(2025, 2, 1, 10, 0, 0, 0, jakarta),
+			}},
+			wantCount: map[string]int{"BEGIN:VTIMEZONE": 1, "BEGIN:VEVENT": 2, "TZID:Asia/Jakarta": 1},
+		},
+		{
+			name: "description escaped and folded",
+			events: []ICSEvent{{
+				UID: "event-5@ulyngo", Summary: "Kuliner", Updated: updated,
+				Description: "Baris satu\nBaris dua, " + strings.Repeat("é", 60),
+				Start:       time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC), End: time.Date(2025, 1, 3, 14, 0, 0, 0, time.UTC),
+			}},
+			wantLines: []string{"DESCRIPTION:Baris satu\\nBaris dua\\, " + strings.Repeat("é", 60)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteICS(&buf, "Acara Bandung, Jawa Barat", tt.events); err != nil {
+				t.Fatal(err)
+			}
+			raw := buf.String()
+			if !strings.HasPrefix(raw, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(raw, "END:VCALENDAR\r\n") {
+				t.Fatalf("calendar not wrapped in VCALENDAR with CRLF:\n%s", raw)
+			}
+			for _, line := range strings.Split(strings.TrimSuffix(raw, "\r\n"), "\r\n") {
+				if len(line) > 75 {
+					t.Errorf("line longer than 75 octets: %q", line)
+				}
+				if strings.Contains(line, "\n") {
+					t.Errorf("bare newline in line %q", line)
+				}
+			}
+
+			// Baris yang dilipat disambung kembali sebelum dibandingkan
+			lines := strings.Split(strings.ReplaceAll(raw, "\r\n ", ""), "\r\n")
+			has := func(want string) bool {
+				for _, line := range lines {
+					if line == want {
+						return true
+					}
+				}
+				return false
+			}
+			for _, want := range tt.wantLines {
+				if !has(want) {
+					t.Errorf("missing line %q in:\n%s", want, raw)
+				}
+			}
+			for _, missing := range tt.wantMissing {
+				if strings.Contains(raw, missing) {
+					t.Errorf("unexpected %q in:\n%s", missing, raw)
+				}
+			}
+			for want, count := range tt.wantCount {
+				got := 0
+				for _, line := range lines {
+					if line == want {
+						got++
+					}
+				}
+				if got != count {
+					t.Errorf("%q appears %d times, want %d", want, got, count)
+				}
+			}
+		})
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Braga"},
+		{"exactly 75", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"ascii", "DESCRIPTION:" + strings.Repeat("a", 200)},
+		{"multibyte", "DESCRIPTION:" + strings.Repeat("é☕", 50)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := foldLine(tt.line)
+			for _, part := range strings.Split(folded, "\r\n") {
+				if len(part) > 75 {
+					t.Errorf("folded line has %d octets", len(part))
+				}
+				if !utf8.ValidString(part) {
+					t.Errorf("fold split a UTF-8 character: %q", part)
+				}
+			}
+			if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded line differs:\n got %q\nwant %q", unfolded, tt.line)
+			}
+		})
+	}
+}
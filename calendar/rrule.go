@@ -0,0 +1,266 @@
+// Package calendar menyediakan aturan pengulangan jadwal (subset RRULE RFC 5545) dan penulisan
+// feed iCalendar (.ics) untuk event yang terikat ke marker.
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// Data zona waktu disertakan di binary karena image produksi (alpine) tidak memiliki tzdata
+	_ "time/tzdata"
+)
+
+// Frekuensi pengulangan yang didukung.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// maxPeriods membatasi jumlah periode (hari, minggu, bulan, atau tahun sesuai FREQ, dikali INTERVAL) yang
+// diiterasi saat ekspansi agar aturan tanpa batas tidak membuat perulangan yang terlalu panjang. Untuk
+// FREQ=DAILY batas ini kira-kira 54 tahun: kejadian aturan tanpa COUNT/UNTIL setelah itu tidak dihasilkan.
+// Aturan dengan COUNT atau UNTIL yang membutuhkan lebih banyak periode ditolak oleh ParseRule.
+const maxPeriods = 20000
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// Rule adalah aturan pengulangan hasil parse RRULE. Bagian yang didukung: FREQ, INTERVAL, COUNT,
+// UNTIL, BYDAY (hanya untuk FREQ=WEEKLY, tanpa prefiks angka), dan WKST=MO.
+type Rule struct {
+	Freq     string
+	Interval int
+	Count    int        // 0 berarti tidak dibatasi jumlah
+	Until    *time.Time // nil berarti tidak dibatasi waktu (UTC)
+	ByDay    []time.Weekday
+}
+
+// ParseRule mem-parse string RRULE, misalnya "FREQ=WEEKLY;BYDAY=FR,SA;UNTIL=20251231T235959Z", untuk
+// event yang kejadian pertamanya dimulai pada start (dalam zona waktu event). Prefiks "RRULE:" boleh disertakan.
+// UNTIL berupa tanggal saja berarti akhir hari tersebut di zona waktu start. Aturan yang tidak menghasilkan
+// kejadian (misalnya UNTIL sebelum start) atau yang COUNT/UNTIL-nya melewati maxPeriods ditolak.
+func ParseRule(value string, start time.Time) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("rule is empty")
+	}
+	rule := &Rule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, found := strings.Cut(part, "=")
+		if !found || val == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+			switch rule.Freq {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 || n > 1000 {
+				return nil, fmt.Errorf("INTERVAL must be between 1 and 1000")
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 || n > 10000 {
+				return nil, fmt.Errorf("COUNT must be between 1 and 10000")
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(val, start.Location())
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			seen := map[time.Weekday]bool{}
+			for _, code := range strings.Split(strings.ToUpper(val), ",") {
+				day, ok := weekdayCodes[code]
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY value %q", code)
+				}
+				// Hari yang diulang hanya menghasilkan satu kejadian per minggu
+				if !seen[day] {
+					seen[day] = true
+					rule.ByDay = append(rule.ByDay, day)
+				}
+			}
+		case "WKST":
+			if strings.ToUpper(val) != "MO" {
+				return nil, fmt.Errorf("only WKST=MO is supported")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be combined")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != FreqWeekly {
+		return nil, fmt.Errorf("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	// Urutkan hari mulai Senin agar ekspansi per minggu berurutan
+	sort.Slice(rule.ByDay, func(i, j int) bool { return mondayIndex(rule.ByDay[i]) < mondayIndex(rule.ByDay[j]) })
+
+	if rule.Until != nil && rule.Until.Before(start) {
+		return nil, fmt.Errorf("UNTIL must not be before the event start")
+	}
+	if rule.Count > 0 || rule.Until != nil {
+		occurrences := 0
+		truncated := each(rule, start, func(time.Time) bool {
+			occurrences++
+			return true
+		})
+		if truncated {
+			return nil, fmt.Errorf("rule spans more than %d periods, use an earlier UNTIL or a smaller COUNT", maxPeriods)
+		}
+		if occurrences == 0 {
+			return nil, fmt.Errorf("rule has no occurrences")
+		}
+	}
+	return rule, nil
+}
+
+// parseUntil menerima UNTIL berformat tanggal-waktu UTC (20251231T235959Z) atau tanggal saja (20251231).
+// Tanggal saja diartikan sebagai akhir hari tersebut di zona waktu location.
+func parseUntil(value string, location *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", value, location); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("UNTIL must be formatted as YYYYMMDD or YYYYMMDDTHHMMSSZ")
+}
+
+// String mengembalikan bentuk RRULE yang sudah dinormalisasi (tanpa prefiks "RRULE:").
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = strings.ToUpper(day.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrences mengembalikan waktu mulai setiap kejadian yang rentangnya [mulai, mulai+duration)
+// beririsan dengan [from, to), paling banyak limit kejadian. Rule nil berarti event tunggal.
+// Pengulangan dihitung dalam waktu lokal start sehingga jam mulai tetap sama di setiap kejadian.
+func Occurrences(rule *Rule, start time.Time, duration time.Duration, from, to time.Time, limit int) []time.Time {
+	result := []time.Time{}
+	each(rule, start, func(occurrence time.Time) bool {
+		if !occurrence.Before(to) || len(result) >= limit {
+			return false
+		}
+		if occurrence.Add(duration).After(from) {
+			result = append(result, occurrence)
+		}
+		return true
+	})
+	return result
+}
+
+// LastStart mengembalikan waktu mulai kejadian terakhir, atau nil jika aturan tidak berujung.
+func LastStart(rule *Rule, start time.Time) *time.Time {
+	if rule != nil && rule.Count == 0 && rule.Until == nil {
+		return nil
+	}
+	last := start
+	each(rule, start, func(occurrence time.Time) bool {
+		last = occurrence
+		return true
+	})
+	return &last
+}
+
+// each memanggil fn untuk setiap kejadian secara berurutan sampai fn mengembalikan false
+// atau aturan habis (COUNT, UNTIL, atau maxPeriods). Mengembalikan true jika iterasi berhenti
+// karena maxPeriods.
+func each(rule *Rule, start time.Time, fn func(time.Time) bool) (truncated bool) {
+	if rule == nil {
+		fn(start)
+		return false
+	}
+	emitted := 0
+	emit := func(occurrence time.Time) bool {
+		if rule.Until != nil && occurrence.After(*rule.Until) {
+			return false
+		}
+		if rule.Count > 0 && emitted >= rule.Count {
+			return false
+		}
+		emitted++
+		return fn(occurrence)
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		n := period * rule.Interval
+		switch rule.Freq {
+		case FreqDaily:
+			if !emit(start.AddDate(0, 0, n)) {
+				return false
+			}
+		case FreqWeekly:
+			if len(rule.ByDay) == 0 {
+				if !emit(start.AddDate(0, 0, 7*n)) {
+					return false
+				}
+				continue
+			}
+			// Minggu dimulai hari Senin (WKST=MO); kejadian sebelum start dilewati
+			weekStart := start.AddDate(0, 0, -mondayIndex(start.Weekday())+7*n)
+			for _, day := range rule.ByDay {
+				occurrence := weekStart.AddDate(0, 0, mondayIndex(day))
+				if occurrence.Before(start) {
+					continue
+				}
+				if !emit(occurrence) {
+					return false
+				}
+			}
+		case FreqMonthly:
+			// Tanggal yang tidak ada di bulan tersebut (misalnya 31) dilewati, sesuai RFC 5545
+			occurrence := start.AddDate(0, n, 0)
+			if occurrence.Day() == start.Day() && !emit(occurrence) {
+				return false
+			}
+		case FreqYearly:
+			occurrence := start.AddDate(n, 0, 0)
+			if occurrence.Day() == start.Day() && !emit(occurrence) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// mondayIndex mengubah hari menjadi indeks dengan Senin = 0.
+func mondayIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
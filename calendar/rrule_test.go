@@ -0,0 +1,218 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+func TestParseRule(t *testing.T) {
+	jakarta := mustLocation(t, "Asia/Jakarta")
+	// Rabu, 1 Januari 2025 19:00 WIB
+	start := time.Date(2025, 1, 1, 19, 0, 0, 0, jakarta)
+	tests := []struct {
+		name    string
+		value   string
+		want    string // Bentuk String() yang dinormalisasi
+		wantErr string
+	}{
+		{name: "daily", value: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{name: "prefix and lower case", value: " RRULE:freq=weekly;byday=sa,fr ", want: "FREQ=WEEKLY;BYDAY=FR,SA"},
+		{name: "duplicate byday", value: "FREQ=WEEKLY;BYDAY=SU,MO,SU", want: "FREQ=WEEKLY;BYDAY=MO,SU"},
+		{name: "interval and count", value: "FREQ=WEEKLY;INTERVAL=2;COUNT=5;WKST=MO", want: "FREQ=WEEKLY;INTERVAL=2;COUNT=5"},
+		{name: "interval one omitted", value: "FREQ=MONTHLY;INTERVAL=1", want: "FREQ=MONTHLY"},
+		{name: "utc until", value: "FREQ=DAILY;UNTIL=20250110T120000Z", want: "FREQ=DAILY;UNTIL=20250110T120000Z"},
+		{name: "date until is local end of day", value: "FREQ=DAILY;UNTIL=20250110", want: "FREQ=DAILY;UNTIL=20250110T165959Z"},
+		{name: "until on start day", value: "FREQ=DAILY;UNTIL=20250101", want: "FREQ=DAILY;UNTIL=20250101T165959Z"},
+
+		{name: "empty", value: "RRULE:", wantErr: "empty"},
+		{name: "missing freq", value: "COUNT=5", wantErr: "FREQ is required"},
+		{name: "unsupported freq", value: "FREQ=HOURLY", wantErr: "unsupported FREQ"},
+		{name: "part without value", value: "FREQ=DAILY;COUNT=", wantErr: "invalid rule part"},
+		{name: "unknown part", value: "FREQ=DAILY;BYMONTH=1", wantErr: "unsupported rule part"},
+		{name: "interval zero", value: "FREQ=DAILY;INTERVAL=0", wantErr: "INTERVAL"},
+		{name: "count too large", value: "FREQ=DAILY;COUNT=10001", wantErr: "COUNT"},
+		{name: "count and until", value: "FREQ=DAILY;COUNT=2;UNTIL=20250110", wantErr: "cannot be combined"},
+		{name: "byday with daily", value: "FREQ=DAILY;BYDAY=MO", wantErr: "only supported with FREQ=WEEKLY"},
+		{name: "byday ordinal", value: "FREQ=WEEKLY;BYDAY=1MO", wantErr: "unsupported BYDAY"},
+		{name: "week start sunday", value: "FREQ=WEEKLY;WKST=SU", wantErr: "WKST"},
+		{name: "until format", value: "FREQ=DAILY;UNTIL=2025-01-10", wantErr: "UNTIL must be formatted"},
+		{name: "until before start", value: "FREQ=DAILY;UNTIL=20241231", wantErr: "before the event start"},
+		{name: "until before start time", value: "FREQ=DAILY;UNTIL=20250101T115959Z", wantErr: "before the event start"},
+		{name: "no occurrences", value: "FREQ=WEEKLY;BYDAY=MO;UNTIL=20250105", wantErr: "no occurrences"},
+		{name: "until beyond max periods", value: "FREQ=DAILY;UNTIL=21000101", wantErr: "more than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRule(tt.value, start)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			// Bentuk normalisasi harus bisa di-parse ulang menjadi aturan yang sama
+			again, err := ParseRule(rule.String(), start)
+			if err != nil || again.String() != tt.want {
+				t.Errorf("round trip = %v, %v", again, err)
+			}
+		})
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	jakarta := mustLocation(t, "Asia/Jakarta")
+	newYork := mustLocation(t, "America/New_York")
+	day := 24 * time.Hour
+	tests := []struct {
+		name     string
+		rule     string // Kosong berarti event tunggal
+		start    time.Time
+		duration time.Duration
+		from, to time.Time
+		limit    int
+		want     []string // Waktu lokal start, format 2006-01-02 15:04 MST
+	}{
+		{
+			name:  "single event in window",
+			start: time.Date(2025, 1, 3, 19, 0, 0, 0, jakarta), duration: 2 * time.Hour,
+			from: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), to: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), limit: 10,
+			want: []string{"2025-01-03 19:00 WIB"},
+		},
+		{
+			name:  "single event outside window",
+			start: time.Date(2025, 3, 3, 19, 0, 0, 0, jakarta), duration: 2 * time.Hour,
+			from: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), to: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), limit: 10,
+			want: []string{},
+		},
+		{
+			name: "daily count", rule: "FREQ=DAILY;COUNT=3",
+			start: time.Date(2025, 1, 1, 19, 0, 0, 0, jakarta), duration: time.Hour,
+			from: time.Date(2024, 12, 1, 0, 0, 0, 0, jakarta), to: time.Date(2025, 2, 1, 0, 0, 0, 0, jakarta), limit: 10,
+			want: []string{"2025-01-01 19:00 WIB", "2025-01-02 19:00 WIB", "2025-01-03 19:00 WIB"},
+		},
+		{
+			name: "daily interval and limit", rule: "FREQ=DAILY;INTERVAL=2",
+			start: time.Date(2025, 1, 1, 19, 0, 0, 0, jakarta), duration: time.Hour,
+			from: time.Date(2025, 1, 1, 0, 0, 0, 0, jakarta), to: time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta), limit: 3,
+			want: []string{"2025-01-01 19:00 WIB", "2025-01-03 19:00 WIB", "2025-01-05 19:00 WIB"},
+		},
+		{
+			name: "date until includes last local day", rule: "FREQ=DAILY;UNTIL=20250103",
+			start: time.Date(2025, 1, 1, 19, 0, 0, 0, jakarta), duration: time.Hour,
+			from: time.Date(2025, 1, 1, 0, 0, 0, 0, jakarta), to: time.Date(2025, 2, 1, 0, 0, 0, 0, jakarta), limit: 10,
+			want: []string{"2025-01-01 19:00 WIB", "2025-01-02 19:00 WIB", "2025-01-03 19:00 WIB"},
+		},
+		{
+			name: "weekly byday skips days before start", rule: "FREQ=WEEKLY;BYDAY=MO,FR,SA",
+			start: time.Date(2025, 1, 1, 19, 0, 0, 0, jakarta), duration: 3 * time.Hour,
+			from: time.Date(2025, 1, 1, 0, 0, 0, 0, jakarta), to: time.Date(2025, 2, 1, 0, 0, 0, 0, jakarta), limit: 4,
+			want: []string{"2025-01-03 19:00 WIB", "2025-01-04 19:00 WIB", "2025-01-06 19:00 WIB", "2025-01-10 19:00 WIB"},
+		},
+		{
+			name: "biweekly byday", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR",
+			start: time.Date(2025, 1, 3, 19, 0, 0, 0, jakarta), duration: time.Hour,
+			from: time.Date(2025, 1, 1, 0, 0, 0, 0, jakarta), to: time.Date(2025, 2, 1, 0, 0, 0, 0, jakarta), limit: 10,
+			want: []string{"2025-01-03 19:00 WIB", "2025-01-17 19:00 WIB", "2025-01-31 19:00 WIB"},
+		},
+		{
+			name: "monthly skips short months", rule: "FREQ=MONTHLY;COUNT=3",
+			start: time.Date(2025, 1, 31, 10, 0, 0, 0, jakarta), duration: time.Hour,
+			from: time.Date(2025, 1, 1, 0, 0, 0, 0, jakarta), to: time.Date(2026, 1, 1, 0, 0, 0, 0, jakarta), limit: 10,
+			want: []string{"2025-01-31 10:00 WIB", "2025-03-31 10:00 WIB", "2025-05-31 10:00 WIB"},
+		},
+		{
+			name: "yearly leap day", rule: "FREQ=YEARLY",
+			start: time.Date(2024, 2, 29, 10, 0, 0, 0, jakarta), duration: time.Hour,
+			from: time.Date(2024, 1, 1, 0, 0, 0, 0, jakarta), to: time.Date(2033, 1, 1, 0, 0, 0, 0, jakarta), limit: 10,
+			want: []string{"2024-02-29 10:00 WIB", "2028-02-29 10:00 WIB", "2032-02-29 10:00 WIB"},
+		},
+		{
+			name: "local time kept across daylight saving", rule: "FREQ=WEEKLY;COUNT=3",
+			start: time.Date(2025, 3, 1, 10, 0, 0, 0, newYork), duration: time.Hour,
+			from: time.Date(2025, 3, 1, 0, 0, 0, 0, newYork), to: time.Date(2025, 4, 1, 0, 0, 0, 0, newYork), limit: 10,
+			want: []string{"2025-03-01 10:00 EST", "2025-03-08 10:00 EST", "2025-03-15 10:00 EDT"},
+		},
+		{
+			name: "running occurrence overlaps window start", rule: "FREQ=DAILY",
+			start: time.Date(2025, 1, 1, 23, 0, 0, 0, jakarta), duration: 2 * time.Hour,
+			from: time.Date(2025, 1, 3, 0, 30, 0, 0, jakarta), to: time.Date(2025, 1, 3, 0, 30, 0, 0, jakarta).Add(day), limit: 10,
+			want: []string{"2025-01-02 23:00 WIB", "2025-01-03 23:00 WIB"},
+		},
+		{
+			name: "occurrence ending at window start excluded", rule: "FREQ=DAILY",
+			start: time.Date(2025, 1, 1, 22, 0, 0, 0, jakarta), duration: 2 * time.Hour,
+			from: time.Date(2025, 1, 3, 0, 0, 0, 0, jakarta), to: time.Date(2025, 1, 3, 22, 0, 0, 0, jakarta), limit: 10,
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rule *Rule
+			if tt.rule != "" {
+				var err error
+				if rule, err = ParseRule(tt.rule, tt.start); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := []string{}
+			for _, occurrence := range Occurrences(rule, tt.start, tt.duration, tt.from, tt.to, tt.limit) {
+				got = append(got, occurrence.Format("2006-01-02 15:04 MST"))
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("Occurrences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastStart(t *testing.T) {
+	jakarta := mustLocation(t, "Asia/Jakarta")
+	start := time.Date(2025, 1, 1, 19, 0, 0, 0, jakarta)
+	tests := []struct {
+		name string
+		rule string
+		want string // Kosong berarti tidak berujung
+	}{
+		{name: "single event", want: "2025-01-01 19:00"},
+		{name: "unbounded", rule: "FREQ=WEEKLY"},
+		{name: "count", rule: "FREQ=DAILY;COUNT=3", want: "2025-01-03 19:00"},
+		{name: "until", rule: "FREQ=WEEKLY;BYDAY=FR;UNTIL=20250124", want: "2025-01-24 19:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rule *Rule
+			if tt.rule != "" {
+				var err error
+				if rule, err = ParseRule(tt.rule, start); err != nil {
+					t.Fatal(err)
+				}
+			}
+			last := LastStart(rule, start)
+			if tt.want == "" {
+				if last != nil {
+					t.Errorf("LastStart = %v, want nil", last)
+				}
+				return
+			}
+			if last == nil || last.Format("2006-01-02 15:04") != tt.want {
+				t.Errorf("LastStart = %v, want %s", last, tt.want)
+			}
+		})
+	}
+}
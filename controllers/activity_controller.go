@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"ulyngo/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ActivityController menampilkan log aktivitas milik pengguna, termasuk notifikasi
+// hasil moderasi marker yang mereka kirim.
+type ActivityController struct {
+	DB *gorm.DB
+}
+
+// NewActivityController adalah konstruktor untuk ActivityController.
+func NewActivityController(db *gorm.DB) *ActivityController {
+	return &ActivityController{DB: db}
+}
+
+// GetMyActivity mengambil log aktivitas pengguna saat ini, terbaru lebih dulu.
+// Mendukung filter ?type= (misal: marker_approved) dan ?limit= (default 50, maksimum 200).
+func (ac *ActivityController) GetMyActivity(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+		return
+	}
+
+	query := ac.DB.Where("user_id = ?", userID)
+	if activityType := c.Query("type"); activityType != "" {
+		query = query.Where("activity_type = ?", activityType)
+	}
+
+	var logs []models.UserActivityLog
+	if err := query.Order("timestamp DESC").Limit(limit).Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity logs: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, logs)
+}
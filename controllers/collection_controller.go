@@ -0,0 +1,615 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ulyngo/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxCollectionItems adalah jumlah maksimum marker dalam satu koleksi.
+const maxCollectionItems = 500
+
+// CollectionController menangani koleksi marker yang dikurasi pengguna.
+type CollectionController struct {
+	DB *gorm.DB
+}
+
+// NewCollectionController adalah konstruktor untuk CollectionController.
+func NewCollectionController(db *gorm.DB) *CollectionController {
+	return &CollectionController{DB: db}
+}
+
+// CreateCollectionInput adalah struktur input untuk membuat koleksi.
+type CreateCollectionInput struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description *string `json:"description"`
+	Visibility  string  `json:"visibility" binding:"omitempty,oneof=private unlisted public"` // Default private
+}
+
+// UpdateCollectionInput adalah struktur input untuk memperbarui koleksi. Field yang tidak dikirim tidak diubah.
+type UpdateCollectionInput struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description"`
+	Visibility  *string `json:"visibility" binding:"omitempty,oneof=private unlisted public"`
+}
+
+// AddCollectionItemInput adalah struktur input untuk menambahkan marker ke koleksi.
+type AddCollectionItemInput struct {
+	MarkerID uuid.UUID `json:"marker_id" binding:"required"`
+	Note     *string   `json:"note"`
+}
+
+// UpdateCollectionItemInput adalah struktur input untuk mengubah catatan marker di koleksi.
+type UpdateCollectionItemInput struct {
+	Note *string `json:"note"`
+}
+
+// ReorderCollectionItemsInput berisi seluruh ID marker koleksi dalam urutan baru.
+type ReorderCollectionItemsInput struct {
+	MarkerIDs []uuid.UUID `json:"marker_ids" binding:"required"`
+}
+
+// CopyCollectionInput adalah struktur input opsional untuk menyalin koleksi.
+type CopyCollectionInput struct {
+	Name string `json:"name" binding:"omitempty,max=100"` // Default nama koleksi asal
+}
+
+// collectionQuery menyiapkan query koleksi beserta jumlah marker yang masih tampil dan username pemiliknya.
+func collectionQuery(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Collection{}).
+		Select(`collections.*, users.username AS owner_username,
+			(SELECT COUNT(*) FROM collection_items ci
+				JOIN markers m ON m.id = ci.marker_id AND m.deleted_at IS NULL AND m.status = ?
+				WHERE ci.collection_id = collections.id) AS item_count`, models.MarkerStatusApproved).
+		Joins("LEFT JOIN users ON users.id = collections.user_id")
+}
+
+// preloadCollectionItems memuat marker di dalam koleksi sesuai urutannya.
+// Marker yang sudah dihapus atau tidak lagi disetujui tidak ditampilkan.
+func preloadCollectionItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", func(tx *gorm.DB) *gorm.DB {
+		return tx.Joins("JOIN markers ON markers.id = collection_items.marker_id AND markers.deleted_at IS NULL AND markers.status = ?", models.MarkerStatusApproved).
+			Order("collection_items.sort_order, collection_items.added_at")
+	}).Preload("Items.Marker")
+}
+
+// visibleCollectionItems menyiapkan query item koleksi yang markernya masih tampil, yaitu item yang sama
+// dengan yang dimuat preloadCollectionItems dan dihitung item_count.
+func visibleCollectionItems(db *gorm.DB, collectionID uuid.UUID) *gorm.DB {
+	return db.Model(&models.CollectionItem{}).
+		Joins("JOIN markers ON markers.id = collection_items.marker_id AND markers.deleted_at IS NULL AND markers.status = ?", models.MarkerStatusApproved).
+		Where("collection_items.collection_id = ?", collectionID)
+}
+
+// newShareToken menghasilkan token acak untuk tautan berbagi koleksi unlisted.
+func newShareToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// loadOwnCollection memuat koleksi :id milik pengguna saat ini. Menulis respons error sendiri jika gagal.
+func (cc *CollectionController) loadOwnCollection(c *gin.Context) (*models.Collection, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+	collectionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID format"})
+		return nil, false
+	}
+	var collection models.Collection
+	if err := cc.DB.First(&collection, "id = ? AND user_id = ?", collectionID, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find collection: " + err.Error()})
+		}
+		return nil, false
+	}
+	return &collection, true
+}
+
+// findCollectionDetail memuat koleksi lengkap dengan marker-markernya.
+func (cc *CollectionController) findCollectionDetail(collectionID uuid.UUID) (*models.Collection, error) {
+	var collection models.Collection
+	if err := preloadCollectionItems(collectionQuery(cc.DB)).First(&collection, "collections.id = ?", collectionID).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// respondCollectionDetail mengirim koleksi lengkap sebagai respons dengan status yang diberikan.
+func (cc *CollectionController) respondCollectionDetail(c *gin.Context, status int, collectionID uuid.UUID) {
+	collection, err := cc.findCollectionDetail(collectionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collection: " + err.Error()})
+		return
+	}
+	c.JSON(status, collection)
+}
+
+// GetMyCollections mengambil semua koleksi milik pengguna saat ini. (Protected)
+func (cc *CollectionController) GetMyCollections(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var collections []models.Collection
+	if err := collectionQuery(cc.DB).Where("collections.user_id = ?", userID).
+		Order("collections.updated_at DESC").Find(&collections).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collections: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, collections)
+}
+
+// GetMyCollection mengambil satu koleksi milik pengguna saat ini beserta marker-markernya. (Protected)
+func (cc *CollectionController) GetMyCollection(c *gin.Context) {
+	collection, ok := cc.loadOwnCollection(c)
+	if !ok {
+		return
+	}
+	cc.respondCollectionDetail(c, http.StatusOK, collection.ID)
+}
+
+// CreateCollection membuat koleksi baru. Koleksi unlisted langsung mendapat share token. (Protected)
+func (cc *CollectionController) CreateCollection(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var input CreateCollectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Collection name cannot be empty"})
+		return
+	}
+	if input.Visibility == "" {
+		input.Visibility = models.CollectionVisibilityPrivate
+	}
+
+	collection := models.Collection{
+		UserID:      userID,
+		Name:        input.Name,
+		Description: input.Description,
+		Visibility:  input.Visibility,
+	}
+	if collection.Visibility == models.CollectionVisibilityUnlisted {
+		token, err := newShareToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate share token: " + err.Error()})
+			return
+		}
+		collection.ShareToken = &token
+	}
+	if err := cc.DB.Create(&collection).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create collection: " + err.Error()})
+		return
+	}
+	cc.respondCollectionDetail(c, http.StatusCreated, collection.ID)
+}
+
+// UpdateCollection memperbarui nama, deskripsi, atau visibilitas koleksi. (Protected)
+// Mengubah visibilitas menjadi unlisted membuat share token baru; mengubahnya ke private atau public
+// mencabut share token lama sehingga tautan yang sudah dibagikan tidak berlaku lagi.
+func (cc *CollectionController) UpdateCollection(c *gin.Context) {
+	collection, ok := cc.loadOwnCollection(c)
+	if !ok {
+		return
+	}
+	var input UpdateCollectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Collection name cannot be empty"})
+			return
+		}
+		updates["name"] = name
+	}
+	if input.Description != nil {
+		updates["description"] = input.Description
+	}
+	if input.Visibility != nil && *input.Visibility != collection.Visibility {
+		updates["visibility"] = *input.Visibility
+		updates["share_token"] = nil
+		if *input.Visibility == models.CollectionVisibilityUnlisted {
+			token, err := newShareToken()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate share token: " + err.Error()})
+				return
+			}
+			updates["share_token"] = token
+		}
+	}
+	if len(updates) > 0 {
+		if err := cc.DB.Model(collection).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update collection: " + err.Error()})
+			return
+		}
+	}
+	cc.respondCollectionDetail(c, http.StatusOK, collection.ID)
+}
+
+// RegenerateShareToken membuat share token baru untuk koleksi unlisted, mencabut tautan lama. (Protected)
+func (cc *CollectionController) RegenerateShareToken(c *gin.Context) {
+	collection, ok := cc.loadOwnCollection(c)
+	if !ok {
+		return
+	}
+	if collection.Visibility != models.CollectionVisibilityUnlisted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only unlisted collections have a share token", "visibility": collection.Visibility})
+		return
+	}
+	token, err := newShareToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate share token: " + err.Error()})
+		return
+	}
+	if err := cc.DB.Model(collection).Update("share_token", token).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update share token: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Share token regenerated", "share_token": token})
+}
+
+// DeleteCollection menghapus (soft delete) koleksi milik pengguna saat ini. (Protected)
+func (cc *CollectionController) DeleteCollection(c *gin.Context) {
+	collection, ok := cc.loadOwnCollection(c)
+	if !ok {
+		return
+	}
+	if err := cc.DB.Delete(collection).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete collection: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Collection deleted successfully"})
+}
+
+// AddCollectionItem menambahkan marker ke akhir koleksi. (Protected)
+func (cc *CollectionController) AddCollectionItem(c *gin.Context) {
+	collection, ok := cc.loadOwnCollection(c)
+	if !ok {
+		return
+	}
+	var input AddCollectionItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	markerID, ok := findApprovedMarkerID(c, cc.DB, input.MarkerID.String())
+	if !ok {
+		return
+	}
+
+	errAlreadyInCollection := errors.New("already in collection")
+	errCollectionFull := errors.New("collection full")
+	err := cc.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci koleksi membuat pengecekan duplikat dan batas jumlah item aman dari request bersamaan
+		var locked models.Collection
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, "id = ?", collection.ID).Error; err != nil {
+			return err
+		}
+		var existing, count int64
+		if err := tx.Model(&models.CollectionItem{}).Where("collection_id = ? AND marker_id = ?", collection.ID, markerID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errAlreadyInCollection
+		}
+		// Item dengan marker yang sudah dihapus atau tidak lagi disetujui tidak terlihat, sehingga tidak dihitung
+		if err := visibleCollectionItems(tx, collection.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= maxCollectionItems {
+			return errCollectionFull
+		}
+
+		var nextOrder int
+		if err := tx.Model(&models.CollectionItem{}).Where("collection_id = ?", collection.ID).
+			Select("COALESCE(MAX(sort_order), -1) + 1").Scan(&nextOrder).Error; err != nil {
+			return err
+		}
+		item := models.CollectionItem{CollectionID: collection.ID, MarkerID: markerID, SortOrder: nextOrder, Note: input.Note}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		// updated_at koleksi ikut diperbarui agar daftar koleksi terurut berdasarkan aktivitas terakhir
+		return tx.Model(collection).Update("updated_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errAlreadyInCollection):
+			c.JSON(http.StatusConflict, gin.H{"error": "Marker is already in this collection"})
+		case errors.Is(err, errCollectionFull):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A collection can contain at most " + strconv.Itoa(maxCollectionItems) + " markers"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add marker to collection: " + err.Error()})
+		}
+		return
+	}
+	cc.respondCollectionDetail(c, http.StatusCreated, collection.ID)
+}
+
+// UpdateCollectionItem mengubah catatan marker di dalam koleksi. (Protected)
+func (cc *CollectionController) UpdateCollectionItem(c *gin.Context) {
+	collection, ok := cc.loadOwnCollection(c)
+	if !ok {
+		return
+	}
+	markerID, err := uuid.Parse(c.Param("markerID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return
+	}
+	var input UpdateCollectionItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := cc.DB.Model(&models.CollectionItem{}).
+		Where("collection_id = ? AND marker_id = ?", collection.ID, markerID).
+		Update("note", input.Note)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update collection item: " + result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Marker is not in this collection"})
+		return
+	}
+	cc.respondCollectionDetail(c, http.StatusOK, collection.ID)
+}
+
+// RemoveCollectionItem mengeluarkan marker dari koleksi. (Protected)
+func (cc *CollectionController) RemoveCollectionItem(c *gin.Context) {
+	collection, ok := cc.loadOwnCollection(c)
+	if !ok {
+		return
+	}
+	markerID, err := uuid.Parse(c.Param("markerID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return
+	}
+
+	result := cc.DB.Where("collection_id = ? AND marker_id = ?", collection.ID, markerID).Delete(&models.CollectionItem{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove collection item: " + result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Marker is not in this collection"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Marker removed from collection"})
+}
+
+// ReorderCollectionItems mengatur ulang urutan marker di koleksi. marker_ids harus memuat
+// setiap marker koleksi yang tampil tepat satu kali; item dengan marker yang sudah dihapus atau tidak lagi
+// disetujui dipindahkan ke belakang dengan urutan relatif yang sama. (Protected)
+func (cc *CollectionController) ReorderCollectionItems(c *gin.Context) {
+	collection, ok := cc.loadOwnCollection(c)
+	if !ok {
+		return
+	}
+	var input ReorderCollectionItemsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existingIDs []uuid.UUID
+	if err := visibleCollectionItems(cc.DB, collection.ID).Pluck("collection_items.marker_id", &existingIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collection items: " + err.Error()})
+		return
+	}
+	existing := make(map[uuid.UUID]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		existing[id] = true
+	}
+	seen := make(map[uuid.UUID]bool, len(input.MarkerIDs))
+	for _, id := range input.MarkerIDs {
+		if !existing[id] || seen[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "marker_ids must list each marker of the collection exactly once"})
+			return
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(existing) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "marker_ids must list each marker of the collection exactly once"})
+		return
+	}
+
+	err := cc.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range input.MarkerIDs {
+			if err := tx.Model(&models.CollectionItem{}).Where("collection_id = ? AND marker_id = ?", collection.ID, id).
+				Update("sort_order", i).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec(`UPDATE collection_items SET sort_order = ? + hidden.position
+			FROM (SELECT ci.marker_id, ROW_NUMBER() OVER (ORDER BY ci.sort_order, ci.added_at) - 1 AS position
+				FROM collection_items ci
+				WHERE ci.collection_id = ? AND NOT EXISTS (
+					SELECT 1 FROM markers m WHERE m.id = ci.marker_id AND m.deleted_at IS NULL AND m.status = ?)) hidden
+			WHERE collection_items.collection_id = ? AND collection_items.marker_id = hidden.marker_id`,
+			len(input.MarkerIDs), collection.ID, models.MarkerStatusApproved, collection.ID).Error; err != nil {
+			return err
+		}
+		return tx.Model(collection).Update("updated_at", gorm.Expr("CURRENT_TIMESTAMP")).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder collection: " + err.Error()})
+		return
+	}
+	cc.respondCollectionDetail(c, http.StatusOK, collection.ID)
+}
+
+// findVisibleCollection memuat koleksi :id yang boleh dilihat publik: koleksi public, atau koleksi
+// unlisted dengan ?token= yang cocok. Koleksi lain dilaporkan sebagai tidak ditemukan agar keberadaannya
+// tidak bocor. Menulis respons error sendiri jika gagal.
+func (cc *CollectionController) findVisibleCollection(c *gin.Context) (*models.Collection, bool) {
+	collectionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID format"})
+		return nil, false
+	}
+	collection, err := cc.findCollectionDetail(collectionID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find collection: " + err.Error()})
+		}
+		return nil, false
+	}
+
+	visible := collection.Visibility == models.CollectionVisibilityPublic
+	if collection.Visibility == models.CollectionVisibilityUnlisted && collection.ShareToken != nil {
+		token := c.Query("token")
+		visible = subtle.ConstantTimeCompare([]byte(token), []byte(*collection.ShareToken)) == 1
+	}
+	if !visible {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return nil, false
+	}
+	// Share token hanya untuk pemilik; pengunjung sudah memegang token dari tautannya
+	collection.ShareToken = nil
+	return collection, true
+}
+
+// GetPublicCollections mengambil daftar koleksi public, terbaru diperbarui lebih dulu. (Public)
+// Query opsional: ?user_id= untuk koleksi milik pengguna tertentu, ?limit= (1-100, default 20), ?offset=.
+func (cc *CollectionController) GetPublicCollections(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+		return
+	}
+
+	query := collectionQuery(cc.DB).Where("collections.visibility = ?", models.CollectionVisibilityPublic)
+	if ownerID := c.Query("user_id"); ownerID != "" {
+		ownerUUID, err := uuid.Parse(ownerID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+			return
+		}
+		query = query.Where("collections.user_id = ?", ownerUUID)
+	}
+
+	var collections []models.Collection
+	if err := query.Order("collections.updated_at DESC").Limit(limit).Offset(offset).Find(&collections).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collections: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, collections)
+}
+
+// GetPublicCollection mengambil koleksi public, atau koleksi unlisted dengan ?token= yang cocok. (Public)
+func (cc *CollectionController) GetPublicCollection(c *gin.Context) {
+	collection, ok := cc.findVisibleCollection(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, collection)
+}
+
+// CopyCollection menyalin koleksi yang bisa dilihat pengguna (public, unlisted dengan ?token=,
+// atau milik sendiri) menjadi koleksi private baru milik pengguna saat ini, termasuk urutan dan catatannya. (Protected)
+func (cc *CollectionController) CopyCollection(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var input CopyCollectionInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var source *models.Collection
+	if own, err := cc.ownCollectionDetail(c, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find collection: " + err.Error()})
+		return
+	} else if own != nil {
+		source = own
+	} else if source, ok = cc.findVisibleCollection(c); !ok {
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = source.Name
+	}
+	copied := models.Collection{
+		UserID:       userID,
+		Name:         name,
+		Description:  source.Description,
+		Visibility:   models.CollectionVisibilityPrivate,
+		CopiedFromID: &source.ID,
+	}
+	err := cc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&copied).Error; err != nil {
+			return err
+		}
+		if len(source.Items) == 0 {
+			return nil
+		}
+		items := make([]models.CollectionItem, len(source.Items))
+		for i, item := range source.Items {
+			items[i] = models.CollectionItem{CollectionID: copied.ID, MarkerID: item.MarkerID, SortOrder: i, Note: item.Note}
+		}
+		return tx.Omit("Marker").Create(&items).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to copy collection: " + err.Error()})
+		return
+	}
+	cc.respondCollectionDetail(c, http.StatusCreated, copied.ID)
+}
+
+// ownCollectionDetail memuat koleksi :id jika milik userID, atau nil jika bukan miliknya atau tidak ada.
+func (cc *CollectionController) ownCollectionDetail(c *gin.Context, userID uuid.UUID) (*models.Collection, error) {
+	collectionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return nil, nil // Format ID divalidasi ulang oleh findVisibleCollection
+	}
+	collection, err := cc.findCollectionDetail(collectionID)
+	if err == gorm.ErrRecordNotFound || (err == nil && collection.UserID != userID) {
+		return nil, nil
+	}
+	return collection, err
+}
@@ -0,0 +1,576 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ulyngo/calendar"
+	"ulyngo/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultEventRangeDays = 30
+	maxEventRangeDays     = 366
+	maxEventDuration      = 31 * 24 * time.Hour // Durasi maksimum satu kejadian
+	icsHistoryDays        = 90                  // Event yang sudah selesai lebih lama dari ini tidak dimasukkan ke feed .ics
+)
+
+// EventController menangani event berjangka waktu yang terikat ke marker.
+type EventController struct {
+	DB *gorm.DB
+}
+
+// NewEventController adalah konstruktor untuk EventController.
+func NewEventController(db *gorm.DB) *EventController {
+	return &EventController{DB: db}
+}
+
+// EventOccurrence adalah satu kejadian event (event berulang menghasilkan banyak kejadian).
+type EventOccurrence struct {
+	EventID  uuid.UUID     `json:"event_id"`
+	StartsAt time.Time     `json:"starts_at"`
+	EndsAt   time.Time     `json:"ends_at"`
+	Event    *models.Event `json:"event"`
+}
+
+// EventInput adalah struktur untuk data yang diterima saat membuat event.
+type EventInput struct {
+	Title       string    `json:"title" binding:"required"`
+	Description *string   `json:"description"`
+	StartsAt    time.Time `json:"starts_at" binding:"required"` // RFC 3339, kejadian pertama
+	EndsAt      time.Time `json:"ends_at" binding:"required"`
+	Timezone    string    `json:"timezone"` // Zona waktu IANA, default Asia/Jakarta
+	RRule       *string   `json:"rrule"`    // Misal "FREQ=WEEKLY;BYDAY=FR,SA;UNTIL=20251231"
+	Price       *float64  `json:"price"`
+	Currency    string    `json:"currency"`
+	URL         *string   `json:"url"`
+}
+
+// UpdateEventInput adalah struktur untuk data yang diterima saat memperbarui event.
+// Field yang tidak dikirim tidak diubah; rrule atau url berisi string kosong menghapus nilainya.
+type UpdateEventInput struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	Timezone    *string    `json:"timezone"`
+	RRule       *string    `json:"rrule"`
+	Price       *float64   `json:"price"`
+	Currency    *string    `json:"currency"`
+	URL         *string    `json:"url"`
+}
+
+// GetEvents mengambil kejadian event dalam rentang waktu. (Public)
+// Query:
+//   - ?from= dan ?to= (RFC 3339; default sekarang sampai 30 hari ke depan, maksimal 366 hari)
+//   - ?bbox=min_lng,min_lat,max_lng,max_lat, ?city=, ?category_id=, ?marker_id=
+//   - ?limit= (1-1000, default 200)
+func (ec *EventController) GetEvents(c *gin.Context) {
+	from, to, ok := parseEventRange(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "200"))
+	if err != nil || limit < 1 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+
+	query := activeEventsQuery(ec.DB, from, to)
+	if markerID := c.Query("marker_id"); markerID != "" {
+		markerUUID, err := uuid.Parse(markerID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+			return
+		}
+		query = query.Where("events.marker_id = ?", markerUUID)
+	}
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		categoryUUID, err := uuid.Parse(categoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+			return
+		}
+		query = query.Where("markers.category_id IN (?)", categorySubtree(ec.DB, categoryUUID))
+	}
+	if city := strings.TrimSpace(c.Query("city")); city != "" {
+		query = query.Where("LOWER(markers.city) = LOWER(?)", city)
+	}
+	if bboxParam := c.Query("bbox"); bboxParam != "" {
+		bounds, err := parseBBox(bboxParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bbox: " + err.Error()})
+			return
+		}
+		query = query.Where("markers.latitude BETWEEN ? AND ? AND markers.longitude BETWEEN ? AND ?",
+			bounds.MinLat, bounds.MaxLat, bounds.MinLng, bounds.MaxLng)
+	}
+
+	var events []models.Event
+	if err := query.Preload("Marker").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events: " + err.Error()})
+		return
+	}
+	if err := ec.localizeEventMarkers(c, events); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+
+	occurrences := []EventOccurrence{}
+	for i := range events {
+		occurrences = append(occurrences, expandEvent(&events[i], from, to, limit)...)
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartsAt.Before(occurrences[j].StartsAt)
+	})
+	if len(occurrences) > limit {
+		occurrences = occurrences[:limit]
+	}
+	c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "occurrences": occurrences})
+}
+
+// GetEventByID mengambil detail event beserta hingga 10 kejadian berikutnya. (Public)
+func (ec *EventController) GetEventByID(c *gin.Context) {
+	event, ok := ec.findEvent(c)
+	if !ok {
+		return
+	}
+	if err := ec.localizeEventMarkers(c, []models.Event{*event}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+	now := time.Now()
+	upcoming := expandEvent(event, now, now.AddDate(0, 0, maxEventRangeDays), 10)
+	for i := range upcoming {
+		upcoming[i].Event = nil
+	}
+	c.JSON(http.StatusOK, gin.H{"event": event, "next_occurrences": upcoming})
+}
+
+// GetMarkerEvents mengambil kejadian event mendatang di satu marker. (Public)
+// Query: ?from= dan ?to= seperti pada GetEvents.
+func (ec *EventController) GetMarkerEvents(c *gin.Context) {
+	markerID, ok := findApprovedMarkerID(c, ec.DB, c.Param("id"))
+	if !ok {
+		return
+	}
+	from, to, ok := parseEventRange(c)
+	if !ok {
+		return
+	}
+	var events []models.Event
+	if err := activeEventsQuery(ec.DB, from, to).Where("events.marker_id = ?", markerID).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events: " + err.Error()})
+		return
+	}
+	occurrences := []EventOccurrence{}
+	for i := range events {
+		occurrences = append(occurrences, expandEvent(&events[i], from, to, 1000)...)
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartsAt.Before(occurrences[j].StartsAt)
+	})
+	c.JSON(http.StatusOK, gin.H{"from": from, "to": to, "occurrences": occurrences})
+}
+
+// GetMarkerEventsICS menyajikan feed iCalendar berisi event di satu marker. (Public)
+func (ec *EventController) GetMarkerEventsICS(c *gin.Context) {
+	markerID, ok := findApprovedMarkerID(c, ec.DB, c.Param("id"))
+	if !ok {
+		return
+	}
+	var marker models.Marker
+	if err := ec.DB.Select("id", "name").First(&marker, "id = ?", markerID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch marker: " + err.Error()})
+		return
+	}
+	ec.writeEventFeed(c, marker.Name, "marker-"+marker.ID.String(), "events.marker_id = ?", markerID)
+}
+
+// GetCityEventsICS menyajikan feed iCalendar berisi event di semua marker pada :city. (Public)
+func (ec *EventController) GetCityEventsICS(c *gin.Context) {
+	city := strings.TrimSpace(c.Param("city"))
+	ec.writeEventFeed(c, city, "city-"+strings.ToLower(strings.ReplaceAll(city, " ", "-")), "LOWER(markers.city) = LOWER(?)", city)
+}
+
+// writeEventFeed menulis event yang cocok dengan kondisi sebagai file .ics. Event berulang ditulis
+// sebagai satu VEVENT dengan RRULE sehingga aplikasi kalender menghitung kejadiannya sendiri.
+func (ec *EventController) writeEventFeed(c *gin.Context, name, filename, condition string, args ...interface{}) {
+	var events []models.Event
+	if err := ec.DB.Joins("JOIN markers ON markers.id = events.marker_id AND markers.deleted_at IS NULL AND markers.status = ?", models.MarkerStatusApproved).
+		Where(condition, args...).
+		Where("events.series_ends_at IS NULL OR events.series_ends_at > ?", time.Now().AddDate(0, 0, -icsHistoryDays)).
+		Preload("Marker").Order("events.starts_at").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events: " + err.Error()})
+		return
+	}
+
+	items := make([]calendar.ICSEvent, 0, len(events))
+	for _, event := range events {
+		rule, location := eventRule(&event)
+		item := calendar.ICSEvent{
+			UID:       event.ID.String() + "@ulyngo",
+			Summary:   event.Title,
+			Start:     event.StartsAt.In(location),
+			End:       event.EndsAt.In(location),
+			Rule:      rule,
+			Latitude:  event.Marker.Latitude,
+			Longitude: event.Marker.Longitude,
+			Updated:   event.UpdatedAt,
+		}
+		description := []string{}
+		if event.Description != nil && *event.Description != "" {
+			description = append(description, *event.Description)
+		}
+		if event.Price != nil {
+			if *event.Price == 0 {
+				description = append(description, "Price: free")
+			} else {
+				description = append(description, fmt.Sprintf("Price: %s %.2f", event.Currency, *event.Price))
+			}
+		}
+		item.Description = strings.Join(description, "\n\n")
+		item.Location = event.Marker.Name
+		if event.Marker.City != nil && *event.Marker.City != "" {
+			item.Location += ", " + *event.Marker.City
+		}
+		if event.URL != nil {
+			item.URL = *event.URL
+		}
+		items = append(items, item)
+	}
+
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename+".ics"))
+	c.Status(http.StatusOK)
+	if err := calendar.WriteICS(c.Writer, name, items); err != nil {
+		c.Error(err)
+	}
+}
+
+// CreateEvent menambahkan event ke marker :id. (Protected)
+// Hanya pemilik marker atau admin yang boleh mengelola event.
+func (ec *EventController) CreateEvent(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	marker, ok := ec.authorizeEventMarker(c, c.Param("id"), userID)
+	if !ok {
+		return
+	}
+	var input EventInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	event := models.Event{
+		MarkerID:        marker.ID,
+		Title:           input.Title,
+		Description:     input.Description,
+		StartsAt:        input.StartsAt,
+		EndsAt:          input.EndsAt,
+		Timezone:        input.Timezone,
+		RRule:           input.RRule,
+		Price:           input.Price,
+		Currency:        input.Currency,
+		URL:             input.URL,
+		CreatedByUserID: userID,
+	}
+	if err := prepareEvent(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := ec.DB.Create(&event).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Event created successfully", "event": event})
+}
+
+// UpdateEvent memperbarui event berdasarkan :id. (Protected)
+func (ec *EventController) UpdateEvent(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	event, ok := ec.findEvent(c)
+	if !ok {
+		return
+	}
+	if _, ok := ec.authorizeEventMarker(c, event.MarkerID.String(), userID); !ok {
+		return
+	}
+	var input UpdateEventInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Title != nil {
+		event.Title = *input.Title
+	}
+	if input.Description != nil {
+		event.Description = input.Description
+	}
+	if input.StartsAt != nil {
+		event.StartsAt = *input.StartsAt
+	}
+	if input.EndsAt != nil {
+		event.EndsAt = *input.EndsAt
+	}
+	if input.Timezone != nil {
+		event.Timezone = *input.Timezone
+	}
+	if input.RRule != nil {
+		event.RRule = input.RRule
+	}
+	if input.Price != nil {
+		event.Price = input.Price
+	}
+	if input.Currency != nil {
+		event.Currency = *input.Currency
+	}
+	if input.URL != nil {
+		event.URL = input.URL
+	}
+	if err := prepareEvent(event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := ec.DB.Omit("Marker").Save(event).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully", "event": event})
+}
+
+// DeleteEvent menghapus (soft delete) event berdasarkan :id. (Protected)
+func (ec *EventController) DeleteEvent(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	event, ok := ec.findEvent(c)
+	if !ok {
+		return
+	}
+	if _, ok := ec.authorizeEventMarker(c, event.MarkerID.String(), userID); !ok {
+		return
+	}
+	if err := ec.DB.Delete(&models.Event{}, "id = ?", event.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
+}
+
+// findEvent mencari event berdasarkan :id beserta markernya. Event pada marker yang belum disetujui
+// atau sudah dihapus dianggap tidak ada.
+func (ec *EventController) findEvent(c *gin.Context) (*models.Event, bool) {
+	eventID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID format"})
+		return nil, false
+	}
+	var event models.Event
+	err = ec.DB.Joins("JOIN markers ON markers.id = events.marker_id AND markers.deleted_at IS NULL AND markers.status = ?", models.MarkerStatusApproved).
+		Preload("Marker").First(&event, "events.id = ?", eventID).Error
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event: " + err.Error()})
+		return nil, false
+	}
+	return &event, true
+}
+
+// authorizeEventMarker memastikan marker ada dan pengguna adalah pemiliknya atau admin.
+func (ec *EventController) authorizeEventMarker(c *gin.Context, rawMarkerID string, userID uuid.UUID) (*models.Marker, bool) {
+	markerID, err := uuid.Parse(rawMarkerID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return nil, false
+	}
+	var marker models.Marker
+	err = ec.DB.Select("id", "added_by_user_id", "status").First(&marker, "id = ?", markerID).Error
+	if err == gorm.ErrRecordNotFound || (err == nil && marker.Status != models.MarkerStatusApproved) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Marker not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find marker: " + err.Error()})
+		return nil, false
+	}
+	if marker.AddedByUserID != userID && currentUserRole(c) != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the marker owner or an admin can manage its events"})
+		return nil, false
+	}
+	return &marker, true
+}
+
+// localizeEventMarkers menerjemahkan marker yang dimuat bersama event.
+func (ec *EventController) localizeEventMarkers(c *gin.Context, events []models.Event) error {
+	targets := []translationTarget{}
+	for i := range events {
+		if events[i].Marker != nil {
+			targets = append(targets, markerTranslationTargets(events[i].Marker)...)
+		}
+	}
+	return applyTranslations(ec.DB, requestLocaleChain(c), targets)
+}
+
+// prepareEvent memvalidasi dan menormalkan event, lalu menghitung SeriesEndsAt dari aturan pengulangan.
+func prepareEvent(event *models.Event) error {
+	event.Title = strings.TrimSpace(event.Title)
+	if event.Title == "" || len(event.Title) > 200 {
+		return fmt.Errorf("title must be between 1 and 200 characters")
+	}
+	if !event.EndsAt.After(event.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	if event.EndsAt.Sub(event.StartsAt) > maxEventDuration {
+		return fmt.Errorf("a single occurrence cannot last longer than 31 days")
+	}
+	if event.Timezone == "" {
+		event.Timezone = models.DefaultEventTimezone
+	}
+	location, err := time.LoadLocation(event.Timezone)
+	if err != nil {
+		return fmt.Errorf("unknown timezone %q", event.Timezone)
+	}
+	if event.RRule != nil && strings.TrimSpace(*event.RRule) == "" {
+		event.RRule = nil
+	}
+	if event.RRule != nil {
+		rule, err := calendar.ParseRule(*event.RRule, event.StartsAt.In(location))
+		if err != nil {
+			return fmt.Errorf("invalid rrule: %v", err)
+		}
+		normalized := rule.String()
+		event.RRule = &normalized
+	}
+	if event.Price != nil && *event.Price < 0 {
+		return fmt.Errorf("price cannot be negative")
+	}
+	event.Currency = strings.ToUpper(strings.TrimSpace(event.Currency))
+	if event.Currency == "" {
+		event.Currency = "IDR"
+	}
+	if len(event.Currency) != 3 {
+		return fmt.Errorf("currency must be a 3-letter ISO 4217 code")
+	}
+	if event.URL != nil && strings.TrimSpace(*event.URL) == "" {
+		event.URL = nil
+	}
+	if event.URL != nil {
+		parsed, err := url.ParseRequestURI(*event.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("url must be an absolute http or https URL")
+		}
+	}
+
+	rule, location := eventRule(event)
+	event.SeriesEndsAt = nil
+	if last := calendar.LastStart(rule, event.StartsAt.In(location)); last != nil {
+		seriesEnd := last.Add(event.EndsAt.Sub(event.StartsAt))
+		event.SeriesEndsAt = &seriesEnd
+	}
+	return nil
+}
+
+// eventRule mengembalikan aturan pengulangan dan zona waktu event. Nilai yang tersimpan sudah
+// divalidasi saat disimpan, sehingga kegagalan parse diperlakukan sebagai event tunggal.
+func eventRule(event *models.Event) (*calendar.Rule, *time.Location) {
+	location, err := time.LoadLocation(event.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	if event.RRule == nil {
+		return nil, location
+	}
+	rule, err := calendar.ParseRule(*event.RRule, event.StartsAt.In(location))
+	if err != nil {
+		return nil, location
+	}
+	return rule, location
+}
+
+// expandEvent menghasilkan kejadian event yang beririsan dengan [from, to).
+func expandEvent(event *models.Event, from, to time.Time, limit int) []EventOccurrence {
+	rule, location := eventRule(event)
+	duration := event.EndsAt.Sub(event.StartsAt)
+	starts := calendar.Occurrences(rule, event.StartsAt.In(location), duration, from, to, limit)
+	occurrences := make([]EventOccurrence, len(starts))
+	for i, start := range starts {
+		occurrences[i] = EventOccurrence{EventID: event.ID, StartsAt: start, EndsAt: start.Add(duration), Event: event}
+	}
+	return occurrences
+}
+
+// activeEventsQuery memilih event pada marker yang disetujui yang mungkin memiliki kejadian di [from, to).
+func activeEventsQuery(db *gorm.DB, from, to time.Time) *gorm.DB {
+	return db.Model(&models.Event{}).
+		Joins("JOIN markers ON markers.id = events.marker_id AND markers.deleted_at IS NULL AND markers.status = ?", models.MarkerStatusApproved).
+		Where("events.starts_at < ?", to).
+		Where("events.series_ends_at IS NULL OR events.series_ends_at > ?", from)
+}
+
+// markersHappeningNow mengembalikan ID marker yang sedang memiliki kejadian event pada waktu now.
+func markersHappeningNow(db *gorm.DB, now time.Time) ([]uuid.UUID, error) {
+	var events []models.Event
+	if err := activeEventsQuery(db, now, now.Add(time.Second)).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	seen := map[uuid.UUID]bool{}
+	ids := []uuid.UUID{}
+	for i := range events {
+		if seen[events[i].MarkerID] {
+			continue
+		}
+		if len(expandEvent(&events[i], now, now.Add(time.Second), 1)) > 0 {
+			seen[events[i].MarkerID] = true
+			ids = append(ids, events[i].MarkerID)
+		}
+	}
+	return ids, nil
+}
+
+// parseEventRange membaca ?from= dan ?to= (RFC 3339). Default: sekarang sampai 30 hari ke depan.
+func parseEventRange(c *gin.Context) (time.Time, time.Time, bool) {
+	from := time.Now()
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp"})
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 0, defaultEventRangeDays)
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp"})
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+	if !to.After(from) || to.Sub(from) > maxEventRangeDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("to must be after from and within %d days", maxEventRangeDays)})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
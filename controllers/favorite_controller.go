@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"net/http"
+
+	"ulyngo/models"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FavoriteController menangani marker favorit milik pengguna.
+type FavoriteController struct {
+	DB *gorm.DB
+}
+
+// NewFavoriteController adalah konstruktor untuk FavoriteController.
+func NewFavoriteController(db *gorm.DB) *FavoriteController {
+	return &FavoriteController{DB: db}
+}
+
+// GetMyFavorites mengambil marker favorit pengguna saat ini, terbaru lebih dulu. (Protected)
+// Marker yang sudah dihapus atau tidak lagi disetujui tidak ditampilkan.
+func (fc *FavoriteController) GetMyFavorites(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var favorites []models.Favorite
+	if err := fc.DB.Joins("JOIN markers ON markers.id = favorites.marker_id AND markers.deleted_at IS NULL AND markers.status = ?", models.MarkerStatusApproved).
+		Where("favorites.user_id = ?", userID).
+		Preload("Marker").
+		Order("favorites.created_at DESC").
+		Find(&favorites).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch favorites: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, favorites)
+}
+
+// AddFavorite menambahkan marker :markerID ke favorit pengguna saat ini. (Protected)
+// Bersifat idempoten: memfavoritkan ulang marker yang sama tidak dianggap error.
+func (fc *FavoriteController) AddFavorite(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	markerID, ok := findApprovedMarkerID(c, fc.DB, c.Param("markerID"))
+	if !ok {
+		return
+	}
+
+	favorite := models.Favorite{UserID: userID, MarkerID: markerID}
+	err := fc.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&favorite)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return utils.LogActivity(tx, userID, "favorite_marker", &markerID, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add favorite: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Marker added to favorites", "marker_id": markerID})
+}
+
+// RemoveFavorite menghapus marker :markerID dari favorit pengguna saat ini. (Protected)
+func (fc *FavoriteController) RemoveFavorite(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	markerID, err := uuid.Parse(c.Param("markerID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return
+	}
+
+	result := fc.DB.Where("user_id = ? AND marker_id = ?", userID, markerID).Delete(&models.Favorite{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove favorite: " + result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Marker is not in favorites"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Marker removed from favorites"})
+}
+
+// findApprovedMarkerID mem-parse rawID dan memastikan marker tersebut ada dan sudah disetujui.
+// Menulis respons error sendiri jika gagal.
+func findApprovedMarkerID(c *gin.Context, db *gorm.DB, rawID string) (uuid.UUID, bool) {
+	markerID, err := uuid.Parse(rawID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return uuid.Nil, false
+	}
+	var count int64
+	if err := db.Model(&models.Marker{}).Where("id = ? AND status = ?", markerID, models.MarkerStatusApproved).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find marker: " + err.Error()})
+		return uuid.Nil, false
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Marker not found"})
+		return uuid.Nil, false
+	}
+	return markerID, true
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// currentUserID mengambil ID pengguna dari konteks Gin (diset oleh AuthMiddleware) dan mengonversinya ke UUID.
+// Jika gagal, respons error langsung dikirim dan nilai ok bernilai false.
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return uuid.Nil, false
+	}
+	userIDStr, ok := userID.(string)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// currentUserRole mengembalikan role pengguna dari konteks Gin, atau string kosong jika tidak ada.
+func currentUserRole(c *gin.Context) string {
+	role, _ := c.Get("role")
+	roleStr, _ := role.(string)
+	return roleStr
+}
+
+// isModerator bernilai true untuk pengguna dengan role admin atau moderator.
+func isModerator(c *gin.Context) bool {
+	role := currentUserRole(c)
+	return role == "admin" || role == "moderator"
+}
+
+// geoBounds adalah kotak lintang/bujur untuk filter area peta.
+type geoBounds struct {
+	MinLat, MinLng, MaxLat, MaxLng float64
+}
+
+// parseLatLng mem-parse koordinat berformat "lat,lng".
+func parseLatLng(value string) (float64, float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected lat,lng")
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, fmt.Errorf("latitude must be between -90 and 90")
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return 0, 0, fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return lat, lng, nil
+}
+
+// parseBBox mem-parse kotak area berformat "min_lng,min_lat,max_lng,max_lat" (urutan GeoJSON).
+func parseBBox(value string) (*geoBounds, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("expected min_lng,min_lat,max_lng,max_lat")
+	}
+	values := make([]float64, 4)
+	for i, part := range parts {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coordinate %q", part)
+		}
+		values[i] = parsed
+	}
+	bounds := &geoBounds{MinLng: values[0], MinLat: values[1], MaxLng: values[2], MaxLat: values[3]}
+	if bounds.MinLat < -90 || bounds.MaxLat > 90 || bounds.MinLng < -180 || bounds.MaxLng > 180 ||
+		bounds.MinLat > bounds.MaxLat || bounds.MinLng > bounds.MaxLng {
+		return nil, fmt.Errorf("coordinates out of range or min greater than max")
+	}
+	return bounds, nil
+}
+
+// escapeLike meng-escape karakter wildcard LIKE/ILIKE (%, _, dan backslash) agar input pengguna dicocokkan apa adanya.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"ulyngo/blobstore"
+	"ulyngo/imaging"
+	"ulyngo/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	derivedImageQuality = 80
+	derivedCacheControl = "public, max-age=31536000, immutable"
+)
+
+// allowedImageSizes adalah daftar lebar/tinggi yang boleh diminta. Ukuran dibatasi agar
+// klien tidak bisa memicu pembuatan varian tanpa batas yang menghabiskan CPU dan cache.
+var allowedImageSizes = map[int]bool{
+	64: true, 128: true, 256: true, 320: true, 480: true, 640: true,
+	800: true, 1024: true, 1280: true, 1600: true, 1920: true,
+}
+
+// maxImageSize adalah ukuran terbesar di allowedImageSizes, dipakai jika w dan h tidak diisi.
+const maxImageSize = 1920
+
+// resizeSlots membatasi jumlah proses resize yang berjalan bersamaan.
+var resizeSlots = make(chan struct{}, runtime.NumCPU())
+
+// ServeImage menyajikan gambar marker dengan ukuran dan format yang disesuaikan. (Public)
+// Query: w dan h (harus termasuk allowedImageSizes; 0 atau kosong = mengikuti rasio aspek; jika keduanya
+// kosong, gambar dimuat dalam maxImageSize x maxImageSize dengan fit contain), fit (contain, cover, fill; default contain). Format WebP dipilih jika header Accept mendukungnya,
+// selain itu JPEG. Hasil disimpan di cache disk dan diberi ETag kuat. Gambar marker yang belum disetujui
+// hanya disajikan untuk pemilik marker dan moderator (token opsional).
+func (ic *MarkerImageController) ServeImage(c *gin.Context) {
+	imageID, err := uuid.Parse(c.Param("imageID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID format"})
+		return
+	}
+	width, err := parseImageSize(c.Query("w"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "w " + err.Error()})
+		return
+	}
+	height, err := parseImageSize(c.Query("h"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "h " + err.Error()})
+		return
+	}
+	fit := c.DefaultQuery("fit", imaging.FitContain)
+	if fit != imaging.FitContain && fit != imaging.FitCover && fit != imaging.FitFill {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fit must be one of contain, cover, fill"})
+		return
+	}
+	// Tanpa ukuran, gambar asli (hingga imaging.MaxSourcePixels) tidak disajikan apa adanya
+	if width == 0 && height == 0 {
+		width, height, fit = maxImageSize, maxImageSize, imaging.FitContain
+	}
+
+	var markerImage models.MarkerImage
+	err = ic.DB.Joins("JOIN markers ON markers.id = marker_images.marker_id AND markers.deleted_at IS NULL").
+		First(&markerImage, "marker_images.id = ?", imageID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find image: " + err.Error()})
+		}
+		return
+	}
+	// Gambar marker yang belum disetujui hanya terlihat oleh pemilik marker dan moderator
+	var marker models.Marker
+	if err := ic.DB.Select("id", "status", "added_by_user_id").First(&marker, "id = ?", markerImage.MarkerID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find marker: " + err.Error()})
+		return
+	}
+	cacheControl := derivedCacheControl
+	if marker.Status != models.MarkerStatusApproved {
+		rawUserID, _ := c.Get("userID")
+		userIDStr, _ := rawUserID.(string)
+		userID, err := uuid.Parse(userIDStr)
+		if !isModerator(c) && (err != nil || userID != marker.AddedByUserID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+			return
+		}
+		// Jangan biarkan cache bersama menyimpan gambar yang tidak publik
+		cacheControl = "private, no-store"
+	}
+	// Gambar lama yang hanya berupa URL eksternal tidak bisa diproses
+	if markerImage.StorageKey == "" {
+		c.Redirect(http.StatusFound, markerImage.ImageURL)
+		return
+	}
+
+	format := negotiateImageFormat(c.GetHeader("Accept"))
+	// Blob asli tidak pernah berubah untuk key yang sama, sehingga ETag cukup diturunkan dari parameter varian
+	variant := fmt.Sprintf("%s|%d|%d|%s|%s", markerImage.StorageKey, width, height, fit, format)
+	sum := sha256.Sum256([]byte(variant))
+	cacheKey := hex.EncodeToString(sum[:16]) + "." + format
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cacheControl)
+	c.Header("Vary", "Accept")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	if ic.Cache != nil {
+		if data, ok := ic.Cache.Get(cacheKey); ok {
+			c.Data(http.StatusOK, "image/"+format, data)
+			return
+		}
+	}
+
+	resizeSlots <- struct{}{}
+	data, err := ic.renderVariant(c, markerImage.StorageKey, width, height, fit, format)
+	<-resizeSlots
+	if err != nil {
+		// Respons error tidak boleh di-cache dengan header varian
+		c.Writer.Header().Del("ETag")
+		c.Header("Cache-Control", "no-store")
+		if err == blobstore.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image file not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process image: " + err.Error()})
+		return
+	}
+	if ic.Cache != nil {
+		if err := ic.Cache.Put(cacheKey, data); err != nil {
+			log.Printf("Failed to cache derived image %s: %v", cacheKey, err)
+		}
+	}
+	c.Data(http.StatusOK, "image/"+format, data)
+}
+
+// renderVariant membaca gambar asli dari BlobStore lalu membuat varian sesuai ukuran, fit, dan format.
+func (ic *MarkerImageController) renderVariant(c *gin.Context, key string, width, height int, fit, format string) ([]byte, error) {
+	reader, err := ic.Store.Get(c.Request.Context(), key)
+	if err != nil {
+		return nil, err
+	}
+	original, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// Gambar asli di atas imaging.MaxSourcePixels tidak diproses
+	decoded, err := imaging.Decode(original)
+	if err != nil {
+		return nil, err
+	}
+	upright := imaging.ApplyOrientation(decoded, imaging.Orientation(original))
+	resized := imaging.Transform(upright, width, height, fit)
+
+	if format == "webp" {
+		return imaging.EncodeWebP(resized, derivedImageQuality)
+	}
+	return imaging.EncodeJPEG(resized, derivedImageQuality)
+}
+
+// parseImageSize membaca parameter ukuran. Kosong atau "0" berarti mengikuti rasio aspek.
+func parseImageSize(value string) (int, error) {
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || !allowedImageSizes[size] {
+		return 0, fmt.Errorf("must be one of the allowed sizes: 64, 128, 256, 320, 480, 640, 800, 1024, 1280, 1600, 1920")
+	}
+	return size, nil
+}
+
+// negotiateImageFormat memilih "webp" jika klien menerimanya dan encoder tersedia, selain itu "jpeg".
+func negotiateImageFormat(accept string) string {
+	if imaging.WebPSupported() {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			if strings.TrimSpace(mediaType) == "image/webp" && acceptQuality(params) > 0 {
+				return "webp"
+			}
+		}
+	}
+	return "jpeg"
+}
+
+// acceptQuality membaca nilai q dari parameter media type pada header Accept (default 1).
+func acceptQuality(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if strings.TrimSpace(name) == "q" {
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return 0
+			}
+			return q
+		}
+	}
+	return 1
+}
+
+// etagMatches memeriksa apakah header If-None-Match memuat etag yang diberikan (atau "*").
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
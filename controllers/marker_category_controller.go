@@ -1,12 +1,20 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 	"ulyngo/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MarkerCategoryController struct {
@@ -17,10 +25,29 @@ func NewMarkerCategoryController(db *gorm.DB) *MarkerCategoryController {
 	return &MarkerCategoryController{DB: db}
 }
 
+// Errors returned by moveCategory when a move would break the category tree.
+var (
+	errCategoryCycle   = errors.New("a category cannot be moved into itself or one of its descendants")
+	errCategoryTooDeep = fmt.Errorf("the category tree cannot be deeper than %d levels", models.MaxCategoryDepth+1)
+)
+
+// Errors returned inside the DeleteCategory transaction when the category cannot be deleted yet.
+var (
+	errCategoryHasChildren = errors.New("category has subcategories")
+	errCategoryInUse       = errors.New("category is still used by markers")
+)
+
+// hexColorPattern matches colors in #rrggbb form.
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
 // CreateCategoryInput defines the structure for creating a new marker category.
 type CreateCategoryInput struct {
-	Name        string  `json:"name" binding:"required"`
-	Description *string `json:"description"`
+	Name        string     `json:"name" binding:"required"`
+	Description *string    `json:"description"`
+	ParentID    *uuid.UUID `json:"parent_id"` // Optional parent; omit for a root category
+	Icon        *string    `json:"icon"`      // Icon key from GET /api/marker/categories/icons
+	Color       *string    `json:"color"`     // Hex color, e.g. "#e67e22"
+	SortOrder   int        `json:"sort_order"`
 }
 
 // CreateCategory handles the creation of a new marker category. (Admin Protected)
@@ -31,29 +58,147 @@ func (cc *MarkerCategoryController) CreateCategory(c *gin.Context) {
 		return
 	}
 
+	icon, color, err := normalizeCategoryPresentation(input.Icon, input.Color)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	category := models.MarkerCategory{
 		Name:        input.Name,
 		Description: input.Description,
+		Icon:        icon,
+		Color:       color,
+		SortOrder:   input.SortOrder,
 	}
-
-	if err := cc.DB.Create(&category).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category: " + err.Error()})
+	err = cc.DB.Transaction(func(tx *gorm.DB) error {
+		// The parent's path must not change between reading it and inserting the child
+		if err := lockCategoryTree(tx); err != nil {
+			return err
+		}
+		parent, err := findParentCategory(tx, input.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent != nil {
+			if parent.Depth >= models.MaxCategoryDepth {
+				return errCategoryTooDeep
+			}
+			category.ParentID = &parent.ID
+		}
+		// Path and Depth are filled from the parent by the BeforeCreate hook
+		return tx.Create(&category).Error
+	})
+	if err != nil {
+		if errors.Is(err, errParentNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent category not found"})
+		} else if errors.Is(err, errCategoryTooDeep) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category: " + err.Error()})
+		}
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{"message": "Category created successfully", "category": category})
 }
 
-// GetAllCategories retrieves all marker categories. (Public)
+// GetAllCategories retrieves all marker categories ordered by sort order and name, with the number of
+// approved markers in each category (marker_count) and including its subcategories (total_marker_count). (Public)
 func (cc *MarkerCategoryController) GetAllCategories(c *gin.Context) {
 	var categories []models.MarkerCategory
-	if err := cc.DB.Find(&categories).Error; err != nil {
+	if err := cc.DB.Order("sort_order, name").Find(&categories).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories: " + err.Error()})
 		return
 	}
+	var counts []struct {
+		CategoryID uuid.UUID
+		Total      int64
+	}
+	if err := cc.DB.Model(&models.Marker{}).Select("category_id, COUNT(*) AS total").
+		Where("status = ?", models.MarkerStatusApproved).Group("category_id").Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count markers: " + err.Error()})
+		return
+	}
+	direct := make(map[uuid.UUID]int64, len(counts))
+	for _, count := range counts {
+		direct[count.CategoryID] = count.Total
+	}
+	markerCounts, totalCounts := make([]int64, len(categories)), make([]int64, len(categories))
+	for i := range categories {
+		markerCounts[i] = direct[categories[i].ID]
+		categories[i].MarkerCount, categories[i].TotalMarkerCount = &markerCounts[i], &totalCounts[i]
+	}
+	// In path order every subtree is contiguous, so a stack of the current ancestors is enough
+	// to add each category's markers to itself and all of its ancestors in one pass.
+	byPath := make([]int, len(categories))
+	for i := range byPath {
+		byPath[i] = i
+	}
+	sort.Slice(byPath, func(a, b int) bool { return categories[byPath[a]].Path < categories[byPath[b]].Path })
+	ancestors := []int{}
+	for _, i := range byPath {
+		for len(ancestors) > 0 && !strings.HasPrefix(categories[i].Path, categories[ancestors[len(ancestors)-1]].Path) {
+			ancestors = ancestors[:len(ancestors)-1]
+		}
+		ancestors = append(ancestors, i)
+		for _, ancestor := range ancestors {
+			totalCounts[ancestor] += markerCounts[i]
+		}
+	}
+	if err := localizeCategories(cc.DB, requestLocaleChain(c), categories); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, categories)
 }
 
-// GetCategoryByID retrieves a marker category by its ID. (Public)
+// GetCategoryTree retrieves all marker categories as a nested tree, ordered by sort order and name. (Public)
+func (cc *MarkerCategoryController) GetCategoryTree(c *gin.Context) {
+	var categories []models.MarkerCategory
+	if err := cc.DB.Order("depth, sort_order, name").Find(&categories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories: " + err.Error()})
+		return
+	}
+	if err := localizeCategories(cc.DB, requestLocaleChain(c), categories); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+
+	// Categories whose parent is missing (e.g. still in the trash) are shown as roots
+	exists := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		exists[category.ID] = true
+	}
+	childrenOf := map[uuid.UUID][]models.MarkerCategory{}
+	for _, category := range categories {
+		parentID := uuid.Nil
+		if category.ParentID != nil && exists[*category.ParentID] {
+			parentID = *category.ParentID
+		}
+		childrenOf[parentID] = append(childrenOf[parentID], category)
+	}
+	var build func(parentID uuid.UUID) []models.MarkerCategory
+	build = func(parentID uuid.UUID) []models.MarkerCategory {
+		nodes := childrenOf[parentID]
+		sort.SliceStable(nodes, func(i, j int) bool {
+			if nodes[i].SortOrder != nodes[j].SortOrder {
+				return nodes[i].SortOrder < nodes[j].SortOrder
+			}
+			return nodes[i].Name < nodes[j].Name
+		})
+		for i := range nodes {
+			nodes[i].Children = build(nodes[i].ID)
+		}
+		return nodes
+	}
+	tree := build(uuid.Nil)
+	if tree == nil {
+		tree = []models.MarkerCategory{}
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+// GetCategoryByID retrieves a marker category by its ID, with its breadcrumb trail and direct children. (Public)
 func (cc *MarkerCategoryController) GetCategoryByID(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
@@ -71,13 +216,36 @@ func (cc *MarkerCategoryController) GetCategoryByID(c *gin.Context) {
 		}
 		return
 	}
+	chain := requestLocaleChain(c)
+	if err := cc.DB.Where("parent_id = ?", category.ID).Order("sort_order, name").Find(&category.Children).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subcategories: " + err.Error()})
+		return
+	}
+	targets := []translationTarget{categoryTranslationTarget(&category)}
+	for i := range category.Children {
+		targets = append(targets, categoryTranslationTarget(&category.Children[i]))
+	}
+	if err := applyTranslations(cc.DB, chain, targets); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+	breadcrumbs, err := categoryBreadcrumbs(cc.DB, chain, []string{category.Path})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build breadcrumbs: " + err.Error()})
+		return
+	}
+	category.Breadcrumbs = breadcrumbs[category.Path]
 	c.JSON(http.StatusOK, category)
 }
 
 // UpdateCategoryInput defines the structure for updating a marker category.
+// Sending an empty string for icon or color clears it.
 type UpdateCategoryInput struct {
 	Name        *string `json:"name"`
 	Description *string `json:"description"`
+	Icon        *string `json:"icon"`
+	Color       *string `json:"color"`
+	SortOrder   *int    `json:"sort_order"`
 }
 
 // UpdateCategory handles the update of an existing marker category. (Admin Protected)
@@ -111,8 +279,25 @@ func (cc *MarkerCategoryController) UpdateCategory(c *gin.Context) {
 	if input.Description != nil {
 		category.Description = input.Description
 	}
+	if input.Icon != nil || input.Color != nil {
+		icon, color, err := normalizeCategoryPresentation(input.Icon, input.Color)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if input.Icon != nil {
+			category.Icon = icon
+		}
+		if input.Color != nil {
+			category.Color = color
+		}
+	}
+	if input.SortOrder != nil {
+		category.SortOrder = *input.SortOrder
+	}
 
-	if err := cc.DB.Save(&category).Error; err != nil {
+	// Only the editable columns are written so a concurrent move cannot be undone with a stale path
+	if err := cc.DB.Select("name", "description", "icon", "color", "sort_order", "updated_at").Updates(&category).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category: " + err.Error()})
 		return
 	}
@@ -120,6 +305,8 @@ func (cc *MarkerCategoryController) UpdateCategory(c *gin.Context) {
 }
 
 // DeleteCategory handles the deletion of a marker category. (Admin Protected)
+// Deletion is refused while markers (including trashed ones) still use the category, unless
+// ?reassign_to=<category_id> is given; the markers are then moved to that category in the same transaction.
 func (cc *MarkerCategoryController) DeleteCategory(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := uuid.Parse(idParam)
@@ -127,22 +314,422 @@ func (cc *MarkerCategoryController) DeleteCategory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
 		return
 	}
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
 
-	// Check if category exists
-	var category models.MarkerCategory
-	if err := cc.DB.First(&category, "id = ?", id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	var reassignTo *models.MarkerCategory
+	if v := c.Query("reassign_to"); v != "" {
+		targetID, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reassign_to category ID format"})
+			return
+		}
+		if targetID == id {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "reassign_to must be a different category"})
+			return
+		}
+		var target models.MarkerCategory
+		if err := cc.DB.First(&target, "id = ?", targetID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "reassign_to category not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category: " + err.Error()})
+			}
+			return
+		}
+		reassignTo = &target
+	}
+
+	var childCount int64
+	var markerIDs []uuid.UUID
+	err = cc.DB.Transaction(func(tx *gorm.DB) error {
+		// No subcategory can be moved under the category while it is being deleted
+		if err := lockCategoryTree(tx); err != nil {
+			return err
+		}
+		// The row lock also blocks new markers from referencing the category until the transaction ends
+		var category models.MarkerCategory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&category, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		// Subcategories must be moved or deleted first so they are not left without a parent
+		if err := tx.Model(&models.MarkerCategory{}).Where("parent_id = ?", category.ID).Count(&childCount).Error; err != nil {
+			return err
+		}
+		if childCount > 0 {
+			return errCategoryHasChildren
+		}
+
+		if err := tx.Unscoped().Model(&models.Marker{}).Where("category_id = ?", category.ID).Pluck("id", &markerIDs).Error; err != nil {
+			return err
+		}
+		if len(markerIDs) > 0 {
+			if reassignTo == nil {
+				return errCategoryInUse
+			}
+			if err := reassignCategoryMarkers(tx, markerIDs, reassignTo.ID, adminID); err != nil {
+				return err
+			}
+		}
+
+		// Perform soft delete
+		return tx.Delete(&category).Error
+	})
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	case errors.Is(err, errCategoryHasChildren):
+		c.JSON(http.StatusConflict, gin.H{"error": "Category has subcategories. Move or delete them first", "subcategories": childCount})
+		return
+	case errors.Is(err, errCategoryInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": "Category is still used by markers. Provide ?reassign_to= to move them to another category", "markers": len(markerIDs)})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category: " + err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "Category deleted successfully"}
+	if reassignTo != nil {
+		response["reassigned_to"] = reassignTo.ID
+		response["reassigned_markers"] = len(markerIDs)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCategoryIcons lists the icon keys accepted for categories. (Public)
+func (cc *MarkerCategoryController) GetCategoryIcons(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"icons": models.CategoryIcons})
+}
+
+// normalizeCategoryPresentation validates the icon key and hex color. Empty strings become nil
+// and colors are stored in lowercase.
+func normalizeCategoryPresentation(icon, color *string) (*string, *string, error) {
+	if icon != nil {
+		key := strings.TrimSpace(*icon)
+		if key == "" {
+			icon = nil
+		} else if !models.IsCategoryIcon(key) {
+			return nil, nil, fmt.Errorf("unknown icon %q; see GET /api/marker/categories/icons", key)
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category: " + err.Error()})
+			icon = &key
+		}
+	}
+	if color != nil {
+		value := strings.ToLower(strings.TrimSpace(*color))
+		if value == "" {
+			color = nil
+		} else if !hexColorPattern.MatchString(value) {
+			return nil, nil, fmt.Errorf("color must be a hex color in #rrggbb form")
+		} else {
+			color = &value
+		}
+	}
+	return icon, color, nil
+}
+
+// reassignCategoryMarkers moves markers to another category and records a revision for each of them.
+func reassignCategoryMarkers(tx *gorm.DB, markerIDs []uuid.UUID, categoryID, adminID uuid.UUID) error {
+	before := make(map[uuid.UUID]*markerSnapshot, len(markerIDs))
+	for _, markerID := range markerIDs {
+		snapshot, err := loadMarkerSnapshot(tx, markerID)
+		if err != nil {
+			return err
 		}
+		before[markerID] = snapshot
+	}
+	if err := tx.Unscoped().Model(&models.Marker{}).Where("id IN ?", markerIDs).Update("category_id", categoryID).Error; err != nil {
+		return err
+	}
+	for _, markerID := range markerIDs {
+		after := *before[markerID]
+		after.CategoryID = categoryID
+		if _, err := recordMarkerRevision(tx, markerID, models.RevisionActionUpdate, adminID, before[markerID], &after, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MoveCategoryInput defines the target parent for move and reparent operations.
+type MoveCategoryInput struct {
+	ParentID *uuid.UUID `json:"parent_id"` // New parent; null moves to the root level
+}
+
+// MoveCategory moves a category, together with all of its descendants, under a new parent. (Admin Protected)
+func (cc *MarkerCategoryController) MoveCategory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+		return
+	}
+	var input MoveCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Perform soft delete
-	if err := cc.DB.Delete(&category).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category: " + err.Error()})
+	var category models.MarkerCategory
+	err = cc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockCategoryTree(tx); err != nil {
+			return err
+		}
+		if err := tx.First(&category, "id = ?", id).Error; err != nil {
+			return err
+		}
+		parent, err := findParentCategory(tx, input.ParentID)
+		if err != nil {
+			return err
+		}
+		return moveCategory(tx, &category, parent)
+	})
+	if !cc.respondMoveError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Category moved successfully", "category": category})
+}
+
+// ReparentChildren moves all direct subcategories of a category (with their descendants) under a new parent. (Admin Protected)
+func (cc *MarkerCategoryController) ReparentChildren(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+	var input MoveCategoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var children []models.MarkerCategory
+	err = cc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockCategoryTree(tx); err != nil {
+			return err
+		}
+		var category models.MarkerCategory
+		if err := tx.First(&category, "id = ?", id).Error; err != nil {
+			return err
+		}
+		parent, err := findParentCategory(tx, input.ParentID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("parent_id = ?", category.ID).Order("name").Find(&children).Error; err != nil {
+			return err
+		}
+		for i := range children {
+			if err := moveCategory(tx, &children[i], parent); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if !cc.respondMoveError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Subcategories moved successfully", "moved": len(children), "categories": children})
+}
+
+// categoryTreeLockKey is the pg_advisory_xact_lock key that serializes changes to the category tree.
+const categoryTreeLockKey = 7_302_115
+
+// lockCategoryTree takes a transaction-scoped advisory lock so that concurrent moves and creates see each
+// other's paths; without it two moves could each pass the cycle check and together create a cycle.
+func lockCategoryTree(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", categoryTreeLockKey).Error
+}
+
+// errParentNotFound is returned when the requested parent category does not exist.
+var errParentNotFound = errors.New("parent category not found")
+
+// findParentCategory loads the requested parent category, or returns nil for the root level.
+func findParentCategory(tx *gorm.DB, parentID *uuid.UUID) (*models.MarkerCategory, error) {
+	if parentID == nil {
+		return nil, nil
+	}
+	var parent models.MarkerCategory
+	if err := tx.First(&parent, "id = ?", *parentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errParentNotFound
+		}
+		return nil, err
+	}
+	return &parent, nil
+}
+
+// respondMoveError writes the error response for move operations. Returns true when err is nil.
+func (cc *MarkerCategoryController) respondMoveError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case err == gorm.ErrRecordNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	case errors.Is(err, errParentNotFound), errors.Is(err, errCategoryTooDeep):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, errCategoryCycle):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move category: " + err.Error()})
+	}
+	return false
+}
+
+// moveCategory re-parents category and rewrites the path and depth of its whole subtree,
+// including trashed descendants. parent nil moves the category to the root level.
+// The caller must hold lockCategoryTree so the paths read here are current.
+func moveCategory(tx *gorm.DB, category *models.MarkerCategory, parent *models.MarkerCategory) error {
+	newPath, newDepth := "/"+category.ID.String()+"/", 0
+	if parent != nil {
+		if strings.HasPrefix(parent.Path, category.Path) {
+			return errCategoryCycle
+		}
+		newPath, newDepth = parent.Path+category.ID.String()+"/", parent.Depth+1
+	}
+
+	var maxDepth int
+	if err := tx.Unscoped().Model(&models.MarkerCategory{}).Select("COALESCE(MAX(depth), 0)").
+		Where("path LIKE ?", category.Path+"%").Scan(&maxDepth).Error; err != nil {
+		return err
+	}
+	if newDepth+maxDepth-category.Depth > models.MaxCategoryDepth {
+		return errCategoryTooDeep
+	}
+
+	if err := tx.Exec(`UPDATE marker_categories SET path = ? || SUBSTRING(path FROM ?), depth = depth + ?, updated_at = ?
+		WHERE path LIKE ?`, newPath, len(category.Path)+1, newDepth-category.Depth, time.Now(), category.Path+"%").Error; err != nil {
+		return err
+	}
+	var parentID *uuid.UUID
+	if parent != nil {
+		parentID = &parent.ID
+	}
+	if err := tx.Model(&models.MarkerCategory{}).Where("id = ?", category.ID).Update("parent_id", parentID).Error; err != nil {
+		return err
+	}
+	category.ParentID, category.Path, category.Depth = parentID, newPath, newDepth
+	return nil
+}
+
+// categorySubtree returns a subquery selecting the IDs of a category and all of its descendants,
+// for use as "category_id IN (?)".
+func categorySubtree(db *gorm.DB, categoryID uuid.UUID) *gorm.DB {
+	return db.Model(&models.MarkerCategory{}).Select("id").
+		Where("path LIKE (SELECT path FROM marker_categories WHERE id = ?) || '%'", categoryID)
+}
+
+// categoryBreadcrumbs resolves materialized paths into localized breadcrumb trails, keyed by path.
+func categoryBreadcrumbs(db *gorm.DB, chain []string, paths []string) (map[string][]models.CategoryBreadcrumb, error) {
+	idSet := map[uuid.UUID]bool{}
+	for _, path := range paths {
+		for _, id := range categoryPathIDs(path) {
+			idSet[id] = true
+		}
+	}
+	result := map[string][]models.CategoryBreadcrumb{}
+	if len(idSet) == 0 {
+		return result, nil
+	}
+	ancestors := []models.MarkerCategory{}
+	if err := db.Unscoped().Select("id", "name", "description").Where("id IN ?", keysOfSet(idSet)).Find(&ancestors).Error; err != nil {
+		return nil, err
+	}
+	if err := localizeCategories(db, chain, ancestors); err != nil {
+		return nil, err
+	}
+	names := make(map[uuid.UUID]string, len(ancestors))
+	for _, ancestor := range ancestors {
+		names[ancestor.ID] = ancestor.Name
+	}
+	for _, path := range paths {
+		crumbs := []models.CategoryBreadcrumb{}
+		for _, id := range categoryPathIDs(path) {
+			if name, ok := names[id]; ok {
+				crumbs = append(crumbs, models.CategoryBreadcrumb{ID: id, Name: name})
+			}
+		}
+		result[path] = crumbs
+	}
+	return result, nil
+}
+
+// attachCategoryBreadcrumbs fills CategoryBreadcrumbs on each marker from its category path.
+func attachCategoryBreadcrumbs(db *gorm.DB, chain []string, markers []models.Marker) error {
+	idSet := map[uuid.UUID]bool{}
+	for _, marker := range markers {
+		idSet[marker.CategoryID] = true
+	}
+	if len(idSet) == 0 {
+		return nil
+	}
+	var categories []models.MarkerCategory
+	if err := db.Unscoped().Select("id", "path").Where("id IN ?", keysOfSet(idSet)).Find(&categories).Error; err != nil {
+		return err
+	}
+	pathOf := make(map[uuid.UUID]string, len(categories))
+	paths := make([]string, 0, len(categories))
+	for _, category := range categories {
+		pathOf[category.ID] = category.Path
+		paths = append(paths, category.Path)
+	}
+	breadcrumbs, err := categoryBreadcrumbs(db, chain, paths)
+	if err != nil {
+		return err
+	}
+	for i := range markers {
+		markers[i].CategoryBreadcrumbs = breadcrumbs[pathOf[markers[i].CategoryID]]
+	}
+	return nil
+}
+
+// categoryPathIDs parses a materialized path ("/<root>/.../<id>/") into category IDs, root first.
+func categoryPathIDs(path string) []uuid.UUID {
+	ids := []uuid.UUID{}
+	for _, part := range strings.Split(path, "/") {
+		if id, err := uuid.Parse(part); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// keysOfSet returns the members of a UUID set.
+func keysOfSet(set map[uuid.UUID]bool) []uuid.UUID {
+	keys := make([]uuid.UUID, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// BackfillCategoryPaths fills Path and Depth for categories created before the category tree existed
+// (or with a missing path), walking down from root categories level by level. Existing flat categories
+// become root categories and can then be arranged with the move endpoints.
+func BackfillCategoryPaths(db *gorm.DB) error {
+	result := db.Exec(`UPDATE marker_categories SET path = '/' || id || '/', depth = 0
+		WHERE path = '' AND parent_id IS NULL`)
+	if result.Error != nil {
+		return result.Error
+	}
+	filled := result.RowsAffected
+	for level := 0; level < models.MaxCategoryDepth; level++ {
+		result = db.Exec(`UPDATE marker_categories child SET path = parent.path || child.id || '/', depth = parent.depth + 1
+			FROM marker_categories parent
+			WHERE child.parent_id = parent.id AND child.path = '' AND parent.path <> ''`)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			break
+		}
+		filled += result.RowsAffected
+	}
+	if filled > 0 {
+		log.Printf("Backfilled category paths for %d categories", filled)
+	}
+	return nil
 }
@@ -1,10 +1,12 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
 	"time" // Import time untuk UpdateMarker
 
 	"ulyngo/models"
+	"ulyngo/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,13 +15,14 @@ import (
 
 // MarkerController struct akan menampung dependensi database
 type MarkerController struct {
-	DB *gorm.DB
+	DB    *gorm.DB
+	Views *MarkerViewBuffer // Penampung view_count; nil berarti view ditulis langsung
 }
 
 // NewMarkerController adalah konstruktor untuk MarkerController.
-// Menerima instance GORM DB untuk dependency injection.
-func NewMarkerController(db *gorm.DB) *MarkerController {
-	return &MarkerController{DB: db}
+// Menerima instance GORM DB dan penampung view untuk dependency injection.
+func NewMarkerController(db *gorm.DB, views *MarkerViewBuffer) *MarkerController {
+	return &MarkerController{DB: db, Views: views}
 }
 
 // DirectionsRequest adalah struktur untuk data permintaan rute.
@@ -29,29 +32,76 @@ type DirectionsRequest struct {
 }
 
 // GetMarkers adalah metode dari MarkerController yang mengambil semua marker dari database.
+// Hanya marker yang sudah disetujui (approved) yang ditampilkan ke publik.
+// Query ?happening_now=true membatasi hasil ke marker yang sedang memiliki event berlangsung,
+// ?category_id= membatasi ke kategori tersebut beserta seluruh subkategorinya, dan ?tag= (ID, nama,
+// atau alias tag) membatasi ke marker yang memiliki tag tersebut.
 func (tc *MarkerController) GetMarkers(c *gin.Context) {
 	var markers []models.Marker
 	// Menggunakan dependensi DB yang di-inject untuk mengambil markers
-	if err := tc.DB.Find(&markers).Error; err != nil {
+	query := tc.DB.Where("status = ?", models.MarkerStatusApproved)
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		categoryUUID, err := uuid.Parse(categoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+			return
+		}
+		query = query.Where("category_id IN (?)", categorySubtree(tc.DB, categoryUUID))
+	}
+	if tag := c.Query("tag"); tag != "" {
+		tagID, err := resolveTagRef(tc.DB, tag)
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve tag: " + err.Error()})
+			return
+		}
+		query = query.Where("id IN (?)", tc.DB.Model(&models.MarkerHasTag{}).Select("marker_id").Where("tag_id = ?", tagID))
+	}
+	if c.Query("happening_now") == "true" {
+		ids, err := markersHappeningNow(tc.DB, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events: " + err.Error()})
+			return
+		}
+		query = query.Where("id IN ?", ids)
+	}
+	if err := query.Find(&markers).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch markers: " + err.Error()})
 		return
 	}
+	chain := requestLocaleChain(c)
+	if err := localizeMarkers(tc.DB, chain, markers); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+	if err := attachCategoryBreadcrumbs(tc.DB, chain, markers); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build category breadcrumbs: " + err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, markers)
 }
 
 // AddMarkerInput adalah struktur untuk data yang diterima saat menambah marker baru.
 type AddMarkerInput struct {
-	Name          string    `json:"name" binding:"required"`
-	Description   string    `json:"description" binding:"required"`
-	Latitude      float64   `json:"latitude" binding:"required"`
-	Longitude     float64   `json:"longitude" binding:"required"`
-	CategoryID    uuid.UUID `json:"category_id"`      // Tambahkan CategoryID
-	AddedByUserId string    `json:"added_by_user_id"` // Ini akan diisi otomatis dari token JWT
+	Name          string      `json:"name" binding:"required"`
+	Description   string      `json:"description" binding:"required"`
+	Latitude      float64     `json:"latitude" binding:"required"`
+	Longitude     float64     `json:"longitude" binding:"required"`
+	City          *string     `json:"city"`             // Nama kota/kabupaten, opsional
+	CategoryID    uuid.UUID   `json:"category_id"`      // Tambahkan CategoryID
+	TagIDs        []uuid.UUID `json:"tag_ids"`          // ID tag yang dikaitkan dengan marker, opsional
+	Tags          []string    `json:"tags"`             // Nama atau alias tag, opsional, digabung dengan TagIDs
+	AddedByUserId string      `json:"added_by_user_id"` // Ini akan diisi otomatis dari token JWT
 
 }
 
 // AddMarker adalah metode dari MarkerController yang menambahkan marker baru ke database.
 // Membutuhkan token JWT dan akan menyimpan DitambahkanOlehUserId.
+// Marker dari admin/moderator langsung disetujui, sedangkan kiriman pengguna biasa
+// masuk ke antrean moderasi dengan status pending.
 func (tc *MarkerController) AddMarker(c *gin.Context) {
 
 	var input AddMarkerInput
@@ -75,39 +125,106 @@ func (tc *MarkerController) AddMarker(c *gin.Context) {
 		return
 	}
 
+	status := models.MarkerStatusPending
+	if isModerator(c) {
+		status = models.MarkerStatusApproved
+	}
+
 	// c.JSON(http.StatusOK, gin.H{"message": input}) // Hapus ini, karena akan mengirim respons ganda
 	marker := models.Marker{
 		Name:          input.Name,
 		Description:   &input.Description,
 		Latitude:      input.Latitude,
 		Longitude:     input.Longitude,
+		City:          input.City,
 		AddedByUserID: addedByUserUUID,  // Mengisi DitambahkanOlehUserId
 		CategoryID:    input.CategoryID, // Mengisi CategoryID
-		CreatedAt:     time.Now(),       // Set waktu pembuatan
-		UpdatedAt:     time.Now(),       // Set waktu pembaruan
+		Status:        status,
+		CreatedAt:     time.Now(), // Set waktu pembuatan
+		UpdatedAt:     time.Now(), // Set waktu pembaruan
+	}
+	tagIDs, err := resolveMarkerTags(tc.DB, input.TagIDs, input.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	// Menyimpan marker ke database
-	if err := tc.DB.Create(&marker).Error; err != nil {
+
+	// Menyimpan marker ke database beserta tag, revisi pertama, dan log aktivitas pengirimnya
+	err = tc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&marker).Error; err != nil {
+			return err
+		}
+		if err := setMarkerTags(tx, marker.ID, tagIDs); err != nil {
+			return err
+		}
+		after := snapshotFromMarker(&marker, tagIDs)
+		if _, err := recordMarkerRevision(tx, marker.ID, models.RevisionActionCreate, addedByUserUUID, nil, after, nil); err != nil {
+			return err
+		}
+		if status == models.MarkerStatusPending {
+			return utils.LogActivity(tx, addedByUserUUID, "submit_marker", &marker.ID, gin.H{"marker_name": marker.Name})
+		}
+		return nil
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add marker: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Marker added successfully", "marker": marker})
+	response := gin.H{"message": "Marker added successfully", "marker": marker}
+	if status == models.MarkerStatusPending {
+		response["message"] = "Marker submitted and awaiting moderation"
+	}
+
+	// Deteksi duplikat tidak memblokir penyimpanan; hasilnya dikirim sebagai peringatan
+	duplicates, err := findDuplicateCandidates(tc.DB, marker.Name, marker.Latitude, marker.Longitude, marker.ID)
+	if err != nil {
+		log.Printf("Duplicate detection failed for marker %s: %v", marker.ID, err)
+	} else if len(duplicates) > 0 {
+		response["duplicate_warnings"] = duplicates
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetMySubmissions mengambil semua marker yang dikirim oleh pengguna saat ini, apa pun statusnya,
+// sehingga pengguna bisa melihat kiriman yang masih pending atau alasan penolakannya.
+func (tc *MarkerController) GetMySubmissions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	query := tc.DB.Where("added_by_user_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var markers []models.Marker
+	if err := query.Order("created_at DESC").Find(&markers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch submissions: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, markers)
 }
 
 // UpdateMarkerInput adalah struktur untuk data yang diterima saat memperbarui marker.
 type UpdateMarkerInput struct {
-	Name          *string    `json:"name"` // Gunakan pointer agar bisa null (opsional)
-	Description   *string    `json:"description"`
-	Latitude      *float64   `json:"latitude"`
-	Longitude     *float64   `json:"longitude"`
-	CategoryID    *uuid.UUID `json:"category_id"`      // Tambahkan CategoryID
-	AddedByUserID *string    `json:"added_by_user_id"` // Ini tidak perlu di-update, hanya untuk referensi
-	UpdatedAt     *time.Time `json:"updated_at"`       // Ini tidak perlu di-update, hanya untuk referensi
+	Name          *string      `json:"name"` // Gunakan pointer agar bisa null (opsional)
+	Description   *string      `json:"description"`
+	Latitude      *float64     `json:"latitude"`
+	Longitude     *float64     `json:"longitude"`
+	City          *string      `json:"city"`
+	CategoryID    *uuid.UUID   `json:"category_id"`      // Tambahkan CategoryID
+	TagIDs        *[]uuid.UUID `json:"tag_ids"`          // Jika diisi, menggantikan seluruh tag marker
+	Tags          *[]string    `json:"tags"`             // Nama atau alias tag; jika diisi, ikut menggantikan seluruh tag marker
+	AddedByUserID *string      `json:"added_by_user_id"` // Ini tidak perlu di-update, hanya untuk referensi
+	UpdatedAt     *time.Time   `json:"updated_at"`       // Ini tidak perlu di-update, hanya untuk referensi
 }
 
 // UpdateMarker adalah metode dari MarkerController yang memperbarui marker yang sudah ada.
 // Membutuhkan token JWT dan hanya bisa memperbarui marker yang dimiliki pengguna.
+// Setiap perubahan dicatat sebagai revisi sehingga dapat di-revert.
 func (tc *MarkerController) UpdateMarker(c *gin.Context) {
 	markerID := c.Param("id") // Dapatkan ID marker dari parameter URL
 
@@ -123,6 +240,11 @@ func (tc *MarkerController) UpdateMarker(c *gin.Context) {
 		return
 	}
 	ownerUserID := userID.(string)
+	authorID, err := uuid.Parse(ownerUserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
 
 	var marker models.Marker
 	// Cari marker berdasarkan ID dan pastikan DitambahkanOlehUserId cocok (kepemilikan)
@@ -148,10 +270,60 @@ func (tc *MarkerController) UpdateMarker(c *gin.Context) {
 	if input.Longitude != nil {
 		marker.Longitude = *input.Longitude
 	}
+	if input.City != nil {
+		marker.City = input.City
+	}
+	if input.CategoryID != nil {
+		marker.CategoryID = *input.CategoryID
+	}
+	var tagIDs []uuid.UUID
+	replaceTags := input.TagIDs != nil || input.Tags != nil
+	if replaceTags {
+		var ids []uuid.UUID
+		var names []string
+		if input.TagIDs != nil {
+			ids = *input.TagIDs
+		}
+		if input.Tags != nil {
+			names = *input.Tags
+		}
+		if tagIDs, err = resolveMarkerTags(tc.DB, ids, names); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	marker.UpdatedAt = time.Now() // Perbarui timestamp UpdatedAt
 
-	if err := tc.DB.Save(&marker).Error; err != nil {
+	err = tc.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci marker sebelum snapshot agar perubahan bersamaan diproses berurutan
+		if err := lockMarker(tx, marker.ID); err != nil {
+			return err
+		}
+		before, err := loadMarkerSnapshot(tx, marker.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(&marker).Error; err != nil {
+			return err
+		}
+		if replaceTags {
+			if err := setMarkerTags(tx, marker.ID, tagIDs); err != nil {
+				return err
+			}
+		}
+		after, err := loadMarkerSnapshot(tx, marker.ID)
+		if err != nil {
+			return err
+		}
+		// Update tanpa perubahan field tidak perlu menambah revisi
+		if len(diffSnapshots(before, after)) == 0 {
+			return nil
+		}
+		_, err = recordMarkerRevision(tx, marker.ID, models.RevisionActionUpdate, authorID, before, after, nil)
+		return err
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update marker: " + err.Error()})
 		return
 	}
@@ -174,11 +346,15 @@ func (tc *MarkerController) DeleteMarker(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
 		return
 	}
+	authorID, err := uuid.Parse(ownerUserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
 
 	var marker models.Marker
 	// Jika role admin, izinkan hapus marker apapun; jika bukan, hanya marker miliknya
 	role, _ := c.Get("role")
-	var err error
 	if role == "admin" {
 		err = tc.DB.Where("id = ?", markerID).First(&marker).Error
 	} else {
@@ -193,7 +369,26 @@ func (tc *MarkerController) DeleteMarker(c *gin.Context) {
 		return
 	}
 
-	if err := tc.DB.Delete(&marker).Error; err != nil {
+	// Kondisi terakhir marker disimpan sebagai revisi agar penghapusan dapat di-revert
+	err = tc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockMarker(tx, marker.ID); err != nil {
+			return err
+		}
+		before, err := loadMarkerSnapshot(tx, marker.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&marker).Error; err != nil {
+			return err
+		}
+		// Tautan tag diarsipkan agar bisa dipulihkan dari trash
+		if err := trashTagLinks(tx, "marker_id", marker.ID); err != nil {
+			return err
+		}
+		_, err = recordMarkerRevision(tx, marker.ID, models.RevisionActionDelete, authorID, before, before, nil)
+		return err
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete marker: " + err.Error()})
 		return
 	}
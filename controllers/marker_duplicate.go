@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"ulyngo/models"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Ambang batas default untuk deteksi marker duplikat.
+const (
+	duplicateRadiusMeters      = 150.0 // Jarak maksimum dua marker dianggap lokasi yang sama
+	duplicateMinNameSimilarity = 0.6   // Kemiripan nama minimum (0..1)
+)
+
+// DuplicateCandidate adalah marker yang kemungkinan besar merupakan tempat yang sama.
+type DuplicateCandidate struct {
+	Marker         models.Marker `json:"marker"`
+	DistanceMeters float64       `json:"distance_meters"`
+	NameSimilarity float64       `json:"name_similarity"`
+	Score          float64       `json:"score"` // Gabungan kemiripan nama dan kedekatan, 0..1
+}
+
+// DuplicatePair adalah pasangan marker yang terdeteksi sebagai duplikat pada laporan admin.
+type DuplicatePair struct {
+	MarkerA        models.Marker `json:"marker_a"`
+	MarkerB        models.Marker `json:"marker_b"`
+	DistanceMeters float64       `json:"distance_meters"`
+	NameSimilarity float64       `json:"name_similarity"`
+	Score          float64       `json:"score"`
+}
+
+// duplicateScore menggabungkan kemiripan nama (bobot 70%) dan kedekatan lokasi (bobot 30%).
+func duplicateScore(similarity, distance, radius float64) float64 {
+	proximity := 1 - distance/radius
+	if proximity < 0 {
+		proximity = 0
+	}
+	return similarity*0.7 + proximity*0.3
+}
+
+// findDuplicateCandidates mencari marker aktif (pending atau approved) di sekitar koordinat
+// yang namanya mirip. excludeID dipakai agar marker tidak mendeteksi dirinya sendiri.
+func findDuplicateCandidates(db *gorm.DB, name string, lat, lng float64, excludeID uuid.UUID) ([]DuplicateCandidate, error) {
+	minLat, maxLat, minLng, maxLng := utils.BoundingBox(lat, lng, duplicateRadiusMeters)
+
+	var nearby []models.Marker
+	err := db.Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?", minLat, maxLat, minLng, maxLng).
+		Where("status IN ?", []string{models.MarkerStatusPending, models.MarkerStatusApproved}).
+		Where("id <> ?", excludeID).
+		Find(&nearby).Error
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []DuplicateCandidate{}
+	for _, m := range nearby {
+		distance := utils.HaversineMeters(lat, lng, m.Latitude, m.Longitude)
+		if distance > duplicateRadiusMeters {
+			continue
+		}
+		similarity := utils.NameSimilarity(name, m.Name)
+		if similarity < duplicateMinNameSimilarity {
+			continue
+		}
+		candidates = append(candidates, DuplicateCandidate{
+			Marker:         m,
+			DistanceMeters: distance,
+			NameSimilarity: similarity,
+			Score:          duplicateScore(similarity, distance, duplicateRadiusMeters),
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	return candidates, nil
+}
+
+// GetDuplicateReport menghasilkan laporan pasangan marker yang kemungkinan duplikat. (Admin Protected)
+// Query opsional: ?radius= (meter, default 150) dan ?min_similarity= (0..1, default 0.6).
+func (tc *MarkerController) GetDuplicateReport(c *gin.Context) {
+	radius := duplicateRadiusMeters
+	if v := c.Query("radius"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 || parsed > 5000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "radius must be between 0 and 5000 meters"})
+			return
+		}
+		radius = parsed
+	}
+	minSimilarity := duplicateMinNameSimilarity
+	if v := c.Query("min_similarity"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min_similarity must be between 0 and 1"})
+			return
+		}
+		minSimilarity = parsed
+	}
+
+	var markers []models.Marker
+	if err := tc.DB.Where("status IN ?", []string{models.MarkerStatusPending, models.MarkerStatusApproved}).
+		Order("latitude ASC").Find(&markers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch markers: " + err.Error()})
+		return
+	}
+
+	// Marker sudah terurut berdasarkan lintang, sehingga cukup membandingkan marker
+	// dalam jendela lintang selebar radius (sweep line) alih-alih semua pasangan.
+	latWindow := radius / utils.EarthRadiusMeters * 180 / math.Pi
+	pairs := []DuplicatePair{}
+	for i := range markers {
+		for j := i + 1; j < len(markers) && markers[j].Latitude-markers[i].Latitude <= latWindow; j++ {
+			distance := utils.HaversineMeters(markers[i].Latitude, markers[i].Longitude, markers[j].Latitude, markers[j].Longitude)
+			if distance > radius {
+				continue
+			}
+			similarity := utils.NameSimilarity(markers[i].Name, markers[j].Name)
+			if similarity < minSimilarity {
+				continue
+			}
+			pairs = append(pairs, DuplicatePair{
+				MarkerA:        markers[i],
+				MarkerB:        markers[j],
+				DistanceMeters: distance,
+				NameSimilarity: similarity,
+				Score:          duplicateScore(similarity, distance, radius),
+			})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Score > pairs[j].Score })
+
+	c.JSON(http.StatusOK, gin.H{"total": len(pairs), "pairs": pairs})
+}
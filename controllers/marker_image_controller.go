@@ -0,0 +1,378 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/jpeg"
+	_ "image/png" // Registrasi decoder PNG untuk image.Decode
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"ulyngo/blobstore"
+	"ulyngo/imaging"
+	"ulyngo/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMaxImageUploadBytes = 10 << 20 // 10 MB
+	thumbnailMaxSize           = 320      // Sisi terpanjang thumbnail dalam piksel
+	thumbnailJPEGQuality       = 80
+)
+
+// allowedImageTypes memetakan MIME type yang diizinkan ke ekstensi file yang disimpan.
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// MarkerImageController menangani upload dan pengelolaan gambar marker.
+type MarkerImageController struct {
+	DB             *gorm.DB
+	Store          blobstore.BlobStore
+	Cache          *imaging.DiskCache // Cache varian gambar hasil resize, boleh nil
+	MaxUploadBytes int64
+}
+
+// NewMarkerImageController adalah konstruktor untuk MarkerImageController.
+// Batas ukuran upload dibaca dari MAX_IMAGE_UPLOAD_BYTES (default 10 MB).
+func NewMarkerImageController(db *gorm.DB, store blobstore.BlobStore, cache *imaging.DiskCache) *MarkerImageController {
+	maxBytes := int64(defaultMaxImageUploadBytes)
+	if v, err := strconv.ParseInt(os.Getenv("MAX_IMAGE_UPLOAD_BYTES"), 10, 64); err == nil && v > 0 {
+		maxBytes = v
+	}
+	return &MarkerImageController{DB: db, Store: store, Cache: cache, MaxUploadBytes: maxBytes}
+}
+
+// ReorderImagesInput adalah struktur input untuk mengurutkan ulang gambar marker.
+type ReorderImagesInput struct {
+	ImageIDs []uuid.UUID `json:"image_ids" binding:"required"`
+}
+
+// loadEditableMarker mengambil marker dan memastikan pengguna saat ini adalah pengirimnya atau moderator.
+// Menulis respons error sendiri dan mengembalikan false jika gagal.
+func (ic *MarkerImageController) loadEditableMarker(c *gin.Context) (*models.Marker, uuid.UUID, bool) {
+	markerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return nil, uuid.Nil, false
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, uuid.Nil, false
+	}
+
+	var marker models.Marker
+	if err := ic.DB.First(&marker, "id = ?", markerID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Marker not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find marker: " + err.Error()})
+		}
+		return nil, uuid.Nil, false
+	}
+	if !isModerator(c) && marker.AddedByUserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the marker owner or a moderator can manage its images"})
+		return nil, uuid.Nil, false
+	}
+	return &marker, userID, true
+}
+
+// UploadImage menerima upload gambar multipart (field "image") untuk sebuah marker. (Protected)
+// Field opsional: "description" dan "is_cover". Gambar pertama marker otomatis menjadi cover.
+// Data lokasi GPS pada EXIF dibuang sebelum disimpan, dan thumbnail JPEG dibuat otomatis.
+func (ic *MarkerImageController) UploadImage(c *gin.Context) {
+	marker, userID, ok := ic.loadEditableMarker(c)
+	if !ok {
+		return
+	}
+
+	// Batasi ukuran body; sedikit ruang tambahan untuk header multipart
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, ic.MaxUploadBytes+1<<20)
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Image exceeds the maximum upload size"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image file is required in field 'image': " + err.Error()})
+		return
+	}
+	if fileHeader.Size > ic.MaxUploadBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Image exceeds the maximum upload size"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file: " + err.Error()})
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, ic.MaxUploadBytes+1))
+	file.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file: " + err.Error()})
+		return
+	}
+	if int64(len(data)) > ic.MaxUploadBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Image exceeds the maximum upload size"})
+		return
+	}
+
+	// MIME type ditentukan dari isi file, bukan dari header yang dikirim klien
+	contentType := http.DetectContentType(data)
+	ext, allowed := allowedImageTypes[contentType]
+	if !allowed {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Unsupported image type " + contentType + ". Use JPEG or PNG"})
+		return
+	}
+
+	orientation := imaging.Orientation(data)
+	data = imaging.StripGPS(data)
+	// Dimensi diperiksa sebelum decode agar decompression bomb tidak menghabiskan memori
+	decoded, err := imaging.Decode(data)
+	if errors.Is(err, imaging.ErrTooManyPixels) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image file: " + err.Error()})
+		return
+	}
+	upright := imaging.ApplyOrientation(decoded, orientation)
+
+	var thumbnail bytes.Buffer
+	if err := jpeg.Encode(&thumbnail, imaging.Thumbnail(upright, thumbnailMaxSize), &jpeg.Options{Quality: thumbnailJPEGQuality}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate thumbnail: " + err.Error()})
+		return
+	}
+
+	imageID := uuid.New()
+	storageKey := "markers/" + marker.ID.String() + "/" + imageID.String() + ext
+	thumbnailKey := "markers/" + marker.ID.String() + "/" + imageID.String() + "_thumb.jpg"
+	ctx := c.Request.Context()
+	if err := ic.Store.Put(ctx, storageKey, bytes.NewReader(data), contentType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store image: " + err.Error()})
+		return
+	}
+	if err := ic.Store.Put(ctx, thumbnailKey, bytes.NewReader(thumbnail.Bytes()), "image/jpeg"); err != nil {
+		deleteBlobs(ic.Store, storageKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store thumbnail: " + err.Error()})
+		return
+	}
+
+	var description *string
+	if d := c.PostForm("description"); d != "" {
+		description = &d
+	}
+	wantCover, _ := strconv.ParseBool(c.PostForm("is_cover"))
+	thumbnailURL := ic.Store.URL(thumbnailKey)
+	bounds := upright.Bounds()
+	markerImage := models.MarkerImage{
+		ID:               imageID,
+		MarkerID:         marker.ID,
+		ImageURL:         ic.Store.URL(storageKey),
+		Description:      description,
+		StorageKey:       storageKey,
+		ThumbnailKey:     thumbnailKey,
+		ThumbnailURL:     &thumbnailURL,
+		ContentType:      contentType,
+		SizeBytes:        int64(len(data)),
+		Width:            bounds.Dx(),
+		Height:           bounds.Dy(),
+		UploadedByUserID: &userID,
+		UploadedAt:       time.Now(),
+	}
+
+	err = ic.DB.Transaction(func(tx *gorm.DB) error {
+		var stats struct {
+			Count    int64
+			MaxOrder int
+		}
+		if err := tx.Model(&models.MarkerImage{}).Where("marker_id = ?", marker.ID).
+			Select("COUNT(*) AS count, COALESCE(MAX(sort_order), -1) AS max_order").Scan(&stats).Error; err != nil {
+			return err
+		}
+		markerImage.SortOrder = stats.MaxOrder + 1
+		markerImage.IsCover = wantCover || stats.Count == 0
+		if markerImage.IsCover {
+			if err := tx.Model(&models.MarkerImage{}).Where("marker_id = ? AND is_cover", marker.ID).Update("is_cover", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&markerImage).Error
+	})
+	if err != nil {
+		// Blob yang sudah terunggah dibersihkan agar tidak menjadi file yatim
+		deleteBlobs(ic.Store, storageKey, thumbnailKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, markerImage)
+}
+
+// DeleteImage menghapus (soft delete) gambar marker. (Protected)
+// Jika gambar yang dihapus adalah cover, gambar berikutnya menurut urutan menjadi cover.
+// File di BlobStore baru dihapus saat purge trash.
+func (ic *MarkerImageController) DeleteImage(c *gin.Context) {
+	marker, _, ok := ic.loadEditableMarker(c)
+	if !ok {
+		return
+	}
+	markerImage, ok := ic.findMarkerImage(c, marker.ID)
+	if !ok {
+		return
+	}
+
+	err := ic.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(markerImage).Error; err != nil {
+			return err
+		}
+		return ensureCoverImage(tx, marker.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete image: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Image deleted successfully"})
+}
+
+// ReorderImages menyetel ulang urutan gambar marker sesuai daftar image_ids. (Protected)
+// Daftar harus berisi tepat semua gambar aktif milik marker.
+func (ic *MarkerImageController) ReorderImages(c *gin.Context) {
+	marker, _, ok := ic.loadEditableMarker(c)
+	if !ok {
+		return
+	}
+	var input ReorderImagesInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existingIDs []uuid.UUID
+	if err := ic.DB.Model(&models.MarkerImage{}).Where("marker_id = ?", marker.ID).Pluck("id", &existingIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch images: " + err.Error()})
+		return
+	}
+	existing := make(map[uuid.UUID]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		existing[id] = true
+	}
+	seen := make(map[uuid.UUID]bool, len(input.ImageIDs))
+	for _, id := range input.ImageIDs {
+		if !existing[id] || seen[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "image_ids must list each image of the marker exactly once"})
+			return
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(existing) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image_ids must list each image of the marker exactly once"})
+		return
+	}
+
+	var images []models.MarkerImage
+	err := ic.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range input.ImageIDs {
+			if err := tx.Model(&models.MarkerImage{}).Where("id = ?", id).Update("sort_order", i).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("marker_id = ?", marker.ID).Order("sort_order").Find(&images).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder images: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, images)
+}
+
+// SetCoverImage menjadikan gambar tertentu sebagai cover marker. (Protected)
+func (ic *MarkerImageController) SetCoverImage(c *gin.Context) {
+	marker, _, ok := ic.loadEditableMarker(c)
+	if !ok {
+		return
+	}
+	markerImage, ok := ic.findMarkerImage(c, marker.ID)
+	if !ok {
+		return
+	}
+
+	err := ic.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.MarkerImage{}).Where("marker_id = ? AND is_cover", marker.ID).Update("is_cover", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(markerImage).Update("is_cover", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set cover image: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, markerImage)
+}
+
+// findMarkerImage mengambil gambar :imageID milik marker. Menulis respons error sendiri jika gagal.
+func (ic *MarkerImageController) findMarkerImage(c *gin.Context, markerID uuid.UUID) (*models.MarkerImage, bool) {
+	imageID, err := uuid.Parse(c.Param("imageID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID format"})
+		return nil, false
+	}
+	var markerImage models.MarkerImage
+	if err := ic.DB.Where("marker_id = ?", markerID).First(&markerImage, "id = ?", imageID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find image: " + err.Error()})
+		}
+		return nil, false
+	}
+	return &markerImage, true
+}
+
+// deleteBlobs menghapus blob secara best-effort dari store, kegagalan hanya dicatat ke log; key kosong (gambar lama berupa URL) dilewati.
+func deleteBlobs(store blobstore.BlobStore, keys ...string) {
+	if store == nil {
+		return
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := store.Delete(context.Background(), key); err != nil {
+			log.Printf("Failed to delete blob %s: %v", key, err)
+		}
+	}
+}
+
+// ensureCoverImage memastikan marker yang memiliki gambar aktif punya tepat satu cover,
+// dengan mempromosikan gambar pertama menurut urutan jika belum ada.
+func ensureCoverImage(tx *gorm.DB, markerID uuid.UUID) error {
+	var covers int64
+	if err := tx.Model(&models.MarkerImage{}).Where("marker_id = ? AND is_cover", markerID).Count(&covers).Error; err != nil {
+		return err
+	}
+	if covers > 0 {
+		return nil
+	}
+	var next models.MarkerImage
+	err := tx.Where("marker_id = ?", markerID).Order("sort_order, uploaded_at").First(&next).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Model(&next).Update("is_cover", true).Error
+}
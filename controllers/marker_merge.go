@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"ulyngo/models"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxRedirectHops membatasi penelusuran rantai redirect (A -> B -> C) agar tidak berputar tanpa akhir.
+const maxRedirectHops = 10
+
+// GetMarkerByID mengambil satu marker beserta kategori, tag, dan gambarnya. (Public)
+// Jika ID merujuk ke marker yang sudah digabungkan, respons berupa 301 ke marker yang bertahan.
+func (tc *MarkerController) GetMarkerByID(c *gin.Context) {
+	markerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return
+	}
+
+	var marker models.Marker
+	err = tc.DB.Preload("Category").Preload("Tags").
+		Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order, uploaded_at") }).
+		Where("status = ?", models.MarkerStatusApproved).First(&marker, "id = ?", markerID).Error
+	if err == gorm.ErrRecordNotFound {
+		targetID, found, redirectErr := resolveMarkerRedirect(tc.DB, markerID)
+		if redirectErr != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve marker redirect: " + redirectErr.Error()})
+			return
+		}
+		if found {
+			c.Header("Location", "/api/markers/"+targetID.String())
+			c.JSON(http.StatusMovedPermanently, gin.H{"message": "Marker has been merged", "redirect_to": targetID})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Marker not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch marker: " + err.Error()})
+		return
+	}
+
+	// View dicatat untuk peringkat trending; kegagalan pencatatan tidak menggagalkan permintaan
+	if err := recordMarkerView(tc.DB, tc.Views, c, &marker); err != nil {
+		log.Printf("Failed to record view for marker %s: %v", marker.ID, err)
+	}
+	chain := requestLocaleChain(c)
+	if err := applyTranslations(tc.DB, chain, markerTranslationTargets(&marker)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+	markers := []models.Marker{marker}
+	if err := attachCategoryBreadcrumbs(tc.DB, chain, markers); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build category breadcrumbs: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, markers[0])
+}
+
+// recordMarkerView menambah ViewCount marker lewat penampung view dan, jika pengguna login, mencatat
+// aktivitas view_marker untuk peringkat trending.
+func recordMarkerView(db *gorm.DB, views *MarkerViewBuffer, c *gin.Context, marker *models.Marker) error {
+	if views != nil {
+		views.Add(marker.ID)
+	} else if err := db.Model(&models.Marker{}).Where("id = ?", marker.ID).
+		UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error; err != nil {
+		return err
+	}
+	marker.ViewCount++
+	rawUserID, _ := c.Get("userID")
+	userIDStr, _ := rawUserID.(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil // Pengunjung anonim hanya menambah ViewCount
+	}
+	return utils.LogActivity(db, userID, "view_marker", &marker.ID, gin.H{"marker_name": marker.Name})
+}
+
+// resolveMarkerRedirect menelusuri tabel marker_redirects hingga menemukan marker terakhir yang bertahan.
+func resolveMarkerRedirect(db *gorm.DB, markerID uuid.UUID) (uuid.UUID, bool, error) {
+	current := markerID
+	found := false
+	for i := 0; i < maxRedirectHops; i++ {
+		var redirect models.MarkerRedirect
+		err := db.First(&redirect, "from_marker_id = ?", current).Error
+		if err == gorm.ErrRecordNotFound {
+			break
+		}
+		if err != nil {
+			return uuid.Nil, false, err
+		}
+		current = redirect.ToMarkerID
+		found = true
+	}
+	return current, found, nil
+}
+
+// MergeMarkersInput adalah struktur untuk menggabungkan marker duplikat ke marker yang bertahan.
+type MergeMarkersInput struct {
+	DuplicateIDs []uuid.UUID `json:"duplicate_ids" binding:"required,min=1"`
+}
+
+// MergeMarkers menggabungkan marker duplikat ke marker :id. (Admin Protected)
+// Tag, gambar, ulasan, dan jumlah tampilan dipindahkan ke marker yang bertahan, marker duplikat
+// di-soft delete, dan redirect dibuat dari ID lama ke marker yang bertahan.
+func (tc *MarkerController) MergeMarkers(c *gin.Context) {
+	survivorID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return
+	}
+
+	var input MergeMarkersInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// ID yang diulang cukup diproses sekali
+	seen := make(map[uuid.UUID]bool, len(input.DuplicateIDs))
+	duplicateIDs := make([]uuid.UUID, 0, len(input.DuplicateIDs))
+	for _, id := range input.DuplicateIDs {
+		if id == survivorID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A marker cannot be merged into itself"})
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			duplicateIDs = append(duplicateIDs, id)
+		}
+	}
+	input.DuplicateIDs = duplicateIDs
+
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var survivor models.Marker
+	if err := tc.DB.First(&survivor, "id = ?", survivorID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Surviving marker not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find marker: " + err.Error()})
+		}
+		return
+	}
+
+	var duplicates []models.Marker
+	if err := tc.DB.Where("id IN ?", input.DuplicateIDs).Find(&duplicates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find duplicate markers: " + err.Error()})
+		return
+	}
+	if len(duplicates) != len(input.DuplicateIDs) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "One or more duplicate markers were not found"})
+		return
+	}
+
+	err = tc.DB.Transaction(func(tx *gorm.DB) error {
+		before, err := loadMarkerSnapshot(tx, survivorID)
+		if err != nil {
+			return err
+		}
+
+		for _, dup := range duplicates {
+			if err := mergeMarkerInto(tx, &dup, survivorID, adminID); err != nil {
+				return err
+			}
+		}
+
+		if err := recomputeMarkerRating(tx, survivorID); err != nil {
+			return err
+		}
+		after, err := loadMarkerSnapshot(tx, survivorID)
+		if err != nil {
+			return err
+		}
+		if _, err := recordMarkerRevision(tx, survivorID, models.RevisionActionMerge, adminID, before, after, nil); err != nil {
+			return err
+		}
+		return tx.Preload("Tags").Preload("Images").First(&survivor, "id = ?", survivorID).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge markers: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Markers merged successfully", "marker": survivor, "merged_ids": input.DuplicateIDs})
+}
+
+// mergeMarkerInto memindahkan seluruh data terkait dari marker dup ke survivorID lalu menghapus dup.
+func mergeMarkerInto(tx *gorm.DB, dup *models.Marker, survivorID, adminID uuid.UUID) error {
+	before, err := loadMarkerSnapshot(tx, dup.ID)
+	if err != nil {
+		return err
+	}
+
+	// Tag: salin tautan yang belum dimiliki marker bertahan, lalu hapus tautan lama
+	if err := tx.Exec(`INSERT INTO marker_has_tags (marker_id, tag_id, created_at)
+		SELECT ?, tag_id, created_at FROM marker_has_tags WHERE marker_id = ?
+		ON CONFLICT DO NOTHING`, survivorID, dup.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("marker_id = ?", dup.ID).Delete(&models.MarkerHasTag{}).Error; err != nil {
+		return err
+	}
+
+	// Gambar dan ulasan dipindahkan apa adanya (termasuk yang sudah di-soft delete).
+	// Gambar duplikat ditempatkan setelah gambar survivor dan cover survivor dipertahankan.
+	if err := tx.Exec(`UPDATE marker_images SET marker_id = ?, is_cover = false,
+		sort_order = sort_order + (SELECT COALESCE(MAX(sort_order), -1) + 1 FROM marker_images WHERE marker_id = ?)
+		WHERE marker_id = ?`, survivorID, survivorID, dup.ID).Error; err != nil {
+		return err
+	}
+	if err := ensureCoverImage(tx, survivorID); err != nil {
+		return err
+	}
+	// Pengguna yang mengulas kedua marker hanya boleh memiliki satu ulasan aktif: ulasan terbaru dipertahankan
+	if err := tx.Exec(`UPDATE marker_reviews r SET deleted_at = ?
+		FROM marker_reviews o
+		WHERE r.user_id = o.user_id AND r.id <> o.id
+		AND r.deleted_at IS NULL AND o.deleted_at IS NULL
+		AND r.marker_id IN ? AND o.marker_id IN ?
+		AND (r.updated_at < o.updated_at OR (r.updated_at = o.updated_at AND r.id < o.id))`,
+		time.Now(), []uuid.UUID{dup.ID, survivorID}, []uuid.UUID{dup.ID, survivorID}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Model(&models.MarkerReview{}).Where("marker_id = ?", dup.ID).Update("marker_id", survivorID).Error; err != nil {
+		return err
+	}
+
+	// Favorit dan isi koleksi dipindahkan ke marker bertahan; yang sudah memuat marker bertahan cukup dihapus
+	if err := tx.Exec(`INSERT INTO favorites (user_id, marker_id, created_at)
+		SELECT user_id, ?, created_at FROM favorites WHERE marker_id = ?
+		ON CONFLICT DO NOTHING`, survivorID, dup.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("marker_id = ?", dup.ID).Delete(&models.Favorite{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec(`INSERT INTO collection_items (collection_id, marker_id, sort_order, note, added_at)
+		SELECT collection_id, ?, sort_order, note, added_at FROM collection_items WHERE marker_id = ?
+		ON CONFLICT DO NOTHING`, survivorID, dup.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("marker_id = ?", dup.ID).Delete(&models.CollectionItem{}).Error; err != nil {
+		return err
+	}
+	// Event ikut dipindahkan, termasuk yang sudah di-soft delete
+	if err := tx.Unscoped().Model(&models.Event{}).Where("marker_id = ?", dup.ID).Update("marker_id", survivorID).Error; err != nil {
+		return err
+	}
+
+	// Jumlah tampilan dijumlahkan
+	if err := tx.Model(&models.Marker{}).Where("id = ?", survivorID).
+		Update("view_count", gorm.Expr("view_count + ?", dup.ViewCount)).Error; err != nil {
+		return err
+	}
+
+	// Redirect: ID marker duplikat (dan redirect lama yang menuju ke sana) diarahkan ke marker bertahan
+	if err := tx.Model(&models.MarkerRedirect{}).Where("to_marker_id = ?", dup.ID).Update("to_marker_id", survivorID).Error; err != nil {
+		return err
+	}
+	if err := tx.Create(&models.MarkerRedirect{FromMarkerID: dup.ID, ToMarkerID: survivorID, MergedByUserID: adminID}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Delete(dup).Error; err != nil {
+		return err
+	}
+	_, err = recordMarkerRevision(tx, dup.ID, models.RevisionActionDelete, adminID, before, before, nil)
+	return err
+}
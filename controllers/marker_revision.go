@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"ulyngo/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// markerSnapshot adalah representasi kondisi marker yang disimpan pada setiap revisi.
+// Field di sini adalah field yang bisa dipulihkan melalui revert.
+type markerSnapshot struct {
+	Name        string      `json:"name"`
+	Description *string     `json:"description"`
+	Latitude    float64     `json:"latitude"`
+	Longitude   float64     `json:"longitude"`
+	City        *string     `json:"city"`
+	CategoryID  uuid.UUID   `json:"category_id"`
+	Status      string      `json:"status"`
+	TagIDs      []uuid.UUID `json:"tag_ids"`
+}
+
+// fieldChange adalah nilai lama dan baru untuk satu field pada diff revisi.
+type fieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// loadMarkerSnapshot membaca kondisi marker saat ini (termasuk yang sudah di-soft delete) beserta ID tag-nya.
+func loadMarkerSnapshot(tx *gorm.DB, markerID uuid.UUID) (*markerSnapshot, error) {
+	var marker models.Marker
+	if err := tx.Unscoped().First(&marker, "id = ?", markerID).Error; err != nil {
+		return nil, err
+	}
+	tagIDs, err := markerTagIDs(tx, markerID)
+	if err != nil {
+		return nil, err
+	}
+	return snapshotFromMarker(&marker, tagIDs), nil
+}
+
+// snapshotFromMarker membuat snapshot dari struct marker dan daftar ID tag-nya.
+func snapshotFromMarker(marker *models.Marker, tagIDs []uuid.UUID) *markerSnapshot {
+	sorted := append([]uuid.UUID{}, tagIDs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].String() < sorted[j].String() })
+	return &markerSnapshot{
+		Name:        marker.Name,
+		Description: marker.Description,
+		Latitude:    marker.Latitude,
+		Longitude:   marker.Longitude,
+		City:        marker.City,
+		CategoryID:  marker.CategoryID,
+		Status:      marker.Status,
+		TagIDs:      sorted,
+	}
+}
+
+// markerTagIDs mengambil ID tag yang terhubung dengan marker dari tabel marker_has_tags.
+func markerTagIDs(tx *gorm.DB, markerID uuid.UUID) ([]uuid.UUID, error) {
+	var tagIDs []uuid.UUID
+	err := tx.Model(&models.MarkerHasTag{}).Where("marker_id = ?", markerID).Pluck("tag_id", &tagIDs).Error
+	return tagIDs, err
+}
+
+// setMarkerTags mengganti seluruh tag marker dengan daftar tagIDs yang diberikan.
+func setMarkerTags(tx *gorm.DB, markerID uuid.UUID, tagIDs []uuid.UUID) error {
+	if err := tx.Where("marker_id = ?", markerID).Delete(&models.MarkerHasTag{}).Error; err != nil {
+		return err
+	}
+	seen := make(map[uuid.UUID]bool)
+	links := make([]models.MarkerHasTag, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		if seen[tagID] {
+			continue
+		}
+		seen[tagID] = true
+		links = append(links, models.MarkerHasTag{MarkerID: markerID, TagID: tagID})
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return tx.Create(&links).Error
+}
+
+// validateTagIDs memastikan semua tag yang dirujuk ada (dan belum dihapus).
+func validateTagIDs(tx *gorm.DB, tagIDs []uuid.UUID) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	var count int64
+	unique := make(map[uuid.UUID]bool)
+	for _, id := range tagIDs {
+		unique[id] = true
+	}
+	if err := tx.Model(&models.MarkerTag{}).Where("id IN ?", tagIDs).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(unique) {
+		return fmt.Errorf("one or more tags do not exist")
+	}
+	return nil
+}
+
+// diffSnapshots menghasilkan diff per field antara dua snapshot. before boleh nil (marker baru).
+func diffSnapshots(before, after *markerSnapshot) map[string]fieldChange {
+	toMap := func(s *markerSnapshot) map[string]interface{} {
+		result := map[string]interface{}{}
+		if s == nil {
+			return result
+		}
+		raw, _ := json.Marshal(s)
+		_ = json.Unmarshal(raw, &result)
+		return result
+	}
+
+	oldValues, newValues := toMap(before), toMap(after)
+	changes := make(map[string]fieldChange)
+	for field, newValue := range newValues {
+		oldValue := oldValues[field]
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes[field] = fieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	return changes
+}
+
+// recordMarkerRevision menyimpan revisi baru untuk marker di dalam transaksi tx.
+// before bernilai nil untuk aksi create; after adalah kondisi marker setelah perubahan
+// (untuk aksi delete, kondisi terakhir sebelum dihapus). Baris marker dikunci lebih dulu agar dua perubahan
+// bersamaan pada marker yang sama tidak mendapat nomor revisi yang sama.
+func recordMarkerRevision(tx *gorm.DB, markerID uuid.UUID, action string, authorID uuid.UUID, before, after *markerSnapshot, revertedTo *int) (*models.MarkerRevision, error) {
+	// Unscoped: marker yang dihapus atau dipulihkan tetap perlu dikunci
+	if err := lockMarker(tx.Unscoped(), markerID); err != nil {
+		return nil, err
+	}
+	var lastRevision int
+	if err := tx.Model(&models.MarkerRevision{}).Where("marker_id = ?", markerID).
+		Select("COALESCE(MAX(revision), 0)").Scan(&lastRevision).Error; err != nil {
+		return nil, err
+	}
+
+	snapshot, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := diffSnapshots(before, after)
+	if action == models.RevisionActionDelete {
+		changes = map[string]fieldChange{"deleted": {Old: false, New: true}}
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := models.MarkerRevision{
+		MarkerID:           markerID,
+		Revision:           lastRevision + 1,
+		Action:             action,
+		AuthorUserID:       authorID,
+		Snapshot:           snapshot,
+		Changes:            changesJSON,
+		RevertedToRevision: revertedTo,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetMarkerRevisions mengambil riwayat revisi sebuah marker, terbaru lebih dulu.
+// Revisi marker yang belum disetujui hanya bisa dilihat oleh pengirimnya atau moderator.
+func (tc *MarkerController) GetMarkerRevisions(c *gin.Context) {
+	markerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var marker models.Marker
+	if err := tc.DB.Unscoped().First(&marker, "id = ?", markerID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Marker not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find marker: " + err.Error()})
+		}
+		return
+	}
+	if !isModerator(c) && marker.AddedByUserID != userID &&
+		(marker.Status != models.MarkerStatusApproved || marker.DeletedAt.Valid) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Marker not found"})
+		return
+	}
+
+	var revisions []models.MarkerRevision
+	if err := tc.DB.Where("marker_id = ?", markerID).Order("revision DESC").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+// RevertMarkerRevision mengembalikan marker ke kondisi pada revisi tertentu. (Admin Protected)
+// Marker yang sudah dihapus akan dipulihkan. Revert dicatat sebagai revisi baru sehingga bisa dibatalkan lagi.
+func (tc *MarkerController) RevertMarkerRevision(c *gin.Context) {
+	markerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return
+	}
+	revisionNumber, err := strconv.Atoi(c.Param("rev"))
+	if err != nil || revisionNumber < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var target models.MarkerRevision
+	if err := tc.DB.Where("marker_id = ? AND revision = ?", markerID, revisionNumber).First(&target).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find revision: " + err.Error()})
+		}
+		return
+	}
+
+	var snapshot markerSnapshot
+	if err := json.Unmarshal(target.Snapshot, &snapshot); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read revision snapshot: " + err.Error()})
+		return
+	}
+
+	var marker models.Marker
+	var revision *models.MarkerRevision
+	err = tc.DB.Transaction(func(tx *gorm.DB) error {
+		// Marker yang sudah dihapus juga bisa di-revert, sehingga dikunci tanpa scope soft delete
+		if err := lockMarker(tx.Unscoped(), markerID); err != nil {
+			return err
+		}
+		before, err := loadMarkerSnapshot(tx, markerID)
+		if err != nil {
+			return err
+		}
+
+		// Tag yang sejak revisi tersebut digabung ke tag lain diganti dengan tag tujuannya, sedangkan tag
+		// yang sudah dihapus tidak dapat dihubungkan kembali
+		snapshotTagIDs, err := canonicalTagIDs(tx, snapshot.TagIDs)
+		if err != nil {
+			return err
+		}
+		var liveTagIDs []uuid.UUID
+		if len(snapshotTagIDs) > 0 {
+			if err := tx.Model(&models.MarkerTag{}).Where("id IN ?", snapshotTagIDs).Pluck("id", &liveTagIDs).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Model(&models.Marker{}).Where("id = ?", markerID).Updates(map[string]interface{}{
+			"name":        snapshot.Name,
+			"description": snapshot.Description,
+			"latitude":    snapshot.Latitude,
+			"longitude":   snapshot.Longitude,
+			"city":        snapshot.City,
+			"category_id": snapshot.CategoryID,
+			"status":      snapshot.Status,
+			"deleted_at":  nil,
+		}).Error; err != nil {
+			return err
+		}
+		if err := setMarkerTags(tx, markerID, liveTagIDs); err != nil {
+			return err
+		}
+		// Tag marker kini ditentukan oleh snapshot, sehingga arsip tautan di trash tidak lagi relevan
+		if err := tx.Where("marker_id = ?", markerID).Delete(&models.MarkerHasTagTrash{}).Error; err != nil {
+			return err
+		}
+
+		after, err := loadMarkerSnapshot(tx, markerID)
+		if err != nil {
+			return err
+		}
+		revision, err = recordMarkerRevision(tx, markerID, models.RevisionActionRevert, userID, before, after, &revisionNumber)
+		if err != nil {
+			return err
+		}
+		return tx.Preload("Tags").First(&marker, "id = ?", markerID).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Marker not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revert marker: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Marker reverted successfully", "marker": marker, "revision": revision})
+}
@@ -1,12 +1,18 @@
 package controllers
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"ulyngo/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MarkerTagController struct {
@@ -33,6 +39,9 @@ func (tc *MarkerTagController) CreateTag(c *gin.Context) {
 	tag := models.MarkerTag{
 		Name: input.Name,
 	}
+	if !tc.ensureNameNotAlias(c, tag.Name) {
+		return
+	}
 
 	if err := tc.DB.Create(&tag).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag: " + err.Error()})
@@ -41,22 +50,34 @@ func (tc *MarkerTagController) CreateTag(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "Tag created successfully", "tag": tag})
 }
 
-// GetAllTags retrieves all marker tags. (Public)
+// GetAllTags retrieves all marker tags with their usage counts and aliases. (Public)
 func (tc *MarkerTagController) GetAllTags(c *gin.Context) {
 	var tags []models.MarkerTag
-	if err := tc.DB.Find(&tags).Error; err != nil {
+	if err := tc.DB.Order("name").Find(&tags).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags: " + err.Error()})
 		return
 	}
+	if err := localizeTags(tc.DB, requestLocaleChain(c), tags); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+	if err := attachTagStats(tc.DB, tags); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag usage: " + err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, tags)
 }
 
-// GetTagByID retrieves a marker tag by its ID. (Public)
+// GetTagByID retrieves a marker tag by its ID, name or alias. IDs of tags that were merged
+// resolve to the tag they were merged into. (Public)
 func (tc *MarkerTagController) GetTagByID(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := uuid.Parse(idParam)
+	id, err := resolveTagRef(tc.DB, c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID format"})
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve tag: " + err.Error()})
+		}
 		return
 	}
 
@@ -69,7 +90,16 @@ func (tc *MarkerTagController) GetTagByID(c *gin.Context) {
 		}
 		return
 	}
-	c.JSON(http.StatusOK, tag)
+	if err := applyTranslations(tc.DB, requestLocaleChain(c), []translationTarget{tagTranslationTarget(&tag)}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+	tags := []models.MarkerTag{tag}
+	if err := attachTagStats(tc.DB, tags); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag usage: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tags[0])
 }
 
 // UpdateTagInput defines the structure for updating a marker tag.
@@ -104,6 +134,9 @@ func (tc *MarkerTagController) UpdateTag(c *gin.Context) {
 
 	if input.Name != nil {
 		tag.Name = *input.Name
+		if !tc.ensureNameNotAlias(c, tag.Name) {
+			return
+		}
 	}
 
 	if err := tc.DB.Save(&tag).Error; err != nil {
@@ -133,10 +166,498 @@ func (tc *MarkerTagController) DeleteTag(c *gin.Context) {
 		return
 	}
 
-	// Perform soft delete
-	if err := tc.DB.Delete(&tag).Error; err != nil {
+	// Perform soft delete and archive the tag's marker links so they can be restored from the trash
+	err = tc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&tag).Error; err != nil {
+			return err
+		}
+		return trashTagLinks(tx, "tag_id", tag.ID)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tag: " + err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
 }
+
+// TagCloudEntry is one tag in the tag cloud, with a display weight from 1 (least used) to 5 (most used).
+type TagCloudEntry struct {
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Count  int64     `json:"count"`
+	Weight int       `json:"weight"`
+}
+
+// GetTagCloud retrieves the most used tags, sorted by popularity. (Public)
+// Query: ?limit= (1-200, default 50), ?min_count= (default 1).
+func (tc *MarkerTagController) GetTagCloud(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+		return
+	}
+	minCount, err := strconv.Atoi(c.DefaultQuery("min_count", "1"))
+	if err != nil || minCount < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_count must be a positive integer"})
+		return
+	}
+
+	var rows []struct {
+		ID    uuid.UUID
+		Count int64
+	}
+	if err := tagUsageQuery(tc.DB).Select("marker_tags.id, COUNT(*) AS count").
+		Having("COUNT(*) >= ?", minCount).Order("count DESC, marker_tags.name").Limit(limit).Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag usage: " + err.Error()})
+		return
+	}
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var tags []models.MarkerTag
+	if err := tc.DB.Where("id IN ?", ids).Find(&tags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags: " + err.Error()})
+		return
+	}
+	if err := localizeTags(tc.DB, requestLocaleChain(c), tags); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+	names := make(map[uuid.UUID]string, len(tags))
+	for _, tag := range tags {
+		names[tag.ID] = tag.Name
+	}
+
+	// Weights use a logarithmic scale so a few very popular tags do not flatten the rest
+	entries := make([]TagCloudEntry, 0, len(rows))
+	for _, row := range rows {
+		weight := 1
+		if len(rows) > 0 && rows[0].Count > int64(minCount) {
+			ratio := math.Log(float64(row.Count)/float64(minCount)) / math.Log(float64(rows[0].Count)/float64(minCount))
+			weight = 1 + int(math.Round(ratio*4))
+		}
+		entries = append(entries, TagCloudEntry{ID: row.ID, Name: names[row.ID], Count: row.Count, Weight: weight})
+	}
+	c.JSON(http.StatusOK, gin.H{"tags": entries})
+}
+
+// TagAliasInput defines the structure for adding an alias to a tag.
+type TagAliasInput struct {
+	Alias string `json:"alias" binding:"required"`
+}
+
+// AddTagAlias adds an alias (synonym) that resolves to the tag. (Admin Protected)
+func (tc *MarkerTagController) AddTagAlias(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	tagID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID format"})
+		return
+	}
+	var input TagAliasInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	alias := normalizeTagName(input.Alias)
+	if alias == "" || len(alias) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alias must be between 1 and 100 characters"})
+		return
+	}
+
+	var tag models.MarkerTag
+	if err := tc.DB.First(&tag, "id = ?", tagID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tag: " + err.Error()})
+		}
+		return
+	}
+	// An alias may not shadow an existing tag name or another alias
+	var conflicts int64
+	if err := tc.DB.Unscoped().Model(&models.MarkerTag{}).Where("LOWER(name) = ?", alias).Count(&conflicts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check tag names: " + err.Error()})
+		return
+	}
+	if conflicts > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A tag with this name already exists; merge the tags instead"})
+		return
+	}
+
+	record := models.MarkerTagAlias{Alias: alias, TagID: tag.ID, CreatedByUserID: &adminID}
+	result := tc.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create alias: " + result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Alias is already in use"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Alias created successfully", "alias": record})
+}
+
+// DeleteTagAlias removes an alias from a tag. (Admin Protected)
+func (tc *MarkerTagController) DeleteTagAlias(c *gin.Context) {
+	tagID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID format"})
+		return
+	}
+	aliasID, err := uuid.Parse(c.Param("aliasID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid alias ID format"})
+		return
+	}
+	result := tc.DB.Where("tag_id = ? AND id = ?", tagID, aliasID).Delete(&models.MarkerTagAlias{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete alias: " + result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alias not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Alias deleted successfully"})
+}
+
+// MergeTagInput defines the tag that the source tag is merged into.
+type MergeTagInput struct {
+	TargetID uuid.UUID `json:"target_id" binding:"required"`
+}
+
+// MergeTag merges the tag :id into target_id in one transaction. (Admin Protected)
+// Marker links (active and trashed) are rewritten to the target and deduplicated, existing aliases are
+// moved over, and the source name and ID become an alias of the target before the source tag is removed.
+// Every marker whose tags change gets a revision authored by the admin.
+func (tc *MarkerTagController) MergeTag(c *gin.Context) {
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	sourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID format"})
+		return
+	}
+	var input MergeTagInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.TargetID == sourceID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A tag cannot be merged into itself"})
+		return
+	}
+
+	var target models.MarkerTag
+	var movedLinks int64
+	err = tc.DB.Transaction(func(tx *gorm.DB) error {
+		var source models.MarkerTag
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&source, "id = ?", sourceID).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&target, "id = ?", input.TargetID).Error; err != nil {
+			return err
+		}
+
+		// Snapshots of the affected markers before their links change, for the revision history
+		var markerIDs []uuid.UUID
+		if err := tx.Model(&models.MarkerHasTag{}).Where("tag_id = ?", source.ID).Pluck("marker_id", &markerIDs).Error; err != nil {
+			return err
+		}
+		before := make(map[uuid.UUID]*markerSnapshot, len(markerIDs))
+		for _, markerID := range markerIDs {
+			snapshot, err := loadMarkerSnapshot(tx, markerID)
+			if err != nil {
+				return err
+			}
+			before[markerID] = snapshot
+		}
+
+		// Marker links: copy to the target unless the marker already has it, then drop the source links
+		result := tx.Exec(`INSERT INTO marker_has_tags (marker_id, tag_id, created_at)
+			SELECT marker_id, ?, created_at FROM marker_has_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, target.ID, source.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		movedLinks = result.RowsAffected
+		if err := tx.Where("tag_id = ?", source.ID).Delete(&models.MarkerHasTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`INSERT INTO marker_has_tag_trashes (marker_id, tag_id, created_at, trashed_at)
+			SELECT marker_id, ?, created_at, trashed_at FROM marker_has_tag_trashes WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, target.ID, source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", source.ID).Delete(&models.MarkerHasTagTrash{}).Error; err != nil {
+			return err
+		}
+		for _, markerID := range markerIDs {
+			after, err := loadMarkerSnapshot(tx, markerID)
+			if err != nil {
+				return err
+			}
+			if len(diffSnapshots(before[markerID], after)) == 0 {
+				continue
+			}
+			if _, err := recordMarkerRevision(tx, markerID, models.RevisionActionUpdate, adminID, before[markerID], after, nil); err != nil {
+				return err
+			}
+		}
+
+		// Aliases of the source now point at the target, and the source itself becomes an alias
+		if err := tx.Model(&models.MarkerTagAlias{}).Where("tag_id = ?", source.ID).Update("tag_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.MarkerTagAlias{}).Where("merged_from_tag_id = ?", source.ID).Update("tag_id", target.ID).Error; err != nil {
+			return err
+		}
+		alias := models.MarkerTagAlias{Alias: normalizeTagName(source.Name), TagID: target.ID, MergedFromTagID: &source.ID, CreatedByUserID: &adminID}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "alias"}},
+			DoUpdates: clause.AssignmentColumns([]string{"tag_id", "merged_from_tag_id"}),
+		}).Create(&alias).Error; err != nil {
+			return err
+		}
+
+		// Favorite tag preferences keep working with the target ID
+		if err := replacePreferenceTag(tx, source.ID, target.ID); err != nil {
+			return err
+		}
+
+		// The source tag and its translations are removed permanently; its ID lives on as the alias above
+		if err := tx.Where("entity_type = ? AND entity_id = ?", models.TranslationEntityTag, source.ID).Delete(&models.Translation{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&source).Error
+	})
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge tags: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tags merged successfully", "tag": target, "moved_links": movedLinks})
+}
+
+// replacePreferenceTag replaces sourceID with targetID in every favorite_tag_ids preference, without
+// listing targetID twice when the user already had both tags.
+func replacePreferenceTag(tx *gorm.DB, sourceID, targetID uuid.UUID) error {
+	var preferences []models.Preference
+	if err := tx.Where("key = ? AND value LIKE ?", PreferenceFavoriteTags, "%"+sourceID.String()+"%").Find(&preferences).Error; err != nil {
+		return err
+	}
+	for _, preference := range preferences {
+		ids, err := parsePreferenceIDs(preference.Value)
+		if err != nil {
+			continue // Unparseable values are left alone; they are ignored when read as well
+		}
+		seen := map[uuid.UUID]bool{}
+		replaced := make([]uuid.UUID, 0, len(ids))
+		for _, id := range ids {
+			if id == sourceID {
+				id = targetID
+			}
+			if !seen[id] {
+				seen[id] = true
+				replaced = append(replaced, id)
+			}
+		}
+		value, err := json.Marshal(replaced)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&preference).Update("value", string(value)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureNameNotAlias writes a 409 response and returns false when name is already used as an alias.
+func (tc *MarkerTagController) ensureNameNotAlias(c *gin.Context, name string) bool {
+	var alias models.MarkerTagAlias
+	err := tc.DB.Where("alias = ?", normalizeTagName(name)).First(&alias).Error
+	if err == gorm.ErrRecordNotFound {
+		return true
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check tag aliases: " + err.Error()})
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": "Name is already an alias of another tag", "tag_id": alias.TagID})
+	return false
+}
+
+// normalizeTagName lowercases a tag name or alias and collapses whitespace, for case-insensitive matching.
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// tagUsageQuery joins tags with their links to approved markers, grouped per tag.
+func tagUsageQuery(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.MarkerTag{}).
+		Joins("JOIN marker_has_tags ON marker_has_tags.tag_id = marker_tags.id").
+		Joins("JOIN markers ON markers.id = marker_has_tags.marker_id AND markers.deleted_at IS NULL AND markers.status = ?", models.MarkerStatusApproved).
+		Group("marker_tags.id")
+}
+
+// attachTagStats fills UsageCount and Aliases on tags.
+func attachTagStats(db *gorm.DB, tags []models.MarkerTag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	var counts []struct {
+		ID    uuid.UUID
+		Count int64
+	}
+	if err := tagUsageQuery(db).Select("marker_tags.id, COUNT(*) AS count").
+		Where("marker_tags.id IN ?", ids).Scan(&counts).Error; err != nil {
+		return err
+	}
+	usage := make(map[uuid.UUID]int64, len(counts))
+	for _, count := range counts {
+		usage[count.ID] = count.Count
+	}
+	var aliases []models.MarkerTagAlias
+	if err := db.Where("tag_id IN ?", ids).Order("alias").Find(&aliases).Error; err != nil {
+		return err
+	}
+	aliasesOf := map[uuid.UUID][]string{}
+	for _, alias := range aliases {
+		aliasesOf[alias.TagID] = append(aliasesOf[alias.TagID], alias.Alias)
+	}
+	for i := range tags {
+		count := usage[tags[i].ID]
+		tags[i].UsageCount = &count
+		tags[i].Aliases = aliasesOf[tags[i].ID]
+	}
+	return nil
+}
+
+// resolveTagRef resolves a tag reference (tag ID, ID of a merged tag, name or alias) to a canonical tag ID.
+// Returns gorm.ErrRecordNotFound when nothing matches.
+func resolveTagRef(db *gorm.DB, ref string) (uuid.UUID, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		ids, err := canonicalTagIDs(db, []uuid.UUID{id})
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return ids[0], nil
+	}
+	ids, unknown, err := resolveTagNames(db, []string{ref})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if len(unknown) > 0 {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	return ids[0], nil
+}
+
+// canonicalTagIDs replaces IDs of merged tags with the ID of the tag they were merged into.
+func canonicalTagIDs(db *gorm.DB, ids []uuid.UUID) ([]uuid.UUID, error) {
+	if len(ids) == 0 {
+		return ids, nil
+	}
+	var aliases []models.MarkerTagAlias
+	if err := db.Where("merged_from_tag_id IN ?", ids).Find(&aliases).Error; err != nil {
+		return nil, err
+	}
+	mergedInto := make(map[uuid.UUID]uuid.UUID, len(aliases))
+	for _, alias := range aliases {
+		mergedInto[*alias.MergedFromTagID] = alias.TagID
+	}
+	result := make([]uuid.UUID, len(ids))
+	for i, id := range ids {
+		if target, ok := mergedInto[id]; ok {
+			id = target
+		}
+		result[i] = id
+	}
+	return result, nil
+}
+
+// resolveTagNames resolves tag names or aliases (case-insensitive) to canonical tag IDs, in input order.
+// Names that match neither a tag nor an alias are returned in unknown.
+func resolveTagNames(db *gorm.DB, names []string) ([]uuid.UUID, []string, error) {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		if n := normalizeTagName(name); n != "" {
+			normalized = append(normalized, n)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, nil, nil
+	}
+	var tags []models.MarkerTag
+	if err := db.Select("id", "name").Where("LOWER(name) IN ?", normalized).Find(&tags).Error; err != nil {
+		return nil, nil, err
+	}
+	// Aliases of a tag in the trash do not resolve until the tag is restored
+	var aliases []models.MarkerTagAlias
+	if err := db.Where("alias IN ?", normalized).
+		Where("tag_id IN (?)", db.Model(&models.MarkerTag{}).Select("id")).Find(&aliases).Error; err != nil {
+		return nil, nil, err
+	}
+	byName := make(map[string]uuid.UUID, len(tags)+len(aliases))
+	for _, alias := range aliases {
+		byName[alias.Alias] = alias.TagID
+	}
+	for _, tag := range tags {
+		byName[normalizeTagName(tag.Name)] = tag.ID
+	}
+
+	ids := make([]uuid.UUID, 0, len(normalized))
+	unknown := []string{}
+	for _, name := range normalized {
+		if id, ok := byName[name]; ok {
+			ids = append(ids, id)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	return ids, unknown, nil
+}
+
+// resolveMarkerTags combines tag IDs and tag names/aliases from marker input into a deduplicated list
+// of canonical tag IDs, and checks that all of them exist.
+func resolveMarkerTags(db *gorm.DB, ids []uuid.UUID, names []string) ([]uuid.UUID, error) {
+	canonical, err := canonicalTagIDs(db, ids)
+	if err != nil {
+		return nil, err
+	}
+	named, unknown, err := resolveTagNames(db, names)
+	if err != nil {
+		return nil, err
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown tags: %s", strings.Join(unknown, ", "))
+	}
+
+	seen := map[uuid.UUID]bool{}
+	result := []uuid.UUID{}
+	for _, id := range append(canonical, named...) {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	if err := validateTagIDs(db, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MarkerViewBuffer menampung penambahan view_count marker di memori lalu menuliskannya secara berkala
+// dalam satu UPDATE, sehingga GET /api/markers/:id tidak membuka transaksi tulis di setiap permintaan.
+// View yang belum ditulis akan hilang jika server berhenti; angka ini hanya statistik kasar.
+type MarkerViewBuffer struct {
+	DB      *gorm.DB
+	mu      sync.Mutex
+	pending map[uuid.UUID]int64
+}
+
+// NewMarkerViewBuffer adalah konstruktor untuk MarkerViewBuffer.
+func NewMarkerViewBuffer(db *gorm.DB) *MarkerViewBuffer {
+	return &MarkerViewBuffer{DB: db, pending: map[uuid.UUID]int64{}}
+}
+
+// Add mencatat satu view untuk marker.
+func (b *MarkerViewBuffer) Add(markerID uuid.UUID) {
+	b.mu.Lock()
+	b.pending[markerID]++
+	b.mu.Unlock()
+}
+
+// Start menulis view yang tertampung setiap interval di background.
+func (b *MarkerViewBuffer) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := b.Flush(); err != nil {
+				log.Printf("Failed to flush marker views: %v", err)
+			}
+		}
+	}()
+}
+
+// Flush menambahkan seluruh view yang tertampung ke view_count marker. Jika gagal, view dikembalikan
+// ke penampung agar ikut ditulis pada flush berikutnya.
+func (b *MarkerViewBuffer) Flush() error {
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = map[uuid.UUID]int64{}
+	b.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(pending))
+	args := make([]interface{}, 0, 2*len(pending))
+	for markerID, views := range pending {
+		values = append(values, "(?::uuid, ?::bigint)")
+		args = append(args, markerID, views)
+	}
+	err := b.DB.Exec(fmt.Sprintf(`UPDATE markers SET view_count = markers.view_count + v.views
+		FROM (VALUES %s) AS v(id, views) WHERE markers.id = v.id`, strings.Join(values, ", ")), args...).Error
+	if err != nil {
+		b.mu.Lock()
+		for markerID, views := range pending {
+			b.pending[markerID] += views
+		}
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ulyngo/models"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ModerationController menangani antrean moderasi untuk konten kiriman komunitas.
+type ModerationController struct {
+	DB *gorm.DB
+}
+
+// NewModerationController adalah konstruktor untuk ModerationController.
+func NewModerationController(db *gorm.DB) *ModerationController {
+	return &ModerationController{DB: db}
+}
+
+// GetMarkerQueue mengambil daftar marker kiriman komunitas yang menunggu moderasi.
+// Query ?status= dapat dipakai untuk melihat marker yang sudah disetujui/ditolak.
+func (mc *ModerationController) GetMarkerQueue(c *gin.Context) {
+	status := c.DefaultQuery("status", models.MarkerStatusPending)
+
+	var markers []models.Marker
+	if err := mc.DB.Where("status = ?", status).Order("created_at ASC").Find(&markers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch moderation queue: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, markers)
+}
+
+// ModerateMarkerInput adalah struktur untuk data keputusan moderator.
+type ModerateMarkerInput struct {
+	Reason string `json:"reason"`
+}
+
+// ApproveMarker menyetujui marker pending sehingga tampil di daftar publik.
+// AddedByUserID tetap menunjuk ke kontributor asli.
+func (mc *ModerationController) ApproveMarker(c *gin.Context) {
+	mc.moderateMarker(c, models.MarkerStatusApproved)
+}
+
+// RejectMarker menolak marker pending. Alasan penolakan wajib diisi.
+func (mc *ModerationController) RejectMarker(c *gin.Context) {
+	mc.moderateMarker(c, models.MarkerStatusRejected)
+}
+
+// moderateMarker mengubah status marker pending dan memberi tahu pengirimnya melalui log aktivitas.
+func (mc *ModerationController) moderateMarker(c *gin.Context, decision string) {
+	markerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return
+	}
+
+	var input ModerateMarkerInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if decision == models.MarkerStatusRejected && input.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required when rejecting a marker"})
+		return
+	}
+
+	moderatorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var marker models.Marker
+	if err := mc.DB.First(&marker, "id = ?", markerID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Marker not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find marker: " + err.Error()})
+		}
+		return
+	}
+	if marker.Status != models.MarkerStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending markers can be moderated", "status": marker.Status})
+		return
+	}
+
+	now := time.Now()
+	marker.Status = decision
+	marker.ModeratedByUserID = &moderatorID
+	marker.ModeratedAt = &now
+	marker.ModerationNote = nil
+	if input.Reason != "" {
+		marker.ModerationNote = &input.Reason
+	}
+
+	activityData := gin.H{"marker_name": marker.Name, "moderated_by": moderatorID}
+	if input.Reason != "" {
+		activityData["reason"] = input.Reason
+	}
+
+	err = mc.DB.Transaction(func(tx *gorm.DB) error {
+		before, err := loadMarkerSnapshot(tx, marker.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&marker).Updates(map[string]interface{}{
+			"status":               marker.Status,
+			"moderated_by_user_id": marker.ModeratedByUserID,
+			"moderated_at":         marker.ModeratedAt,
+			"moderation_note":      marker.ModerationNote,
+		}).Error; err != nil {
+			return err
+		}
+		after, err := loadMarkerSnapshot(tx, marker.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := recordMarkerRevision(tx, marker.ID, models.RevisionActionUpdate, moderatorID, before, after, nil); err != nil {
+			return err
+		}
+		// Notifikasi ke pengirim dicatat di log aktivitas miliknya
+		return utils.LogActivity(tx, marker.AddedByUserID, "marker_"+decision, &marker.ID, activityData)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate marker: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Marker " + decision + " successfully", "marker": marker})
+}
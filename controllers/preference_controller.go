@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ulyngo/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kunci preferensi yang dipahami server. Kunci lain tetap boleh disimpan apa adanya (misalnya untuk frontend).
+const (
+	PreferenceFavoriteCategories = "favorite_category_ids" // Array JSON berisi ID kategori, misal ["<uuid>", "<uuid>"]
+	PreferenceFavoriteTags       = "favorite_tag_ids"      // Array JSON berisi ID tag
+	PreferenceHomeLocation       = "home_location"         // Koordinat "lat,lng" sebagai lokasi default rekomendasi
+	PreferenceMaxDistance        = "max_distance_meters"   // Radius default rekomendasi dalam meter
+)
+
+// PreferenceController menangani preferensi key-value milik pengguna.
+type PreferenceController struct {
+	DB *gorm.DB
+}
+
+// NewPreferenceController adalah konstruktor untuk PreferenceController.
+func NewPreferenceController(db *gorm.DB) *PreferenceController {
+	return &PreferenceController{DB: db}
+}
+
+// GetMyPreferences mengambil semua preferensi pengguna saat ini sebagai objek key-value. (Protected)
+func (pc *PreferenceController) GetMyPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	preferences, err := loadPreferences(pc.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch preferences: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdateMyPreferences menyimpan (upsert) satu atau lebih preferensi dari objek key-value. (Protected)
+// Kunci yang dipahami server divalidasi formatnya; kunci yang tidak dikirim tidak diubah.
+func (pc *PreferenceController) UpdateMyPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var input map[string]string
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(input) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one preference is required"})
+		return
+	}
+
+	rows := make([]models.Preference, 0, len(input))
+	for key, value := range input {
+		key = strings.TrimSpace(key)
+		if key == "" || len(key) > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Preference keys must be between 1 and 100 characters"})
+			return
+		}
+		if err := validatePreference(pc.DB, key, value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s: %v", key, err)})
+			return
+		}
+		rows = append(rows, models.Preference{UserID: userID, Key: key, Value: value})
+	}
+
+	// Preferensi yang pernah di-soft delete dihidupkan kembali oleh upsert
+	if err := pc.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"value":      gorm.Expr("EXCLUDED.value"),
+			"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+			"deleted_at": nil,
+		}),
+	}).Create(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save preferences: " + err.Error()})
+		return
+	}
+
+	preferences, err := loadPreferences(pc.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch preferences: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+// DeleteMyPreference menghapus satu preferensi berdasarkan :key. (Protected)
+func (pc *PreferenceController) DeleteMyPreference(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	result := pc.DB.Where("user_id = ? AND key = ?", userID, c.Param("key")).Delete(&models.Preference{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete preference: " + result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Preference not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Preference deleted successfully"})
+}
+
+// loadPreferences membaca preferensi pengguna sebagai map key-value.
+func loadPreferences(db *gorm.DB, userID uuid.UUID) (map[string]string, error) {
+	var rows []models.Preference
+	if err := db.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	preferences := make(map[string]string, len(rows))
+	for _, row := range rows {
+		preferences[row.Key] = row.Value
+	}
+	return preferences, nil
+}
+
+// validatePreference memeriksa format nilai untuk kunci preferensi yang dipahami server.
+func validatePreference(db *gorm.DB, key, value string) error {
+	switch key {
+	case PreferenceFavoriteCategories:
+		ids, err := parsePreferenceIDs(value)
+		if err != nil {
+			return err
+		}
+		return ensureIDsExist(db, &models.MarkerCategory{}, ids, "categories")
+	case PreferenceFavoriteTags:
+		ids, err := parsePreferenceIDs(value)
+		if err != nil {
+			return err
+		}
+		// ID tag yang sudah di-merge tetap diterima dan dianggap sebagai tag tujuannya
+		if ids, err = canonicalTagIDs(db, ids); err != nil {
+			return err
+		}
+		return ensureIDsExist(db, &models.MarkerTag{}, ids, "tags")
+	case PreferenceHomeLocation:
+		_, _, err := parseLatLng(value)
+		return err
+	case PreferenceMaxDistance:
+		meters, err := strconv.Atoi(value)
+		if err != nil || meters <= 0 || meters > maxRecommendationRadius {
+			return fmt.Errorf("must be an integer between 1 and %d", maxRecommendationRadius)
+		}
+	}
+	return nil
+}
+
+// parsePreferenceIDs mem-parse nilai preferensi berupa array JSON berisi UUID.
+func parsePreferenceIDs(value string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := json.Unmarshal([]byte(value), &ids); err != nil {
+		return nil, fmt.Errorf("must be a JSON array of IDs")
+	}
+	return ids, nil
+}
+
+// ensureIDsExist memastikan semua ID merujuk ke baris model yang ada (dan belum dihapus).
+func ensureIDsExist(db *gorm.DB, model interface{}, ids []uuid.UUID, label string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	unique := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	var count int64
+	if err := db.Model(model).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(unique) {
+		return fmt.Errorf("one or more %s do not exist", label)
+	}
+	return nil
+}
@@ -0,0 +1,602 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"ulyngo/models"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultRecommendationRadius dan maxRecommendationRadius dalam meter.
+	defaultRecommendationRadius = 25000
+	maxRecommendationRadius     = 200000
+	// recommendationHistory adalah rentang aktivitas pengguna yang dipakai sebagai sinyal minat.
+	recommendationHistory = 90 * 24 * time.Hour
+	// recommendationHalfLife membuat aktivitas lama semakin kecil pengaruhnya.
+	recommendationHalfLife = 30 * 24 * time.Hour
+	// coVisitWindow adalah jarak waktu maksimum dua aktivitas pengguna lain agar dianggap satu kunjungan bersama.
+	coVisitWindow = 24 * time.Hour
+	// recommendationPoolSize membatasi jumlah kandidat yang dinilai per permintaan.
+	recommendationPoolSize = 1000
+	// Batas perhitungan co-visitation: hanya aktivitas pengguna lain dalam coVisitHistory pada coVisitMaxSeeds
+	// seed terkuat, paling banyak coVisitMaxSourceRows aktivitas terbaru dan coVisitMaxPairs pasangan marker.
+	coVisitHistory       = 60 * 24 * time.Hour
+	coVisitMaxSeeds      = 50
+	coVisitMaxSourceRows = 5000
+	coVisitMaxPairs      = 2000
+)
+
+// Bobot komponen skor rekomendasi. Tanpa riwayat, co-visitation tidak tersedia sehingga bobotnya
+// dialihkan ke preferensi (konten) dan kualitas.
+const (
+	recommendationContentWeight = 0.5
+	recommendationCoVisitWeight = 0.35
+	recommendationQualityWeight = 0.15
+)
+
+// interactionWeights adalah bobot setiap jenis interaksi sebagai sinyal minat pengguna.
+var interactionWeights = map[string]float64{
+	"view_marker":     1,
+	"favorite_marker": 3,
+	"review_marker":   2,
+}
+
+// interactionVerbs dipakai untuk menyusun penjelasan rekomendasi.
+var interactionVerbs = map[string]string{
+	"view_marker":     "viewed",
+	"favorite_marker": "saved",
+	"review_marker":   "reviewed",
+}
+
+// RecommendationController menyusun rekomendasi marker yang dipersonalisasi.
+type RecommendationController struct {
+	DB *gorm.DB
+}
+
+// NewRecommendationController adalah konstruktor untuk RecommendationController.
+func NewRecommendationController(db *gorm.DB) *RecommendationController {
+	return &RecommendationController{DB: db}
+}
+
+// RecommendationReason menjelaskan mengapa sebuah marker direkomendasikan.
+type RecommendationReason struct {
+	Type           string     `json:"type"` // co_visit, similar, preference, popular
+	Text           string     `json:"text"` // Misal: "Because you viewed Kopi Aroma"
+	SourceMarkerID *uuid.UUID `json:"source_marker_id,omitempty"`
+}
+
+// Recommendation adalah satu marker yang direkomendasikan beserta skor dan alasannya.
+type Recommendation struct {
+	Score          float64              `json:"score"`
+	DistanceMeters *float64             `json:"distance_meters,omitempty"`
+	Reason         RecommendationReason `json:"reason"`
+	Marker         models.Marker        `json:"marker"`
+}
+
+// seedMarker adalah marker yang pernah diinteraksikan pengguna beserta bobot minatnya.
+type seedMarker struct {
+	Weight      float64
+	Interaction string // Jenis interaksi terkuat, untuk penjelasan
+	Name        string
+	CategoryID  uuid.UUID
+	TagIDs      []uuid.UUID
+}
+
+// GetMyRecommendations menyusun rekomendasi marker untuk pengguna saat ini. (Protected)
+// Skor menggabungkan kemiripan konten (kategori & tag) dengan marker yang pernah dilihat, disimpan,
+// atau diulas, co-visitation item-ke-item dari log aktivitas pengguna lain, dan kualitas marker
+// (rating Bayesian). Pengguna tanpa riwayat (cold start) memakai preferensi favorite_category_ids dan favorite_tag_ids.
+// Query opsional: ?near=lat,lng (default preferensi home_location), ?radius= (meter, default preferensi
+// max_distance_meters atau 25000), ?category_id=, ?limit= (1-50, default 20).
+func (rc *RecommendationController) GetMyRecommendations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 50"})
+		return
+	}
+	var categoryFilter *uuid.UUID
+	if v := c.Query("category_id"); v != "" {
+		parsed, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+			return
+		}
+		categoryFilter = &parsed
+	}
+
+	preferences, err := loadPreferences(rc.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch preferences: " + err.Error()})
+		return
+	}
+
+	// Lokasi: ?near= lebih diutamakan daripada preferensi home_location
+	var near *[2]float64
+	nearParam := c.Query("near")
+	if nearParam == "" {
+		nearParam = preferences[PreferenceHomeLocation]
+	}
+	if nearParam != "" {
+		lat, lng, err := parseLatLng(nearParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid near: " + err.Error()})
+			return
+		}
+		near = &[2]float64{lat, lng}
+	}
+	radius := float64(defaultRecommendationRadius)
+	if v, ok := preferences[PreferenceMaxDistance]; ok {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 && parsed <= maxRecommendationRadius {
+			radius = float64(parsed)
+		}
+	}
+	if v := c.Query("radius"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 || parsed > maxRecommendationRadius {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "radius must be between 0 and 200000 meters"})
+			return
+		}
+		radius = parsed
+	}
+
+	now := time.Now()
+	seeds, err := rc.loadSeedMarkers(userID, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load activity: " + err.Error()})
+		return
+	}
+	coldStart := len(seeds) == 0
+
+	// Profil konten: bobot kategori dan tag dari marker yang diinteraksikan ditambah preferensi eksplisit
+	categoryProfile := map[uuid.UUID]float64{}
+	tagProfile := map[uuid.UUID]float64{}
+	for _, seed := range seeds {
+		categoryProfile[seed.CategoryID] += seed.Weight
+		for _, tagID := range seed.TagIDs {
+			tagProfile[tagID] += seed.Weight
+		}
+	}
+	preferredCategories, _ := parsePreferenceIDs(preferences[PreferenceFavoriteCategories])
+	preferredTags, _ := parsePreferenceIDs(preferences[PreferenceFavoriteTags])
+	if canonical, err := canonicalTagIDs(rc.DB, preferredTags); err == nil {
+		preferredTags = canonical
+	}
+	preferenceBoost := 1.0 + maxProfileWeight(categoryProfile, tagProfile)
+	for _, id := range preferredCategories {
+		categoryProfile[id] += preferenceBoost
+	}
+	for _, id := range preferredTags {
+		tagProfile[id] += preferenceBoost
+	}
+
+	prior, err := globalAverageRating(rc.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load average rating: " + err.Error()})
+		return
+	}
+
+	coVisits, err := rc.loadCoVisits(userID, seeds, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load co-visitation data: " + err.Error()})
+		return
+	}
+
+	candidates, err := rc.loadCandidates(seeds, categoryProfile, tagProfile, coVisits, near, radius, categoryFilter, prior)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load candidate markers: " + err.Error()})
+		return
+	}
+	candidateIDs := make([]uuid.UUID, 0, len(candidates))
+	for _, marker := range candidates {
+		candidateIDs = append(candidateIDs, marker.ID)
+	}
+	candidateTags, err := markerTagMap(rc.DB, candidateIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load marker tags: " + err.Error()})
+		return
+	}
+	categoryNames, err := categoryNameMap(rc.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load categories: " + err.Error()})
+		return
+	}
+
+	profileNorm := vectorNorm(categoryProfile, tagProfile)
+	maxCoVisit := 0.0
+	for _, bySource := range coVisits {
+		for _, score := range bySource {
+			maxCoVisit = math.Max(maxCoVisit, score)
+		}
+	}
+	contentWeight, coVisitWeight, qualityWeight := recommendationContentWeight, recommendationCoVisitWeight, recommendationQualityWeight
+	if maxCoVisit == 0 {
+		contentWeight += coVisitWeight * 0.6
+		qualityWeight += coVisitWeight * 0.4
+		coVisitWeight = 0
+	}
+
+	recommendations := make([]Recommendation, 0, len(candidates))
+	for _, marker := range candidates {
+		tags := candidateTags[marker.ID]
+
+		// Kemiripan kosinus antara profil pengguna dan vektor marker (kategori + tag, masing-masing bernilai 1)
+		content := 0.0
+		if profileNorm > 0 {
+			dot := categoryProfile[marker.CategoryID]
+			for _, tagID := range tags {
+				dot += tagProfile[tagID]
+			}
+			content = dot / (profileNorm * math.Sqrt(float64(1+len(tags))))
+		}
+
+		// Co-visitation dari marker sumber terkuat
+		coVisit, coVisitSource := 0.0, uuid.Nil
+		for sourceID, score := range coVisits[marker.ID] {
+			if score > coVisit {
+				coVisit, coVisitSource = score, sourceID
+			}
+		}
+		if maxCoVisit > 0 {
+			coVisit /= maxCoVisit
+		}
+
+		quality := bayesianRating(prior, marker.AvgRating, marker.TotalReviews) / 5
+
+		score := contentWeight*content + coVisitWeight*coVisit + qualityWeight*quality
+		recommendation := Recommendation{
+			Score:  math.Round(score*1000) / 1000,
+			Reason: explainRecommendation(marker, tags, content, coVisit, coVisitSource, seeds, preferredCategories, categoryNames, near != nil),
+			Marker: marker,
+		}
+		if near != nil {
+			distance := utils.HaversineMeters(near[0], near[1], marker.Latitude, marker.Longitude)
+			recommendation.DistanceMeters = &distance
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	targets := []translationTarget{}
+	for i := range recommendations {
+		targets = append(targets, markerTranslationTargets(&recommendations[i].Marker)...)
+	}
+	if err := applyTranslations(rc.DB, requestLocaleChain(c), targets); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cold_start": coldStart, "recommendations": recommendations})
+}
+
+// loadSeedMarkers mengumpulkan marker yang pernah dilihat, disimpan, atau diulas pengguna,
+// dengan bobot yang meluruh menurut umur aktivitas. Favorit yang masih tersimpan selalu dihitung penuh.
+func (rc *RecommendationController) loadSeedMarkers(userID uuid.UUID, now time.Time) (map[uuid.UUID]*seedMarker, error) {
+	var activities []struct {
+		TargetID     uuid.UUID
+		ActivityType string
+		Timestamp    time.Time
+	}
+	activityTypes := make([]string, 0, len(interactionWeights))
+	for activityType := range interactionWeights {
+		activityTypes = append(activityTypes, activityType)
+	}
+	if err := rc.DB.Model(&models.UserActivityLog{}).
+		Select("target_id", "activity_type", "timestamp").
+		Where("user_id = ? AND target_id IS NOT NULL AND activity_type IN ? AND timestamp >= ?", userID, activityTypes, now.Add(-recommendationHistory)).
+		Scan(&activities).Error; err != nil {
+		return nil, err
+	}
+	var favoriteIDs []uuid.UUID
+	if err := rc.DB.Model(&models.Favorite{}).Where("user_id = ?", userID).Pluck("marker_id", &favoriteIDs).Error; err != nil {
+		return nil, err
+	}
+
+	seeds := map[uuid.UUID]*seedMarker{}
+	strongest := map[uuid.UUID]float64{}
+	add := func(markerID uuid.UUID, interaction string, weight float64) {
+		seed, ok := seeds[markerID]
+		if !ok {
+			seed = &seedMarker{}
+			seeds[markerID] = seed
+		}
+		seed.Weight += weight
+		if weight > strongest[markerID] {
+			strongest[markerID] = weight
+			seed.Interaction = interaction
+		}
+	}
+	for _, activity := range activities {
+		decay := math.Exp(-math.Ln2 * now.Sub(activity.Timestamp).Seconds() / recommendationHalfLife.Seconds())
+		add(activity.TargetID, activity.ActivityType, interactionWeights[activity.ActivityType]*decay)
+	}
+	for _, markerID := range favoriteIDs {
+		add(markerID, "favorite_marker", interactionWeights["favorite_marker"])
+	}
+	if len(seeds) == 0 {
+		return seeds, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(seeds))
+	for id := range seeds {
+		ids = append(ids, id)
+	}
+	var markers []models.Marker
+	if err := rc.DB.Select("id", "name", "category_id").
+		Where("id IN ? AND status = ?", ids, models.MarkerStatusApproved).Find(&markers).Error; err != nil {
+		return nil, err
+	}
+	tags, err := markerTagMap(rc.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+	// Marker yang sudah dihapus atau tidak (lagi) disetujui tidak menjadi sinyal
+	live := make(map[uuid.UUID]*seedMarker, len(markers))
+	for _, marker := range markers {
+		seed := seeds[marker.ID]
+		seed.Name = marker.Name
+		seed.CategoryID = marker.CategoryID
+		seed.TagIDs = tags[marker.ID]
+		live[marker.ID] = seed
+	}
+	return live, nil
+}
+
+// loadCoVisits menghitung co-visitation item-ke-item: untuk setiap marker sumber (seed), marker lain yang
+// diinteraksikan oleh pengguna lain dalam coVisitWindow dari interaksi mereka dengan sumber tersebut.
+// Skor berupa jumlah pengguna bersama yang diredam popularitas marker tujuan. Data yang dibaca dibatasi
+// (lihat coVisitHistory dan batas lainnya) agar biaya query tidak tumbuh bersama seluruh log aktivitas.
+// Hasil: map[markerTujuan]map[markerSumber]skor.
+func (rc *RecommendationController) loadCoVisits(userID uuid.UUID, seeds map[uuid.UUID]*seedMarker, now time.Time) (map[uuid.UUID]map[uuid.UUID]float64, error) {
+	result := map[uuid.UUID]map[uuid.UUID]float64{}
+	if len(seeds) == 0 {
+		return result, nil
+	}
+	seedIDs := make([]uuid.UUID, 0, len(seeds))
+	for id := range seeds {
+		seedIDs = append(seedIDs, id)
+	}
+	sort.Slice(seedIDs, func(i, j int) bool { return seeds[seedIDs[i]].Weight > seeds[seedIDs[j]].Weight })
+	if len(seedIDs) > coVisitMaxSeeds {
+		seedIDs = seedIDs[:coVisitMaxSeeds]
+	}
+	activityTypes := make([]string, 0, len(interactionWeights))
+	for activityType := range interactionWeights {
+		activityTypes = append(activityTypes, activityType)
+	}
+
+	var rows []struct {
+		SourceID   uuid.UUID
+		MarkerID   uuid.UUID
+		Users      int
+		Popularity int
+	}
+	since := now.Add(-coVisitHistory)
+	if err := rc.DB.Raw(`WITH a AS (
+			SELECT user_id, target_id, timestamp FROM user_activity_logs
+			WHERE target_id IN ? AND user_id <> ? AND activity_type IN ? AND timestamp >= ?
+			ORDER BY timestamp DESC LIMIT ?),
+		pairs AS (
+			SELECT a.target_id AS source_id, b.target_id AS marker_id, COUNT(DISTINCT a.user_id) AS users
+			FROM a
+			JOIN user_activity_logs b ON b.user_id = a.user_id AND b.target_id <> a.target_id
+				AND b.activity_type IN ? AND b.timestamp BETWEEN a.timestamp - ? * INTERVAL '1 second' AND a.timestamp + ? * INTERVAL '1 second'
+			GROUP BY a.target_id, b.target_id
+			ORDER BY users DESC LIMIT ?)
+		SELECT pairs.source_id, pairs.marker_id, pairs.users, pop.users AS popularity
+		FROM pairs
+		JOIN (SELECT target_id, COUNT(DISTINCT user_id) AS users FROM user_activity_logs
+			WHERE activity_type IN ? AND timestamp >= ? AND target_id IN (SELECT marker_id FROM pairs) GROUP BY target_id) pop
+			ON pop.target_id = pairs.marker_id`,
+		seedIDs, userID, activityTypes, since, coVisitMaxSourceRows,
+		activityTypes, coVisitWindow.Seconds(), coVisitWindow.Seconds(), coVisitMaxPairs,
+		activityTypes, since).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if _, isSeed := seeds[row.MarkerID]; isSeed {
+			continue
+		}
+		if result[row.MarkerID] == nil {
+			result[row.MarkerID] = map[uuid.UUID]float64{}
+		}
+		// Bobot minat pada sumber ikut menentukan: co-visit dari marker favorit lebih berarti daripada sekadar view
+		result[row.MarkerID][row.SourceID] = seeds[row.SourceID].Weight * float64(row.Users) / math.Sqrt(float64(max(row.Popularity, 1)))
+	}
+	return result, nil
+}
+
+// loadCandidates memuat marker kandidat: marker hasil co-visitation, marker dengan kategori atau tag dari profil,
+// serta marker dengan rating Bayesian tertinggi (prior = rata-rata global) sebagai cadangan. Marker yang sudah
+// diinteraksikan pengguna tidak diikutkan.
+func (rc *RecommendationController) loadCandidates(seeds map[uuid.UUID]*seedMarker, categoryProfile, tagProfile map[uuid.UUID]float64,
+	coVisits map[uuid.UUID]map[uuid.UUID]float64, near *[2]float64, radius float64, categoryFilter *uuid.UUID, prior float64) ([]models.Marker, error) {
+	base := func() *gorm.DB {
+		query := rc.DB.Model(&models.Marker{}).Where("status = ?", models.MarkerStatusApproved)
+		if len(seeds) > 0 {
+			seedIDs := make([]uuid.UUID, 0, len(seeds))
+			for id := range seeds {
+				seedIDs = append(seedIDs, id)
+			}
+			query = query.Where("id NOT IN ?", seedIDs)
+		}
+		if near != nil {
+			minLat, maxLat, minLng, maxLng := utils.BoundingBox(near[0], near[1], radius)
+			query = query.Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?", minLat, maxLat, minLng, maxLng)
+		}
+		if categoryFilter != nil {
+			query = query.Where("category_id IN (?)", categorySubtree(rc.DB, *categoryFilter))
+		}
+		return query
+	}
+
+	byID := map[uuid.UUID]models.Marker{}
+	collect := func(query *gorm.DB, limit int) error {
+		var markers []models.Marker
+		if err := query.Order(bayesianRatingOrder(prior)).Limit(limit).Find(&markers).Error; err != nil {
+			return err
+		}
+		for _, marker := range markers {
+			if near != nil && utils.HaversineMeters(near[0], near[1], marker.Latitude, marker.Longitude) > radius {
+				continue
+			}
+			byID[marker.ID] = marker
+		}
+		return nil
+	}
+
+	related := base()
+	conditions := rc.DB.Where("1 = 0")
+	if len(coVisits) > 0 {
+		coVisitIDs := make([]uuid.UUID, 0, len(coVisits))
+		for id := range coVisits {
+			coVisitIDs = append(coVisitIDs, id)
+		}
+		conditions = conditions.Or("id IN ?", coVisitIDs)
+	}
+	if len(categoryProfile) > 0 {
+		conditions = conditions.Or("category_id IN ?", keysOf(categoryProfile))
+	}
+	if len(tagProfile) > 0 {
+		conditions = conditions.Or("id IN (SELECT marker_id FROM marker_has_tags WHERE tag_id IN ?)", keysOf(tagProfile))
+	}
+	if err := collect(related.Where(conditions), recommendationPoolSize); err != nil {
+		return nil, err
+	}
+	// Cadangan marker berkualitas agar daftar tetap terisi untuk pengguna baru atau profil yang sempit
+	if err := collect(base(), recommendationPoolSize/10); err != nil {
+		return nil, err
+	}
+
+	candidates := make([]models.Marker, 0, len(byID))
+	for _, marker := range byID {
+		candidates = append(candidates, marker)
+	}
+	return candidates, nil
+}
+
+// explainRecommendation memilih alasan utama sebuah rekomendasi.
+func explainRecommendation(marker models.Marker, tags []uuid.UUID, content, coVisit float64, coVisitSource uuid.UUID,
+	seeds map[uuid.UUID]*seedMarker, preferredCategories []uuid.UUID, categoryNames map[uuid.UUID]string, hasLocation bool) RecommendationReason {
+	becauseOf := func(reasonType string, sourceID uuid.UUID) RecommendationReason {
+		seed := seeds[sourceID]
+		id := sourceID
+		return RecommendationReason{Type: reasonType, Text: "Because you " + interactionVerbs[seed.Interaction] + " " + seed.Name, SourceMarkerID: &id}
+	}
+
+	if coVisit > 0 && coVisit >= content {
+		return becauseOf("co_visit", coVisitSource)
+	}
+	if content > 0 {
+		// Marker seed paling mirip: kategori sama bernilai 1, setiap tag yang sama bernilai 1
+		tagSet := make(map[uuid.UUID]bool, len(tags))
+		for _, tagID := range tags {
+			tagSet[tagID] = true
+		}
+		bestID, bestOverlap := uuid.Nil, 0.0
+		for seedID, seed := range seeds {
+			overlap := 0.0
+			if seed.CategoryID == marker.CategoryID {
+				overlap++
+			}
+			for _, tagID := range seed.TagIDs {
+				if tagSet[tagID] {
+					overlap++
+				}
+			}
+			overlap *= seed.Weight
+			if overlap > bestOverlap {
+				bestID, bestOverlap = seedID, overlap
+			}
+		}
+		if bestID != uuid.Nil {
+			return becauseOf("similar", bestID)
+		}
+		for _, categoryID := range preferredCategories {
+			if categoryID == marker.CategoryID && categoryNames[categoryID] != "" {
+				return RecommendationReason{Type: "preference", Text: "Matches your interest in " + categoryNames[categoryID]}
+			}
+		}
+		return RecommendationReason{Type: "preference", Text: "Matches your interests"}
+	}
+	if hasLocation {
+		return RecommendationReason{Type: "popular", Text: "Highly rated near you"}
+	}
+	return RecommendationReason{Type: "popular", Text: "Highly rated"}
+}
+
+// markerTagMap mengambil ID tag untuk setiap marker dalam markerIDs.
+func markerTagMap(db *gorm.DB, markerIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	result := map[uuid.UUID][]uuid.UUID{}
+	if len(markerIDs) == 0 {
+		return result, nil
+	}
+	var links []models.MarkerHasTag
+	if err := db.Where("marker_id IN ?", markerIDs).Find(&links).Error; err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		result[link.MarkerID] = append(result[link.MarkerID], link.TagID)
+	}
+	return result, nil
+}
+
+// categoryNameMap mengambil nama semua kategori berdasarkan ID-nya.
+func categoryNameMap(db *gorm.DB) (map[uuid.UUID]string, error) {
+	var categories []models.MarkerCategory
+	if err := db.Select("id", "name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uuid.UUID]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+	return names, nil
+}
+
+// maxProfileWeight mengembalikan bobot terbesar pada profil kategori dan tag.
+func maxProfileWeight(profiles ...map[uuid.UUID]float64) float64 {
+	result := 0.0
+	for _, profile := range profiles {
+		for _, weight := range profile {
+			result = math.Max(result, weight)
+		}
+	}
+	return result
+}
+
+// vectorNorm menghitung panjang (norma Euclid) gabungan vektor profil.
+func vectorNorm(profiles ...map[uuid.UUID]float64) float64 {
+	sum := 0.0
+	for _, profile := range profiles {
+		for _, weight := range profile {
+			sum += weight * weight
+		}
+	}
+	return math.Sqrt(sum)
+}
+
+// keysOf mengembalikan kunci map sebagai slice.
+func keysOf(values map[uuid.UUID]float64) []uuid.UUID {
+	keys := make([]uuid.UUID, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,349 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ulyngo/models"
+	"ulyngo/sentiment"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReviewController menangani ulasan dan rating marker.
+type ReviewController struct {
+	DB        *gorm.DB
+	Sentiment *ReviewSentimentWorker // Worker analisis sentimen asinkron, boleh nil
+}
+
+// NewReviewController adalah konstruktor untuk ReviewController.
+func NewReviewController(db *gorm.DB, sentimentWorker *ReviewSentimentWorker) *ReviewController {
+	return &ReviewController{DB: db, Sentiment: sentimentWorker}
+}
+
+// CreateReviewInput adalah struktur input untuk membuat ulasan.
+type CreateReviewInput struct {
+	Rating  int     `json:"rating" binding:"required,min=1,max=5"`
+	Comment *string `json:"comment"`
+}
+
+// UpdateReviewInput adalah struktur input untuk memperbarui ulasan. Field yang tidak dikirim tidak diubah.
+type UpdateReviewInput struct {
+	Rating  *int    `json:"rating" binding:"omitempty,min=1,max=5"`
+	Comment *string `json:"comment"`
+}
+
+// ReviewSummary merangkum rating sebuah marker beserta histogram jumlah ulasan per bintang dan sentimen ulasan.
+type ReviewSummary struct {
+	MarkerID     uuid.UUID        `json:"marker_id"`
+	AvgRating    float64          `json:"avg_rating"`
+	TotalReviews int              `json:"total_reviews"`
+	Histogram    map[string]int   `json:"histogram"` // Kunci "1" sampai "5"
+	Sentiment    SentimentSummary `json:"sentiment"`
+}
+
+// SentimentSummary merangkum hasil analisis sentimen ulasan sebuah marker.
+type SentimentSummary struct {
+	AvgScore *float64 `json:"avg_score"` // Null jika belum ada ulasan yang dianalisis
+	Positive int      `json:"positive"`
+	Neutral  int      `json:"neutral"`
+	Negative int      `json:"negative"`
+	Pending  int      `json:"pending"` // Ulasan berkomentar yang belum selesai dianalisis
+}
+
+// reviewSortOrders memetakan nilai ?sort= ke klausa ORDER BY.
+var reviewSortOrders = map[string]string{
+	"newest":  "marker_reviews.created_at DESC",
+	"oldest":  "marker_reviews.created_at ASC",
+	"highest": "marker_reviews.rating DESC, marker_reviews.created_at DESC",
+	"lowest":  "marker_reviews.rating ASC, marker_reviews.created_at DESC",
+	"helpful": "(marker_reviews.helpful_count - marker_reviews.unhelpful_count) DESC, marker_reviews.helpful_count DESC, marker_reviews.created_at DESC",
+}
+
+// reviewQuery menyiapkan query ulasan beserta username pengulas dan balasan resmi.
+func reviewQuery(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.MarkerReview{}).
+		Select("marker_reviews.*, users.username").
+		Joins("LEFT JOIN users ON users.id = marker_reviews.user_id").
+		Preload("Reply")
+}
+
+// findApprovedMarker memastikan marker :id ada dan sudah disetujui. Menulis respons error sendiri jika gagal.
+func (rc *ReviewController) findApprovedMarker(c *gin.Context) (uuid.UUID, bool) {
+	return findApprovedMarkerID(c, rc.DB, c.Param("id"))
+}
+
+// GetReviews mengambil ulasan sebuah marker. (Public)
+// Ulasan yang disembunyikan moderator tidak ditampilkan.
+// Query opsional: ?sort= (newest, oldest, highest, lowest, helpful; default newest), ?limit= (1-100, default 20), ?offset=.
+func (rc *ReviewController) GetReviews(c *gin.Context) {
+	markerID, ok := rc.findApprovedMarker(c)
+	if !ok {
+		return
+	}
+
+	order, ok := reviewSortOrders[c.DefaultQuery("sort", "newest")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be one of newest, oldest, highest, lowest, helpful"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+		return
+	}
+
+	var reviews []models.MarkerReview
+	if err := reviewQuery(rc.DB).Where("marker_reviews.marker_id = ? AND NOT marker_reviews.is_hidden", markerID).
+		Order(order).Limit(limit).Offset(offset).Find(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// GetReviewSummary mengambil rata-rata rating, total ulasan, dan histogram rating sebuah marker. (Public)
+func (rc *ReviewController) GetReviewSummary(c *gin.Context) {
+	markerID, ok := rc.findApprovedMarker(c)
+	if !ok {
+		return
+	}
+
+	var marker models.Marker
+	if err := rc.DB.Select("id", "avg_rating", "total_reviews", "avg_sentiment").First(&marker, "id = ?", markerID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch marker: " + err.Error()})
+		return
+	}
+	var rows []struct {
+		Rating int
+		Count  int
+	}
+	if err := rc.DB.Model(&models.MarkerReview{}).Where("marker_id = ? AND NOT is_hidden", markerID).
+		Select("rating, COUNT(*) AS count").Group("rating").Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rating histogram: " + err.Error()})
+		return
+	}
+
+	histogram := map[string]int{"1": 0, "2": 0, "3": 0, "4": 0, "5": 0}
+	for _, row := range rows {
+		histogram[strconv.Itoa(row.Rating)] = row.Count
+	}
+
+	var sentimentSummary SentimentSummary
+	if err := rc.DB.Model(&models.MarkerReview{}).Where("marker_id = ? AND NOT is_hidden", markerID).
+		Select(`COUNT(*) FILTER (WHERE sentiment_label = ?) AS positive,
+			COUNT(*) FILTER (WHERE sentiment_label = ?) AS neutral,
+			COUNT(*) FILTER (WHERE sentiment_label = ?) AS negative,
+			COUNT(*) FILTER (WHERE sentiment_analyzed_at IS NULL AND comment IS NOT NULL AND comment ~ '[^[:space:]]') AS pending`,
+			sentiment.LabelPositive, sentiment.LabelNeutral, sentiment.LabelNegative).
+		Scan(&sentimentSummary).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sentiment summary: " + err.Error()})
+		return
+	}
+	sentimentSummary.AvgScore = marker.AvgSentiment
+
+	c.JSON(http.StatusOK, ReviewSummary{
+		MarkerID:     markerID,
+		AvgRating:    marker.AvgRating,
+		TotalReviews: marker.TotalReviews,
+		Histogram:    histogram,
+		Sentiment:    sentimentSummary,
+	})
+}
+
+// CreateReview membuat ulasan untuk marker. Setiap pengguna hanya boleh memiliki satu ulasan per marker. (Protected)
+func (rc *ReviewController) CreateReview(c *gin.Context) {
+	markerID, ok := rc.findApprovedMarker(c)
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var input CreateReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review := models.MarkerReview{MarkerID: markerID, UserID: userID, Rating: input.Rating, Comment: input.Comment}
+	errAlreadyReviewed := errors.New("already reviewed")
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci marker membuat pengecekan ulasan ganda di bawah ini aman dari request bersamaan
+		if err := lockMarker(tx, markerID); err != nil {
+			return err
+		}
+		var existing int64
+		if err := tx.Model(&models.MarkerReview{}).Where("marker_id = ? AND user_id = ?", markerID, userID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errAlreadyReviewed
+		}
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+		if err := recomputeMarkerRating(tx, markerID); err != nil {
+			return err
+		}
+		return utils.LogActivity(tx, userID, "review_marker", &markerID, gin.H{"review_id": review.ID, "rating": review.Rating})
+	})
+	if err != nil {
+		if errors.Is(err, errAlreadyReviewed) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You have already reviewed this marker. Edit your existing review instead"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review: " + err.Error()})
+		return
+	}
+
+	rc.Sentiment.Enqueue(review.ID)
+	c.JSON(http.StatusCreated, review)
+}
+
+// UpdateReview memperbarui rating dan/atau komentar ulasan milik pengguna saat ini. (Protected)
+func (rc *ReviewController) UpdateReview(c *gin.Context) {
+	review, ok := rc.findReview(c)
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if review.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only edit your own review"})
+		return
+	}
+	var input UpdateReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	commentChanged := input.Comment != nil && (review.Comment == nil || *review.Comment != *input.Comment)
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockMarker(tx, review.MarkerID); err != nil {
+			return err
+		}
+		if input.Rating != nil {
+			review.Rating = *input.Rating
+		}
+		if input.Comment != nil {
+			review.Comment = input.Comment
+		}
+		// Hanya field milik pengulas yang ditulis agar jumlah suara yang berubah bersamaan tidak tertimpa
+		if err := tx.Model(review).Updates(map[string]interface{}{"rating": review.Rating, "comment": review.Comment}).Error; err != nil {
+			return err
+		}
+		// Sentimen komentar lama tidak lagi berlaku
+		if commentChanged {
+			review.SentimentScore, review.SentimentLabel, review.SentimentAnalyzer, review.SentimentAnalyzedAt = nil, nil, nil, nil
+			if err := resetReviewSentiment(tx, review.ID); err != nil {
+				return err
+			}
+		}
+		return recomputeMarkerRating(tx, review.MarkerID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review: " + err.Error()})
+		return
+	}
+	if commentChanged {
+		rc.Sentiment.Enqueue(review.ID)
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// DeleteReview menghapus (soft delete) ulasan. Hanya pemilik ulasan atau moderator yang boleh menghapus. (Protected)
+func (rc *ReviewController) DeleteReview(c *gin.Context) {
+	review, ok := rc.findReview(c)
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if review.UserID != userID && !isModerator(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own review"})
+		return
+	}
+
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockMarker(tx, review.MarkerID); err != nil {
+			return err
+		}
+		if err := tx.Delete(review).Error; err != nil {
+			return err
+		}
+		return recomputeMarkerRating(tx, review.MarkerID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete review: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Review deleted successfully"})
+}
+
+// findReview mengambil ulasan :reviewID milik marker :id. Menulis respons error sendiri jika gagal.
+func (rc *ReviewController) findReview(c *gin.Context) (*models.MarkerReview, bool) {
+	markerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid marker ID format"})
+		return nil, false
+	}
+	reviewID, err := uuid.Parse(c.Param("reviewID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID format"})
+		return nil, false
+	}
+	var review models.MarkerReview
+	if err := rc.DB.Where("marker_id = ?", markerID).First(&review, "id = ?", reviewID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find review: " + err.Error()})
+		}
+		return nil, false
+	}
+	return &review, true
+}
+
+// lockMarker mengunci baris marker (SELECT ... FOR UPDATE) sehingga perubahan ulasan pada marker
+// yang sama diproses berurutan dan agregat rating selalu konsisten.
+func lockMarker(tx *gorm.DB, markerID uuid.UUID) error {
+	var marker models.Marker
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&marker, "id = ?", markerID).Error
+}
+
+// recomputeMarkerRating menghitung ulang AvgRating, TotalReviews, dan AvgSentiment marker dari tabel ulasan.
+// Ulasan yang disembunyikan moderator tidak dihitung. Dipanggil di dalam transaksi yang sama dengan perubahan ulasan.
+func recomputeMarkerRating(tx *gorm.DB, markerID uuid.UUID) error {
+	var aggregate struct {
+		Avg          float64
+		Total        int
+		AvgSentiment *float64
+	}
+	if err := tx.Model(&models.MarkerReview{}).Where("marker_id = ? AND NOT is_hidden", markerID).
+		Select("COALESCE(AVG(rating), 0) AS avg, COUNT(*) AS total, AVG(sentiment_score) AS avg_sentiment").
+		Scan(&aggregate).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Marker{}).Where("id = ?", markerID).Updates(map[string]interface{}{
+		"avg_rating":    aggregate.Avg,
+		"total_reviews": aggregate.Total,
+		"avg_sentiment": aggregate.AvgSentiment,
+	}).Error
+}
@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"ulyngo/models"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VoteReviewInput adalah struktur input untuk menilai kegunaan ulasan.
+type VoteReviewInput struct {
+	Helpful *bool `json:"helpful" binding:"required"`
+}
+
+// ReplyReviewInput adalah struktur input untuk balasan resmi atas ulasan.
+type ReplyReviewInput struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// ReportReviewInput adalah struktur input untuk melaporkan ulasan.
+type ReportReviewInput struct {
+	Reason  string  `json:"reason" binding:"required"`
+	Details *string `json:"details"`
+}
+
+// VoteReview memberi atau mengubah suara "membantu"/"tidak membantu" pengguna saat ini atas ulasan. (Protected)
+// Pengguna tidak dapat menilai ulasannya sendiri.
+func (rc *ReviewController) VoteReview(c *gin.Context) {
+	review, ok := rc.findReview(c)
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if review.UserID == userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot vote on your own review"})
+		return
+	}
+	if review.IsHidden {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	var input VoteReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	vote := models.ReviewVote{ReviewID: review.ID, UserID: userID, Helpful: *input.Helpful}
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "review_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"helpful", "updated_at"}),
+		}).Create(&vote).Error; err != nil {
+			return err
+		}
+		return recomputeReviewVotes(tx, review)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to vote on review: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Vote recorded", "helpful_count": review.HelpfulCount, "unhelpful_count": review.UnhelpfulCount})
+}
+
+// RemoveReviewVote menghapus suara pengguna saat ini atas ulasan. (Protected)
+func (rc *ReviewController) RemoveReviewVote(c *gin.Context) {
+	review, ok := rc.findReview(c)
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("review_id = ? AND user_id = ?", review.ID, userID).Delete(&models.ReviewVote{}).Error; err != nil {
+			return err
+		}
+		return recomputeReviewVotes(tx, review)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove vote: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Vote removed", "helpful_count": review.HelpfulCount, "unhelpful_count": review.UnhelpfulCount})
+}
+
+// recomputeReviewVotes menghitung ulang jumlah suara ulasan dari tabel review_votes di dalam transaksi tx
+// dan memperbarui nilai pada struct review. Baris ulasan dikunci (SELECT ... FOR UPDATE) lebih dulu agar
+// dua suara yang masuk bersamaan tidak saling menimpa hasil hitungan.
+func recomputeReviewVotes(tx *gorm.DB, review *models.MarkerReview) error {
+	var locked models.MarkerReview
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, "id = ?", review.ID).Error; err != nil {
+		return err
+	}
+	var counts struct {
+		Helpful   int
+		Unhelpful int
+	}
+	if err := tx.Model(&models.ReviewVote{}).Where("review_id = ?", review.ID).
+		Select("COUNT(*) FILTER (WHERE helpful) AS helpful, COUNT(*) FILTER (WHERE NOT helpful) AS unhelpful").
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+	review.HelpfulCount, review.UnhelpfulCount = counts.Helpful, counts.Unhelpful
+	return tx.Model(&models.MarkerReview{}).Where("id = ?", review.ID).Updates(map[string]interface{}{
+		"helpful_count":   counts.Helpful,
+		"unhelpful_count": counts.Unhelpful,
+	}).Error
+}
+
+// ReplyToReview membuat atau memperbarui balasan resmi atas ulasan. (Protected)
+// Hanya pemilik marker atau moderator yang boleh membalas, dan setiap ulasan hanya memiliki satu balasan.
+func (rc *ReviewController) ReplyToReview(c *gin.Context) {
+	review, ok := rc.findReview(c)
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var marker models.Marker
+	if err := rc.DB.Select("id", "name", "added_by_user_id").First(&marker, "id = ?", review.MarkerID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find marker: " + err.Error()})
+		return
+	}
+	if marker.AddedByUserID != userID && !isModerator(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the marker owner or a moderator can reply to reviews"})
+		return
+	}
+	var input ReplyReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Body = strings.TrimSpace(input.Body)
+	if input.Body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reply body cannot be empty"})
+		return
+	}
+
+	reply := models.ReviewReply{ReviewID: review.ID, AuthorUserID: userID, Body: input.Body}
+	status := http.StatusCreated
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.ReviewReply
+		err := tx.Where("review_id = ?", review.ID).First(&existing).Error
+		if err == nil {
+			status = http.StatusOK
+			existing.AuthorUserID = userID
+			existing.Body = input.Body
+			reply = existing
+			return tx.Save(&reply).Error
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+		if err := tx.Create(&reply).Error; err != nil {
+			return err
+		}
+		// Pengulas diberi tahu melalui log aktivitasnya
+		return utils.LogActivity(tx, review.UserID, "review_replied", &review.MarkerID, gin.H{"review_id": review.ID, "marker_name": marker.Name})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reply: " + err.Error()})
+		return
+	}
+	c.JSON(status, reply)
+}
+
+// DeleteReviewReply menghapus balasan resmi atas ulasan. (Protected)
+// Hanya penulis balasan, pemilik marker, atau moderator yang boleh menghapus.
+func (rc *ReviewController) DeleteReviewReply(c *gin.Context) {
+	review, ok := rc.findReview(c)
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var reply models.ReviewReply
+	if err := rc.DB.Where("review_id = ?", review.ID).First(&reply).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Reply not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find reply: " + err.Error()})
+		}
+		return
+	}
+	var marker models.Marker
+	if err := rc.DB.Select("id", "added_by_user_id").First(&marker, "id = ?", review.MarkerID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find marker: " + err.Error()})
+		return
+	}
+	if reply.AuthorUserID != userID && marker.AddedByUserID != userID && !isModerator(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to delete this reply"})
+		return
+	}
+
+	if err := rc.DB.Delete(&reply).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reply: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reply deleted successfully"})
+}
+
+// ReportReview melaporkan ulasan yang melanggar aturan ke antrean moderasi. (Protected)
+// Setiap pengguna hanya dapat melaporkan ulasan yang sama satu kali.
+func (rc *ReviewController) ReportReview(c *gin.Context) {
+	review, ok := rc.findReview(c)
+	if !ok {
+		return
+	}
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	if review.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot report your own review"})
+		return
+	}
+	var input ReportReviewInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !slices.Contains(models.ReviewReportReasons, input.Reason) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reason", "allowed_reasons": models.ReviewReportReasons})
+		return
+	}
+
+	report := models.ReviewReport{
+		ReviewID:       review.ID,
+		ReporterUserID: userID,
+		Reason:         input.Reason,
+		Details:        input.Details,
+		Status:         models.ReviewReportStatusPending,
+	}
+	errAlreadyReported := errors.New("already reported")
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.ReviewReport{}).Where("review_id = ? AND reporter_user_id = ?", review.ID, userID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errAlreadyReported
+		}
+		return tx.Create(&report).Error
+	})
+	if err != nil {
+		if errors.Is(err, errAlreadyReported) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You have already reported this review"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to report review: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Review reported. A moderator will look into it", "report": report})
+}
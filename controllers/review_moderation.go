@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ulyngo/models"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReviewReportQueueItem adalah satu ulasan pada antrean moderasi beserta laporan-laporannya.
+type ReviewReportQueueItem struct {
+	Review      models.MarkerReview   `json:"review"`
+	Reports     []models.ReviewReport `json:"reports"`
+	ReportCount int                   `json:"report_count"`
+}
+
+// GetReviewReportQueue mengambil ulasan yang dilaporkan, dikelompokkan per ulasan dan
+// diurutkan dari yang paling banyak dilaporkan. Query ?status= (pending, resolved, dismissed; default pending),
+// ?limit= dan ?offset= per ulasan. Ulasan yang sudah dihapus penulisnya tidak ditampilkan.
+func (mc *ModerationController) GetReviewReportQueue(c *gin.Context) {
+	status := c.DefaultQuery("status", models.ReviewReportStatusPending)
+	switch status {
+	case models.ReviewReportStatusPending, models.ReviewReportStatusResolved, models.ReviewReportStatusDismissed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of pending, resolved, dismissed"})
+		return
+	}
+	limit, offset, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	// Ulasan dengan laporan terbanyak didahulukan, lalu yang laporannya paling lama menunggu
+	var reviewIDs []uuid.UUID
+	if err := mc.DB.Model(&models.ReviewReport{}).
+		Joins("JOIN marker_reviews ON marker_reviews.id = review_reports.review_id AND marker_reviews.deleted_at IS NULL").
+		Where("review_reports.status = ?", status).
+		Group("review_reports.review_id").
+		Order("COUNT(*) DESC, MIN(review_reports.created_at) ASC, review_reports.review_id").
+		Limit(limit).Offset(offset).
+		Pluck("review_reports.review_id", &reviewIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review reports: " + err.Error()})
+		return
+	}
+	if len(reviewIDs) == 0 {
+		c.JSON(http.StatusOK, []ReviewReportQueueItem{})
+		return
+	}
+
+	var reports []models.ReviewReport
+	if err := mc.DB.Where("review_id IN ? AND status = ?", reviewIDs, status).Order("created_at ASC").Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review reports: " + err.Error()})
+		return
+	}
+	byReview := make(map[uuid.UUID][]models.ReviewReport, len(reviewIDs))
+	for _, report := range reports {
+		byReview[report.ReviewID] = append(byReview[report.ReviewID], report)
+	}
+	var reviews []models.MarkerReview
+	if err := reviewQuery(mc.DB).Where("marker_reviews.id IN ?", reviewIDs).Find(&reviews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reported reviews: " + err.Error()})
+		return
+	}
+	reviewByID := make(map[uuid.UUID]models.MarkerReview, len(reviews))
+	for _, review := range reviews {
+		reviewByID[review.ID] = review
+	}
+
+	queue := make([]ReviewReportQueueItem, 0, len(reviewIDs))
+	for _, id := range reviewIDs {
+		review, found := reviewByID[id]
+		if !found {
+			continue
+		}
+		items := byReview[id]
+		queue = append(queue, ReviewReportQueueItem{Review: review, Reports: items, ReportCount: len(items)})
+	}
+	c.JSON(http.StatusOK, queue)
+}
+
+// HideReview menyembunyikan ulasan dari publik dan mengeluarkannya dari perhitungan AvgRating.
+// Semua laporan pending atas ulasan tersebut ditandai resolved. Alasan wajib diisi.
+func (mc *ModerationController) HideReview(c *gin.Context) {
+	mc.setReviewHidden(c, true)
+}
+
+// UnhideReview menampilkan kembali ulasan yang disembunyikan.
+func (mc *ModerationController) UnhideReview(c *gin.Context) {
+	mc.setReviewHidden(c, false)
+}
+
+// setReviewHidden mengubah status tersembunyi ulasan dan menghitung ulang rating marker dalam satu transaksi.
+func (mc *ModerationController) setReviewHidden(c *gin.Context, hidden bool) {
+	reviewID, err := uuid.Parse(c.Param("reviewID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID format"})
+		return
+	}
+	var input ModerateMarkerInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if hidden && input.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required when hiding a review"})
+		return
+	}
+	moderatorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var review models.MarkerReview
+	if err := mc.DB.First(&review, "id = ?", reviewID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find review: " + err.Error()})
+		}
+		return
+	}
+	if review.IsHidden == hidden {
+		c.JSON(http.StatusConflict, gin.H{"error": "Review is already in the requested state", "is_hidden": review.IsHidden})
+		return
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"is_hidden": hidden, "hidden_reason": nil, "hidden_by_user_id": nil, "hidden_at": nil}
+	if hidden {
+		updates["hidden_reason"] = input.Reason
+		updates["hidden_by_user_id"] = moderatorID
+		updates["hidden_at"] = now
+	}
+
+	err = mc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockMarker(tx, review.MarkerID); err != nil {
+			return err
+		}
+		if err := tx.Model(&review).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := recomputeMarkerRating(tx, review.MarkerID); err != nil {
+			return err
+		}
+		if !hidden {
+			return nil
+		}
+		if err := tx.Model(&models.ReviewReport{}).
+			Where("review_id = ? AND status = ?", review.ID, models.ReviewReportStatusPending).
+			Updates(map[string]interface{}{
+				"status":              models.ReviewReportStatusResolved,
+				"resolved_by_user_id": moderatorID,
+				"resolved_at":         now,
+			}).Error; err != nil {
+			return err
+		}
+		// Pengulas diberi tahu melalui log aktivitasnya
+		return utils.LogActivity(tx, review.UserID, "review_hidden", &review.MarkerID, gin.H{"review_id": review.ID, "reason": input.Reason})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate review: " + err.Error()})
+		return
+	}
+
+	if err := mc.DB.First(&review, "id = ?", reviewID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload review: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Review updated successfully", "review": review})
+}
+
+// DismissReviewReport menandai laporan sebagai tidak valid tanpa mengubah ulasan.
+func (mc *ModerationController) DismissReviewReport(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("reportID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID format"})
+		return
+	}
+	moderatorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var report models.ReviewReport
+	if err := mc.DB.First(&report, "id = ?", reportID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find report: " + err.Error()})
+		}
+		return
+	}
+	if report.Status != models.ReviewReportStatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending reports can be dismissed", "status": report.Status})
+		return
+	}
+
+	now := time.Now()
+	report.Status = models.ReviewReportStatusDismissed
+	report.ResolvedByUserID = &moderatorID
+	report.ResolvedAt = &now
+	if err := mc.DB.Model(&report).Updates(map[string]interface{}{
+		"status":              report.Status,
+		"resolved_by_user_id": report.ResolvedByUserID,
+		"resolved_at":         report.ResolvedAt,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dismiss report: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Report dismissed", "report": report})
+}
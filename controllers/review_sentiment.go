@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"ulyngo/models"
+	"ulyngo/sentiment"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	sentimentAnalyzeTimeout = 30 * time.Second
+	sentimentBackfillBatch  = 500
+)
+
+// ReviewSentimentWorker menganalisis sentimen komentar ulasan secara asinkron di background,
+// sehingga pembuatan ulasan tidak menunggu analyzer (yang bisa berupa layanan eksternal).
+type ReviewSentimentWorker struct {
+	DB       *gorm.DB
+	Analyzer sentiment.SentimentAnalyzer
+	queue    chan uuid.UUID
+}
+
+// NewReviewSentimentWorker adalah konstruktor untuk ReviewSentimentWorker.
+func NewReviewSentimentWorker(db *gorm.DB, analyzer sentiment.SentimentAnalyzer, queueSize int) *ReviewSentimentWorker {
+	return &ReviewSentimentWorker{DB: db, Analyzer: analyzer, queue: make(chan uuid.UUID, queueSize)}
+}
+
+// Start menjalankan sejumlah goroutine pemroses antrean, serta backfill berkala untuk ulasan
+// yang belum dianalisis (ulasan lama, antrean penuh, atau analyzer gagal).
+func (w *ReviewSentimentWorker) Start(workers int, backfillInterval time.Duration) {
+	for i := 0; i < workers; i++ {
+		go func() {
+			for reviewID := range w.queue {
+				if err := w.analyzeReview(reviewID); err != nil {
+					log.Printf("Sentiment analysis for review %s failed: %v", reviewID, err)
+				}
+			}
+		}()
+	}
+	go func() {
+		ticker := time.NewTicker(backfillInterval)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if _, err := w.Backfill(); err != nil {
+				log.Printf("Sentiment backfill failed: %v", err)
+			}
+		}
+	}()
+}
+
+// Enqueue menjadwalkan analisis sentimen sebuah ulasan tanpa memblokir.
+// Jika antrean penuh, ulasan akan diproses pada backfill berikutnya.
+func (w *ReviewSentimentWorker) Enqueue(reviewID uuid.UUID) {
+	if w == nil {
+		return
+	}
+	select {
+	case w.queue <- reviewID:
+	default:
+		log.Printf("Sentiment queue full, review %s will be picked up by backfill", reviewID)
+	}
+}
+
+// Backfill memasukkan ulasan berkomentar yang belum dianalisis ke antrean.
+// Komentar yang hanya berisi spasi tidak ikut, sama seperti di analyzeReview, agar tidak dijadwalkan ulang
+// pada setiap backfill. Mengembalikan jumlah ulasan yang dijadwalkan.
+func (w *ReviewSentimentWorker) Backfill() (int, error) {
+	var reviewIDs []uuid.UUID
+	if err := w.DB.Model(&models.MarkerReview{}).
+		Where("sentiment_analyzed_at IS NULL AND comment IS NOT NULL AND comment ~ '[^[:space:]]'").
+		Order("created_at ASC").Limit(sentimentBackfillBatch).
+		Pluck("id", &reviewIDs).Error; err != nil {
+		return 0, err
+	}
+	for _, id := range reviewIDs {
+		w.queue <- id // Backfill berjalan di goroutine sendiri sehingga boleh menunggu antrean
+	}
+	return len(reviewIDs), nil
+}
+
+// analyzeReview menghitung sentimen satu ulasan lalu memperbarui ulasan dan agregat marker dalam satu transaksi.
+func (w *ReviewSentimentWorker) analyzeReview(reviewID uuid.UUID) error {
+	var review models.MarkerReview
+	if err := w.DB.First(&review, "id = ?", reviewID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil // Ulasan sudah dihapus
+		}
+		return err
+	}
+	if review.SentimentAnalyzedAt != nil || review.Comment == nil || strings.TrimSpace(*review.Comment) == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sentimentAnalyzeTimeout)
+	defer cancel()
+	result, err := w.Analyzer.Analyze(ctx, *review.Comment)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return w.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockMarker(tx, review.MarkerID); err != nil {
+			return err
+		}
+		// Hasil hanya disimpan jika komentar tidak diubah selama analisis berlangsung
+		updated := tx.Model(&models.MarkerReview{}).
+			Where("id = ? AND comment = ? AND sentiment_analyzed_at IS NULL", review.ID, *review.Comment).
+			Updates(map[string]interface{}{
+				"sentiment_score":       result.Score,
+				"sentiment_label":       result.Label,
+				"sentiment_analyzer":    result.Analyzer,
+				"sentiment_analyzed_at": now,
+			})
+		if updated.Error != nil || updated.RowsAffected == 0 {
+			return updated.Error
+		}
+		return recomputeMarkerRating(tx, review.MarkerID)
+	})
+}
+
+// resetReviewSentiment menandai ulasan agar dianalisis ulang (misalnya setelah komentarnya diubah).
+func resetReviewSentiment(tx *gorm.DB, reviewID uuid.UUID) error {
+	return tx.Model(&models.MarkerReview{}).Where("id = ?", reviewID).Updates(map[string]interface{}{
+		"sentiment_score":       nil,
+		"sentiment_label":       nil,
+		"sentiment_analyzer":    nil,
+		"sentiment_analyzed_at": nil,
+	}).Error
+}
+
+// AnalyzeSentimentInput adalah struktur input untuk analisis sentimen teks bebas.
+type AnalyzeSentimentInput struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// AnalyzeSentiment menganalisis sentimen teks bebas secara langsung dengan analyzer yang dikonfigurasi. (Protected)
+func (rc *ReviewController) AnalyzeSentiment(c *gin.Context) {
+	var input AnalyzeSentimentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if rc.Sentiment == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Sentiment analysis is not configured"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), sentimentAnalyzeTimeout)
+	defer cancel()
+	result, err := rc.Sentiment.Analyzer.Analyze(ctx, input.Text)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to analyze sentiment: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// BackfillSentiment menjadwalkan analisis untuk ulasan yang belum dianalisis. (Admin Protected)
+// Query ?reanalyze=true mengosongkan seluruh hasil lama terlebih dahulu, misalnya setelah mengganti analyzer.
+func (rc *ReviewController) BackfillSentiment(c *gin.Context) {
+	if rc.Sentiment == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Sentiment analysis is not configured"})
+		return
+	}
+	if c.Query("reanalyze") == "true" {
+		// Skor dan label lama ikut dikosongkan agar ulasan tidak terhitung berlabel sekaligus pending,
+		// dan AvgSentiment marker tidak lagi memakai skor lama sampai analisis ulang selesai
+		err := rc.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.MarkerReview{}).Where("sentiment_analyzed_at IS NOT NULL OR sentiment_score IS NOT NULL").
+				UpdateColumns(map[string]interface{}{
+					"sentiment_score":       nil,
+					"sentiment_label":       nil,
+					"sentiment_analyzer":    nil,
+					"sentiment_analyzed_at": nil,
+				}).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Model(&models.Marker{}).Where("avg_sentiment IS NOT NULL").UpdateColumn("avg_sentiment", nil).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset sentiment: " + err.Error()})
+			return
+		}
+	}
+
+	// Backfill bisa menunggu antrean, jadi dijalankan di background. Sisa ulasan di luar satu batch
+	// diambil oleh backfill berkala.
+	go func() {
+		if _, err := rc.Sentiment.Backfill(); err != nil {
+			log.Printf("Sentiment backfill failed: %v", err)
+		}
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"message": "Sentiment backfill started"})
+}
@@ -1,19 +1,19 @@
 package controllers
 
 import (
-	"bytes"
 	"context" // Import context
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"net/url"
-	"os"
 	"strings"
+	"time"
+
+	"ulyngo/directions"
+	"ulyngo/places"
+	"ulyngo/utils"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/oauth2/google" // <-- TAMBAHKAN IMPORT INI
 	"gorm.io/gorm"
 )
 
@@ -22,13 +22,22 @@ import (
 // =================================================================================
 
 type RouteController struct {
-	DB *gorm.DB
+	DB         *gorm.DB
+	Directions directions.DirectionsProvider // Penyedia rute (Google, OSRM, Valhalla, atau fake), dipilih lewat DIRECTIONS_PROVIDER
+	Places     places.PlacesProvider         // Penyedia pencarian tempat (marker ulyngo lalu Google), dipilih lewat PLACES_PROVIDER
+	Trips      TripExtractor                 // NLU untuk PlanTripFromQuery (Vertex AI)
 }
 
-func NewRouteController(db *gorm.DB) *RouteController {
-	return &RouteController{DB: db}
+func NewRouteController(db *gorm.DB, directionsProvider directions.DirectionsProvider, placesProvider places.PlacesProvider, tripExtractor TripExtractor) *RouteController {
+	return &RouteController{DB: db, Directions: directionsProvider, Places: placesProvider, Trips: tripExtractor}
 }
 
+// Parameter pencarian tempat singgah di sekitar tujuan.
+const (
+	placeSearchRadiusMeters = 5000
+	placeSearchLimit        = 10
+)
+
 // Structs untuk request dan response Google Maps API (masih sama)
 type RouteRequest struct {
 	Origin      string `json:"origin" binding:"required"`
@@ -36,43 +45,13 @@ type RouteRequest struct {
 	IsPublic    bool   `json:"is_public"`
 }
 
-type DirectionsAPIResponse struct {
-	Routes []struct {
-		Legs []struct {
-			Distance struct {
-				Text  string `json:"text"`
-				Value int64  `json:"value"`
-			} `json:"distance"`
-			Duration struct {
-				Text  string `json:"text"`
-				Value int64  `json:"value"`
-			} `json:"duration"`
-			StartLocation struct {
-				Lat float64 `json:"lat"`
-				Lng float64 `json:"lng"`
-			} `json:"start_location"`
-			EndLocation struct {
-				Lat float64 `json:"lat"`
-				Lng float64 `json:"lng"`
-			} `json:"end_location"`
-			// ... (properti lain tidak diubah)
-		} `json:"legs"`
-		OverviewPolyline struct {
-			Points string `json:"points"`
-		} `json:"overview_polyline"`
-	} `json:"routes"`
-	Status            string `json:"status"`
-	ErrorMessage      string `json:"error_message,omitempty"`
-	GeocodedWaypoints []struct {
-		PlaceID string `json:"place_id"`
-	} `json:"geocoded_waypoints,omitempty"`
-}
-
 type PlaceSearchRequest struct {
 	Query        string `json:"query" binding:"required"`
 	LocationBias string `json:"location_bias"`
 }
 
+// PlacesAPIResponse adalah bentuk respons Google Places Text Search. Hanya dipakai untuk membaca rencana
+// perjalanan tersimpan yang dibuat sebelum pencarian tempat memakai PlacesProvider.
 type PlacesAPIResponse struct {
 	Results []struct {
 		PlaceID          string `json:"place_id"`
@@ -98,13 +77,57 @@ type TripPlanRequest struct {
 	Query string `json:"query" binding:"required"`
 	// Lokasi awal user, e.g., "Jakarta, Indonesia" atau "latitude,longitude"
 	Origin string `json:"origin" binding:"required"`
+	// Tempat pilihan user untuk setiap tempat singgah, map[nama_stop]id_tempat dari suggested_stops.
+	// Tempat singgah yang tidak dipilih otomatis memakai hasil dengan rating tertinggi.
+	SelectedStops map[string]string `json:"selected_stops"`
+	// ID tempat dari return_trip_shop untuk disinggahi saat pulang; default hasil dengan rating tertinggi
+	SelectedReturnShop string `json:"selected_return_shop"`
+	// Waktu berangkat pulang dari tujuan (RFC3339), opsional, e.g. "2026-10-20T16:00:00+07:00"
+	ReturnDepartureTime *time.Time `json:"return_departure_time"`
 }
 
 // Struct untuk menampung hasil ekstraksi dari Vertex AI
 type ExtractedTripInfo struct {
-	Destination      string   `json:"destination"`
-	StopsAlongTheWay []string `json:"stops_along_the_way"`
-	ReturnTripPlan   string   `json:"return_trip_plan"`
+	Destination      string     `json:"destination"`
+	TravelMode       TravelMode `json:"travel_mode"`
+	StopsAlongTheWay []string   `json:"stops_along_the_way"`
+	ReturnTripPlan   string     `json:"return_trip_plan"`
+}
+
+// TravelMode adalah moda perjalanan dan preferensi rute hasil ekstraksi, e.g. {"mode": "motorcycle", "preferences": ["avoid_tolls"]}
+type TravelMode struct {
+	Mode        string   `json:"mode"`        // driving atau motorcycle
+	Preferences []string `json:"preferences"` // avoid_tolls, avoid_highways, avoid_ferries
+}
+
+// travelPreferenceAvoid memetakan preferensi rute ke jenis jalan yang dihindari penyedia rute.
+var travelPreferenceAvoid = map[string]string{
+	"avoid_tolls":    directions.AvoidTolls,
+	"avoid_highways": directions.AvoidHighways,
+	"avoid_ferries":  directions.AvoidFerries,
+}
+
+// directionsRequest membuat permintaan rute dengan moda dan preferensi ini. Nilai yang tidak dikenal diabaikan.
+func (t TravelMode) directionsRequest(origin, destination string) directions.Request {
+	req := directions.Request{Origin: origin, Destination: destination, Mode: strings.ToLower(strings.TrimSpace(t.Mode))}
+	for _, preference := range t.Preferences {
+		if avoid, ok := travelPreferenceAvoid[strings.ToLower(strings.TrimSpace(preference))]; ok {
+			req.Avoid = append(req.Avoid, avoid)
+		}
+	}
+	return req.Normalize()
+}
+
+// applyRequest menyamakan moda dan preferensi dengan permintaan rute yang benar-benar dikirim,
+// termasuk avoid_tolls yang selalu berlaku untuk motorcycle.
+func (t *TravelMode) applyRequest(req directions.Request) {
+	t.Mode = req.Mode
+	t.Preferences = []string{}
+	for _, preference := range []string{"avoid_tolls", "avoid_highways", "avoid_ferries"} {
+		if req.Avoids(travelPreferenceAvoid[preference]) {
+			t.Preferences = append(t.Preferences, preference)
+		}
+	}
 }
 
 // Struct untuk request ke Vertex AI Gemini
@@ -130,279 +153,44 @@ type VertexAIResponse struct {
 
 // Struct untuk respons akhir yang komprehensif
 type FinalTripPlanResponse struct {
-	Interpretation ExtractedTripInfo            `json:"interpretation"`
-	MainRoute      DirectionsAPIResponse        `json:"main_route"`
-	SuggestedStops map[string]PlacesAPIResponse `json:"suggested_stops"` // map[nama_stop]hasil_pencarian
-	ReturnTripShop PlacesAPIResponse            `json:"return_trip_shop"`
+	Interpretation ExtractedTripInfo         `json:"interpretation"`
+	MainRoute      *directions.Route         `json:"main_route"`
+	SuggestedStops map[string][]places.Place `json:"suggested_stops"`  // map[nama_stop]hasil_pencarian, marker ulyngo lebih dulu
+	ReturnTripShop []places.Place            `json:"return_trip_shop"` // Hasil pencarian toko untuk perjalanan pulang
+	ChosenStops    []TripStop                `json:"chosen_stops"`     // Tempat singgah yang dilewati MainRoute, berurutan
+	Legs           []TripLeg                 `json:"legs"`             // Jarak dan durasi setiap segmen MainRoute
+	Detour         *TripDetour               `json:"detour,omitempty"` // Tambahan jarak dan waktu dibanding rute langsung
+
+	// Perjalanan pulang dari tujuan ke asal melalui toko oleh-oleh yang dipilih
+	ChosenReturnShop *TripStop          `json:"chosen_return_shop,omitempty"`
+	ReturnRoute      *directions.Route  `json:"return_route,omitempty"`
+	Itinerary        []TripItineraryLeg `json:"itinerary"` // Perjalanan berangkat dan pulang
 }
 
 // =================================================================================
 // FUNGSI HELPER BARU (Refaktor dari Controller Lama)
 // =================================================================================
 
-// getDirectionsData adalah versi refaktor dari GetDirections
-// Fungsi ini hanya mengambil data dari API dan mengembalikannya, tanpa menyimpan ke DB atau menulis respons HTTP.
-func (tc *RouteController) getDirectionsData(origin, destination string) (*DirectionsAPIResponse, error) {
-	googleMapsAPIKey := os.Getenv("GOOGLE_MAPS_API_KEY")
-	if googleMapsAPIKey == "" {
-		log.Println("ERROR: GOOGLE_MAPS_API_KEY environment variable not set.")
-		return nil, fmt.Errorf("API key not configured")
-	}
-
-	apiURL := fmt.Sprintf("https://maps.googleapis.com/maps/api/directions/json?origin=%s&destination=%s&key=%s",
-		url.QueryEscape(origin),
-		url.QueryEscape(destination),
-		googleMapsAPIKey)
-
-	// === LANGKAH DEBUG 1: Cetak URL yang akan dipanggil ===
-	// Ini membantu memastikan origin dan destination sudah benar.
-	log.Printf("Calling Google Directions API with URL: %s", apiURL)
-
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		log.Printf("ERROR: Failed to call Google Directions API: %v", err)
-		return nil, fmt.Errorf("failed to call Google Directions API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// === LANGKAH DEBUG 2: Cetak status HTTP dari respons ===
-	log.Printf("Google Directions API returned HTTP status: %s", resp.Status)
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("ERROR: Failed to read directions response body: %v", err)
-		return nil, fmt.Errorf("failed to read directions response: %w", err)
-	}
-
-	// === LANGKAH DEBUG 3: Selalu cetak body respons mentah ===
-	// Ini adalah langkah paling penting. Kita bisa lihat pesan error dari Google.
-	log.Printf("Raw Directions API response: %s", string(body))
-
-	var directionsResp DirectionsAPIResponse
-	if err := json.Unmarshal(body, &directionsResp); err != nil {
-		log.Printf("ERROR: Failed to parse directions response JSON: %v", err)
-		return nil, fmt.Errorf("failed to parse directions response: %w", err)
-	}
-
-	// === LANGKAH DEBUG 4: Cek status internal dari Google ===
-	// Setelah berhasil di-parse, cek status logikanya.
-	if directionsResp.Status != "OK" {
-		errorMessage := fmt.Sprintf("Directions API returned status '%s'. Full error: %s", directionsResp.Status, directionsResp.ErrorMessage)
-		log.Printf("ERROR: %s", errorMessage)
-		return nil, fmt.Errorf(errorMessage)
-	}
-
-	log.Println("Successfully received and parsed directions.")
-	return &directionsResp, nil
-}
-
-// searchPlacesData adalah versi refaktor dari SearchPlaces
+// searchPlacesData mencari tempat di sekitar lokasi bias melalui penyedia tempat yang dikonfigurasi.
 // Hanya mencari dan mengembalikan data, tidak menulis respons HTTP.
-func (tc *RouteController) searchPlacesData(query, locationBias string) (*PlacesAPIResponse, error) {
-	googleMapsAPIKey := os.Getenv("GOOGLE_MAPS_API_KEY")
-	if googleMapsAPIKey == "" {
-		return nil, fmt.Errorf("API key not configured")
-	}
-
-	apiURL := fmt.Sprintf("https://maps.googleapis.com/maps/api/place/textsearch/json?query=%s&key=%s",
-		url.QueryEscape(query),
-		googleMapsAPIKey)
-
-	// Lokasi bias membantu API memberikan hasil yang lebih relevan dengan area tujuan
-	if locationBias != "" {
-		apiURL += "&location=" + url.QueryEscape(locationBias)
-		apiURL += "&radius=100" // cari dalam radius 100meter dari titik lokasi bias
-	}
-
-	resp, err := http.Get(apiURL)
+func (tc *RouteController) searchPlacesData(ctx context.Context, query string, near utils.LatLng) ([]places.Place, error) {
+	results, err := tc.Places.Search(ctx, places.Request{Query: query, Near: &near, RadiusMeters: placeSearchRadiusMeters, Limit: placeSearchLimit})
 	if err != nil {
-		return nil, fmt.Errorf("failed to call Google Places API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read places response: %w", err)
-	}
-
-	var placesResp PlacesAPIResponse
-	if err := json.Unmarshal(body, &placesResp); err != nil {
-		log.Printf("Raw Places API response: %s", string(body))
-		return nil, fmt.Errorf("failed to parse places response: %w", err)
+		return nil, fmt.Errorf("places provider %s failed: %w", tc.Places.Name(), err)
 	}
-
-	if placesResp.Status != "OK" && placesResp.Status != "ZERO_RESULTS" {
-		return nil, fmt.Errorf("places API error: %s - %s", placesResp.Status, placesResp.ErrorMessage)
-	}
-
-	return &placesResp, nil
+	return results, nil
 }
 
-// extractTripDetailsFromVertexAI memanggil Vertex AI untuk NLU (VERSI DIPERBARUI DENGAN ROLE & MODEL YANG BENAR)
-func (tc *RouteController) extractTripDetailsFromVertexAI(query string) (*ExtractedTripInfo, error) {
-	// Variabel lingkungan untuk Vertex AI
-	projectID := os.Getenv("GOOGLE_VERTEX_AI_PROJECT_ID")
-	location := os.Getenv("GOOGLE_VERTEX_AI_LOCATION") // e.g., "us-central1"
-
-	if projectID == "" || location == "" {
-		return nil, fmt.Errorf("vertex AI environment variables not configured (GOOGLE_VERTEX_AI_PROJECT_ID, GOOGLE_VERTEX_AI_LOCATION)")
-	}
-
-	ctx := context.Background()
-	scopes := []string{"https://www.googleapis.com/auth/cloud-platform"}
-
-	tokenSource, err := google.DefaultTokenSource(ctx, scopes...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create token source from service account: %w", err)
-	}
-
-	token, err := tokenSource.Token()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get access token: %w", err)
-	}
-
-	// Prompt yang spesifik untuk meminta output JSON (tidak berubah)
-	prompt := fmt.Sprintf(`
-      	Ekstrak dan inferensi informasi rencana perjalanan dari kalimat berikut ke dalam format JSON yang ketat. Fokus utama adalah menentukan moda transportasi yang paling mungkin dan preferensi rute.
-		Kalimat: "%s"
-
-		Aturan:
-		1.  **destination**: Harus berupa nama lokasi tujuan yang spesifik. Sertakan kota atau negara jika memungkinkan.
-		2.  **travel_mode**: Objek yang berisi detail tentang cara perjalanan.
-			- **mode**: **Lakukan inferensi** untuk menentukan moda transportasi dengan logika prioritas berikut:
-				a. **Eksplisit**: Jika pengguna menyebut "mobil", gunakan "driving". Jika menyebut "motor", "motoran", atau "touring", gunakan "motorcycle".
-				b. **Implisit/Kontekstual (Sangat Penting)**: Jika pengguna menggunakan frasa yang sangat mengindikasikan sepeda motor di konteks Indonesia seperti "lewat jalan tikus", "cari rute alternatif cepat", "selap-selip", atau "hindari ganjil-genap", **simpulkan sebagai "motorcycle"** bahkan jika kata 'motor' tidak disebut.
-				c. **Default**: Jika sama sekali tidak ada petunjuk, gunakan "driving".
-			- **preferences**: Array string berisi preferensi rute. Ekstrak dari frasa seperti "jangan lewat tol" (menjadi "avoid_tolls"), "hindari jalan raya" (menjadi "avoid_highways").
-		3.  **stops_along_the_way**: Array makanan, minuman, atau aktivitas singkat selama perjalanan.
-		4.  **return_trip_plan**: String rencana untuk perjalanan pulang.
-		5.  **legs**: Array objek untuk setiap segmen perjalanan.
-			- **steps**: Array objek yang berisi detail langkah demi langkah untuk segmen tersebut.
-		6.  **Nilai Kosong**: Gunakan nilai kosong yang sesuai ( "", [], {} ) jika informasi tidak ditemukan.
-
-		---
-		Contoh 1
-		Kalimat: "Rute motoran ke Puncak, tapi jangan lewat tol ya."
-		Output JSON:
-		{
-		"destination": "Puncak, Bogor, Indonesia",
-		"travel_mode": {
-			"mode": "motorcycle",
-			"preferences": ["avoid_tolls"]
-		},
-		"stops_along_the_way": [],
-		"return_trip_plan": "",
-		"legs": [
-			{
-			"steps": []
-			}
-		]
-		}
-		---
-		Contoh 2
-		Kalimat: "Mau ke Kota Tua dari Bekasi, cariin jalan tikus dong biar cepet nyampe."
-		Output JSON:
-		{
-		"destination": "Kota Tua, Jakarta, Indonesia",
-		"travel_mode": {
-			"mode": "motorcycle",
-			"preferences": []
-		},
-		"stops_along_the_way": [],
-		"return_trip_plan": "",
-		"legs": [
-			{
-			"steps": []
-			}
-		]
-		}
-		---
-		Contoh 3
-		Kalimat: "Tolong dong rute ke Lembang, mau beli oleh-oleh bolu susu."
-		Output JSON:
-		{
-		"destination": "Lembang, Bandung Barat, Indonesia",
-		"travel_mode": {
-			"mode": "driving",
-			"preferences": []
-		},
-		"stops_along_the_way": [],
-		"return_trip_plan": "beli oleh-oleh bolu susu",
-		"legs": [
-			{
-			"steps": []
-			}
-		]
-		}
-		---
-
-		JSON output:
-    `, query)
-
-	// ======================================================================
-	// === PERBAIKAN DI SINI: Tambahkan "role": "user" ===
-	// ======================================================================
-	reqPayload := map[string]interface{}{
-		"contents": []map[string]interface{}{
-			{
-				"role": "user", // <-- FIELD WAJIB UNTUK API VERSI BARU
-				"parts": []map[string]string{
-					{"text": prompt},
-				},
-			},
-		},
-	}
-
-	jsonReq, err := json.Marshal(reqPayload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal Vertex AI request: %w", err)
-	}
-
-	// Menggunakan nama model yang valid dan stabil
-	apiURL := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/gemini-2.0-flash-001:generateContent", location, projectID, location)
-
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonReq))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create http request for Vertex AI: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call Vertex AI API: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+// getDirectionsData mengambil rute dari penyedia rute yang dikonfigurasi. Gunakan TravelMode.directionsRequest
+// untuk membuat permintaan sesuai moda dan preferensi perjalanan.
+// Fungsi ini hanya mengambil data dan mengembalikannya, tanpa menyimpan ke DB atau menulis respons HTTP.
+func (tc *RouteController) getDirectionsData(ctx context.Context, req directions.Request) (*directions.Route, error) {
+	route, err := tc.Directions.Route(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read Vertex AI response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Vertex AI API returned non-OK status: %d. Raw response: %s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("vertex AI API returned non-OK status: %d", resp.StatusCode)
+		log.Printf("ERROR: Directions provider %s failed for %q -> %q (%s, avoid %v): %v", tc.Directions.Name(), req.Origin, req.Destination, req.Mode, req.Avoid, err)
+		return nil, err
 	}
-
-	// Parsing respons (tidak berubah)
-	var vertexResp VertexAIResponse
-	if err := json.Unmarshal(body, &vertexResp); err != nil || len(vertexResp.Candidates) == 0 {
-		log.Printf("Raw Vertex AI response (unmarshal failed or no candidates): %s", string(body))
-		return nil, fmt.Errorf("failed to parse Vertex AI response or no candidates found")
-	}
-
-	jsonText := vertexResp.Candidates[0].Content.Parts[0].Text
-	jsonText = strings.TrimPrefix(jsonText, "```json")
-	jsonText = strings.TrimSuffix(jsonText, "```")
-
-	var extractedInfo ExtractedTripInfo
-	if err := json.Unmarshal([]byte(jsonText), &extractedInfo); err != nil {
-		log.Printf("Failed to unmarshal JSON from Vertex AI text: %s. Error: %v", jsonText, err)
-		return nil, fmt.Errorf("failed to unmarshal JSON from Vertex AI text: %w", err)
-	}
-
-	return &extractedInfo, nil
+	return route, nil
 }
 
 // =================================================================================
@@ -416,9 +204,13 @@ func (tc *RouteController) PlanTripFromQuery(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.ReturnDepartureTime != nil && !req.ReturnDepartureTime.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "return_departure_time must be in the future"})
+		return
+	}
 
 	// === LANGKAH 1: Ekstrak informasi dari kalimat menggunakan Vertex AI ===
-	extractedInfo, err := tc.extractTripDetailsFromVertexAI(req.Query)
+	extractedInfo, err := tc.Trips.Extract(c.Request.Context(), req.Query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to understand query", "details": err.Error()})
 		return
@@ -428,47 +220,121 @@ func (tc *RouteController) PlanTripFromQuery(c *gin.Context) {
 		return
 	}
 
+	// Moda dan preferensi yang dikirim ke penyedia rute ikut dikembalikan di interpretation
+	extractedInfo.TravelMode.applyRequest(extractedInfo.TravelMode.directionsRequest(req.Origin, extractedInfo.Destination))
+
 	// === LANGKAH 2: Dapatkan rute utama ke tujuan ===
-	mainRoute, err := tc.getDirectionsData(req.Origin, extractedInfo.Destination)
-	// c.JSON(http.StatusInternalServerError, gin.H{"message 1": req.Origin, "message 2": extractedInfo.Destination, "message 3": mainRoute})
-	// return
+	directRoute, err := tc.getDirectionsData(c.Request.Context(), extractedInfo.TravelMode.directionsRequest(req.Origin, extractedInfo.Destination))
+	if errors.Is(err, directions.ErrNoRoute) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No route found to the destination", "destination": extractedInfo.Destination})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get main route", "details": err.Error()})
 		return
 	}
 
-	// Tentukan titik tengah rute sebagai lokasi bias untuk pencarian
-	destLocation := mainRoute.Routes[0].Legs[0].EndLocation
-	locationBiasString := fmt.Sprintf("%f,%f", destLocation.Lat, destLocation.Lng)
+	// Tentukan titik tujuan rute sebagai lokasi bias untuk pencarian
+	destLocation := directRoute.Destination()
 
 	// === LANGKAH 3: Cari setiap tempat singgah yang diinginkan ===
-	suggestedStops := make(map[string]PlacesAPIResponse)
+	suggestedStops := make(map[string][]places.Place)
 	for _, stopQuery := range extractedInfo.StopsAlongTheWay {
-		placesResp, err := tc.searchPlacesData(stopQuery, locationBiasString)
+		results, err := tc.searchPlacesData(c.Request.Context(), stopQuery, destLocation)
 		if err != nil {
 			log.Printf("Could not search for stop '%s': %v", stopQuery, err)
 			continue // Lanjutkan ke stop berikutnya jika ada error
 		}
-		suggestedStops[stopQuery] = *placesResp
+		suggestedStops[stopQuery] = results
 	}
 
 	// === LANGKAH 4: Cari toko untuk rencana perjalanan pulang ===
-	var returnShopResp *PlacesAPIResponse
+	returnShop := []places.Place{}
 	if extractedInfo.ReturnTripPlan != "" {
-		returnShopResp, err = tc.searchPlacesData(extractedInfo.ReturnTripPlan, locationBiasString)
+		results, err := tc.searchPlacesData(c.Request.Context(), extractedInfo.ReturnTripPlan, destLocation)
 		if err != nil {
 			log.Printf("Could not search for return trip plan '%s': %v", extractedInfo.ReturnTripPlan, err)
+		} else {
+			returnShop = results
 		}
 	}
 
-	// === LANGKAH 5: Gabungkan semua hasil dan kirim sebagai respons ===
+	// === LANGKAH 5: Pilih tempat per tempat singgah dan hitung ulang rute melewatinya ===
+	chosenStops, err := chooseTripStops(extractedInfo.StopsAlongTheWay, suggestedStops, req.SelectedStops)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	mainRoute := directRoute
+	if len(chosenStops) > 0 {
+		waypoints := make([]string, len(chosenStops))
+		for i, stop := range chosenStops {
+			waypoints[i] = fmt.Sprintf("%f,%f", stop.Place.Location.Lat, stop.Place.Location.Lng)
+		}
+		stopRequest := extractedInfo.TravelMode.directionsRequest(req.Origin, extractedInfo.Destination)
+		stopRequest.Waypoints = waypoints
+		stopRoute, err := tc.getDirectionsData(c.Request.Context(), stopRequest)
+		if err != nil {
+			// Tetap kirim rute langsung; tempat singgah hanya menjadi saran
+			log.Printf("Could not route through chosen stops, using direct route: %v", err)
+			chosenStops = []TripStop{}
+		} else {
+			mainRoute = stopRoute
+		}
+	}
+
+	// === LANGKAH 6: Hitung rute pulang dari tujuan ke asal melalui toko oleh-oleh ===
+	var chosenReturnShop *TripStop
+	if req.SelectedReturnShop != "" || len(returnShop) > 0 {
+		place, err := pickPlace(returnShop, req.SelectedReturnShop)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid selected_return_shop: " + err.Error()})
+			return
+		}
+		chosenReturnShop = &TripStop{Query: extractedInfo.ReturnTripPlan, Place: *place, AutoPicked: req.SelectedReturnShop == ""}
+	}
+	var returnRoute *directions.Route
+	if chosenReturnShop != nil || req.ReturnDepartureTime != nil {
+		returnRequest := extractedInfo.TravelMode.directionsRequest(extractedInfo.Destination, req.Origin)
+		returnRequest.Departure = req.ReturnDepartureTime
+		if chosenReturnShop != nil {
+			returnRequest.Waypoints = []string{fmt.Sprintf("%f,%f", chosenReturnShop.Place.Location.Lat, chosenReturnShop.Place.Location.Lng)}
+		}
+		returnRoute, err = tc.getDirectionsData(c.Request.Context(), returnRequest)
+		if err != nil {
+			// Rencana tetap dikirim tanpa rute pulang
+			log.Printf("Could not compute return route from '%s': %v", extractedInfo.Destination, err)
+			returnRoute = nil
+		}
+	}
+
+	// === LANGKAH 7: Gabungkan semua hasil dan kirim sebagai respons ===
 	finalResponse := FinalTripPlanResponse{
 		Interpretation: *extractedInfo,
-		MainRoute:      *mainRoute,
+		MainRoute:      mainRoute,
 		SuggestedStops: suggestedStops,
+		ReturnTripShop: returnShop,
+		ChosenStops:    chosenStops,
+		Legs:           tripLegs(mainRoute, req.Origin, extractedInfo.Destination, chosenStops),
 	}
-	if returnShopResp != nil {
-		finalResponse.ReturnTripShop = *returnShopResp
+	if len(chosenStops) > 0 {
+		finalResponse.Detour = tripDetour(directRoute, mainRoute)
+	}
+	outbound := TripItineraryLeg{Kind: TripItineraryOutbound, From: req.Origin, To: extractedInfo.Destination, Via: []string{}}
+	for _, stop := range chosenStops {
+		outbound.Via = append(outbound.Via, stop.Place.Name)
+	}
+	outbound.setRoute(mainRoute, nil)
+	finalResponse.Itinerary = []TripItineraryLeg{outbound}
+	if returnRoute != nil {
+		finalResponse.ChosenReturnShop = chosenReturnShop
+		finalResponse.ReturnRoute = returnRoute
+		inbound := TripItineraryLeg{Kind: TripItineraryReturn, From: extractedInfo.Destination, To: req.Origin, Via: []string{}}
+		if chosenReturnShop != nil {
+			inbound.Via = append(inbound.Via, chosenReturnShop.Place.Name)
+		}
+		inbound.setRoute(returnRoute, req.ReturnDepartureTime)
+		finalResponse.Itinerary = append(finalResponse.Itinerary, inbound)
 	}
 
 	c.JSON(http.StatusOK, finalResponse)
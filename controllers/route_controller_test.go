@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"ulyngo/directions"
+	"ulyngo/places"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubTripExtractor mengembalikan hasil ekstraksi tetap tanpa memanggil Vertex AI.
+type stubTripExtractor struct {
+	info *ExtractedTripInfo
+	err  error
+}
+
+func (s *stubTripExtractor) Extract(ctx context.Context, query string) (*ExtractedTripInfo, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	info := *s.info
+	return &info, nil
+}
+
+// stubPlaces mengembalikan hasil tetap per kalimat pencarian.
+type stubPlaces map[string][]places.Place
+
+func (s stubPlaces) Name() string { return "stub" }
+
+func (s stubPlaces) Search(ctx context.Context, req places.Request) ([]places.Place, error) {
+	return s[req.Query], nil
+}
+
+func TestPlanTripFromQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	searchResults := stubPlaces{
+		"cimol": {
+			{ID: "cimol-1", Source: places.SourceUlyngo, Name: "Cimol Bojot", Location: utils.LatLng{Lat: -6.91, Lng: 107.61}, Rating: 4.2},
+			{ID: "cimol-2", Source: places.SourceGoogle, Name: "Cimol Gembul", Location: utils.LatLng{Lat: -6.915, Lng: 107.605}, Rating: 4.8},
+		},
+		"bolu susu": {
+			{ID: "bolu-1", Source: places.SourceGoogle, Name: "Bolu Susu Lembang", Location: utils.LatLng{Lat: -6.81, Lng: 107.61}, Rating: 4.5},
+		},
+	}
+	braga := &ExtractedTripInfo{
+		Destination:      "-6.917,107.609",
+		TravelMode:       TravelMode{Mode: "motorcycle", Preferences: []string{"avoid_ferries"}},
+		StopsAlongTheWay: []string{"cimol"},
+		ReturnTripPlan:   "bolu susu",
+	}
+
+	tests := []struct {
+		name            string
+		info            *ExtractedTripInfo
+		extractErr      error
+		body            map[string]interface{}
+		wantStatus      int
+		wantStop        string
+		wantReturnShop  string
+		wantLegs        int
+		wantPreferences []string
+	}{
+		{
+			name:            "auto picks highest rated stop and return shop",
+			info:            braga,
+			body:            map[string]interface{}{"query": "motoran ke braga jajan cimol, pulangnya beli bolu susu", "origin": "-6.2,106.8"},
+			wantStatus:      http.StatusOK,
+			wantStop:        "cimol-2",
+			wantReturnShop:  "bolu-1",
+			wantLegs:        2,
+			wantPreferences: []string{"avoid_tolls", "avoid_ferries"},
+		},
+		{
+			name:            "selected stop is honored case-insensitively",
+			info:            braga,
+			body:            map[string]interface{}{"query": "ke braga", "origin": "-6.2,106.8", "selected_stops": map[string]string{"CIMOL": "cimol-1"}},
+			wantStatus:      http.StatusOK,
+			wantStop:        "cimol-1",
+			wantReturnShop:  "bolu-1",
+			wantLegs:        2,
+			wantPreferences: []string{"avoid_tolls", "avoid_ferries"},
+		},
+		{
+			name:            "no stops",
+			info:            &ExtractedTripInfo{Destination: "Bandung"},
+			body:            map[string]interface{}{"query": "ke bandung", "origin": "Jakarta"},
+			wantStatus:      http.StatusOK,
+			wantLegs:        1,
+			wantPreferences: []string{},
+		},
+		{
+			name:       "unknown selected stop",
+			info:       braga,
+			body:       map[string]interface{}{"query": "ke braga", "origin": "Jakarta", "selected_stops": map[string]string{"seblak": "x"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "selected return shop not in results",
+			info:       braga,
+			body:       map[string]interface{}{"query": "ke braga", "origin": "Jakarta", "selected_return_shop": "missing"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "no destination",
+			info:       &ExtractedTripInfo{},
+			body:       map[string]interface{}{"query": "halo", "origin": "Jakarta"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "extractor failure",
+			extractErr: errors.New("vertex down"),
+			body:       map[string]interface{}{"query": "ke braga", "origin": "Jakarta"},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "return departure in the past",
+			info:       braga,
+			body:       map[string]interface{}{"query": "ke braga", "origin": "Jakarta", "return_departure_time": "2001-01-01T00:00:00Z"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing origin",
+			info:       braga,
+			body:       map[string]interface{}{"query": "ke braga"},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := NewRouteController(nil, &directions.FakeProvider{}, searchResults, &stubTripExtractor{info: tt.info, err: tt.extractErr})
+			router := gin.New()
+			router.POST("/plan", controller.PlanTripFromQuery)
+			payload, _ := json.Marshal(tt.body)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/plan", bytes.NewReader(payload)))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var plan FinalTripPlanResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &plan); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			if plan.MainRoute == nil || plan.MainRoute.Provider != "fake" {
+				t.Fatalf("MainRoute = %+v, want a fake route", plan.MainRoute)
+			}
+			if len(plan.MainRoute.Legs) != tt.wantLegs || len(plan.Legs) != tt.wantLegs {
+				t.Errorf("legs = %d/%d, want %d", len(plan.MainRoute.Legs), len(plan.Legs), tt.wantLegs)
+			}
+			if !reflect.DeepEqual(plan.Interpretation.TravelMode.Preferences, tt.wantPreferences) {
+				t.Errorf("preferences = %v, want %v", plan.Interpretation.TravelMode.Preferences, tt.wantPreferences)
+			}
+			if tt.wantStop == "" {
+				if len(plan.ChosenStops) != 0 || plan.Detour != nil {
+					t.Errorf("expected no stops and no detour, got %+v / %+v", plan.ChosenStops, plan.Detour)
+				}
+			} else {
+				if len(plan.ChosenStops) != 1 || plan.ChosenStops[0].Place.ID != tt.wantStop {
+					t.Fatalf("ChosenStops = %+v, want %s", plan.ChosenStops, tt.wantStop)
+				}
+				if plan.Detour == nil || plan.Detour.ExtraDistanceMeters < 0 {
+					t.Errorf("Detour = %+v, want non-negative extra distance", plan.Detour)
+				}
+				if plan.Legs[0].To != plan.ChosenStops[0].Place.Name {
+					t.Errorf("first leg ends at %q, want %q", plan.Legs[0].To, plan.ChosenStops[0].Place.Name)
+				}
+			}
+			if tt.wantReturnShop == "" {
+				if plan.ReturnRoute != nil || len(plan.Itinerary) != 1 {
+					t.Errorf("expected outbound itinerary only, got %+v", plan.Itinerary)
+				}
+			} else {
+				if plan.ChosenReturnShop == nil || plan.ChosenReturnShop.Place.ID != tt.wantReturnShop {
+					t.Fatalf("ChosenReturnShop = %+v, want %s", plan.ChosenReturnShop, tt.wantReturnShop)
+				}
+				if plan.ReturnRoute == nil || len(plan.ReturnRoute.Legs) != 2 || len(plan.Itinerary) != 2 {
+					t.Errorf("return route = %+v, itinerary = %+v", plan.ReturnRoute, plan.Itinerary)
+				}
+			}
+			for label, route := range (&SavedRouteData{TripPlan: &plan}).clientRoutes() {
+				if err := route.Validate(); err != nil {
+					t.Errorf("%s does not validate: %v", label, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeSavedRouteData(t *testing.T) {
+	google := `{"status":"OK","routes":[{"summary":"S","overview_polyline":{"points":"_p~iF~ps|U_ulLnnqC"},"legs":[{"distance":{"value":100},"duration":{"value":60},"start_location":{"lat":38.5,"lng":-120.2},"end_location":{"lat":40.7,"lng":-120.95}}]}]}`
+	googlePlaces := `{"status":"OK","results":[{"place_id":"p1","name":"Cimol","geometry":{"location":{"lat":-6.9,"lng":107.6}},"rating":4.5}]}`
+	route := `{"provider":"osrm","distance_meters":5,"polyline":"_p~iF~ps|U_ulLnnqC","legs":[]}`
+	tests := []struct {
+		name         string
+		raw          string
+		wantSource   string
+		wantProvider string // Provider rute utama, kosong jika tidak ada
+		wantStops    []string
+		wantShop     []string
+		wantErr      bool
+	}{
+		{name: "empty", raw: ``},
+		{name: "raw google without wrapper", raw: google, wantSource: SavedRouteSourceDirections, wantProvider: "google"},
+		{name: "google under directions key", raw: `{"source":"directions","directions":` + google + `}`, wantSource: SavedRouteSourceDirections, wantProvider: "google"},
+		{name: "current directions", raw: `{"source":"directions","route":` + route + `}`, wantSource: SavedRouteSourceDirections, wantProvider: "osrm"},
+		{
+			name:         "trip plan with raw google route and places",
+			raw:          `{"source":"trip_plan","trip_plan":{"main_route":` + google + `,"suggested_stops":{"cimol":` + googlePlaces + `},"return_trip_shop":` + googlePlaces + `}}`,
+			wantSource:   SavedRouteSourceTripPlan,
+			wantProvider: "google",
+			wantStops:    []string{"p1"},
+			wantShop:     []string{"p1"},
+		},
+		{
+			name:         "current trip plan",
+			raw:          `{"source":"trip_plan","trip_plan":{"main_route":` + route + `,"suggested_stops":{"cimol":[{"id":"m1","source":"ulyngo","name":"A"}]},"return_trip_shop":[]}}`,
+			wantSource:   SavedRouteSourceTripPlan,
+			wantProvider: "osrm",
+			wantStops:    []string{"m1"},
+			wantShop:     []string{},
+		},
+		{name: "failed google response", raw: `{"source":"directions","directions":{"status":"REQUEST_DENIED"}}`, wantErr: true},
+		{name: "invalid json", raw: `{"source":`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := decodeSavedRouteData(json.RawMessage(tt.raw))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if data.Source != tt.wantSource {
+				t.Errorf("Source = %q, want %q", data.Source, tt.wantSource)
+			}
+			main := data.route()
+			if tt.wantProvider == "" {
+				if main != nil {
+					t.Errorf("route = %+v, want nil", main)
+				}
+				return
+			}
+			if main == nil || main.Provider != tt.wantProvider || main.Polyline == "" {
+				t.Fatalf("route = %+v, want provider %s with polyline", main, tt.wantProvider)
+			}
+			if tt.wantStops != nil {
+				if got := placeIDs(data.TripPlan.SuggestedStops["cimol"]); !reflect.DeepEqual(got, tt.wantStops) {
+					t.Errorf("suggested stops = %v, want %v", got, tt.wantStops)
+				}
+				if got := placeIDs(data.TripPlan.ReturnTripShop); !reflect.DeepEqual(got, tt.wantShop) {
+					t.Errorf("return trip shop = %v, want %v", got, tt.wantShop)
+				}
+			}
+		})
+	}
+}
+
+func placeIDs(results []places.Place) []string {
+	ids := []string{}
+	for _, place := range results {
+		ids = append(ids, place.ID)
+	}
+	return ids
+}
@@ -0,0 +1,285 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"ulyngo/models"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Parameter pencarian marker di sepanjang rute.
+const (
+	defaultCorridorBufferMeters = 1000.0
+	defaultCorridorLimit        = 50
+	// Perkiraan waktu mampir: jarak keluar-masuk dari jalan utama dikali faktor jalan, ditempuh dengan
+	// kecepatan jalan lokal.
+	corridorDetourRoadFactor = 1.4
+	corridorDetourSpeedKmh   = 30.0
+	// Batas ukuran rute dan kandidat agar satu permintaan tidak memproyeksikan ribuan marker ke ribuan titik.
+	maxCorridorPoints     = 20000
+	corridorBoxCandidates = 500  // Kandidat maksimum per kotak pra-filter, yang paling dekat ke rute lebih dulu
+	corridorMaxBoxes      = 32   // Jumlah kotak pra-filter di sepanjang rute
+	corridorSimplifyShare = 0.05 // Toleransi penyederhanaan rute sebagai porsi buffer
+)
+
+// RouteCorridorInput adalah struktur input untuk mencari marker di sepanjang rute.
+// Isi salah satu dari polyline atau route_id.
+type RouteCorridorInput struct {
+	Polyline         string   `json:"polyline" binding:"max=200000"`                       // Encoded polyline presisi 5 (format Google)
+	RouteID          string   `json:"route_id"`                                            // ID rute tersimpan (public, atau milik sendiri)
+	BufferMeters     float64  `json:"buffer_meters" binding:"omitempty,gt=0,max=20000"`    // Lebar koridor di kiri-kanan rute, default 1000
+	CategoryID       string   `json:"category_id"`                                         // Kategori beserta seluruh subkategorinya
+	Tags             []string `json:"tags" binding:"omitempty,max=20"`                     // ID, nama, atau alias tag; marker cukup memiliki salah satu
+	MaxDetourMinutes float64  `json:"max_detour_minutes" binding:"omitempty,gt=0,max=240"` // Batas perkiraan waktu tambahan untuk mampir
+	Limit            int      `json:"limit" binding:"omitempty,min=1,max=200"`             // Jumlah marker maksimum, default 50
+	Offset           int      `json:"offset" binding:"omitempty,min=0,max=100000"`         // Jumlah marker yang dilewati, untuk halaman berikutnya
+}
+
+// CorridorMarker adalah marker di dalam koridor rute beserta posisinya terhadap rute.
+type CorridorMarker struct {
+	Marker                   models.Marker `json:"marker"`
+	DistanceFromRouteMeters  float64       `json:"distance_from_route_meters"`  // Jarak terpendek marker ke rute
+	DistanceAlongRouteMeters float64       `json:"distance_along_route_meters"` // Jarak dari asal rute sampai titik terdekat ke marker
+	DetourSeconds            int64         `json:"detour_seconds"`              // Perkiraan waktu tambahan untuk mampir (pergi dan kembali ke rute)
+}
+
+// RouteCorridorResponse adalah respons pencarian marker di sepanjang rute.
+type RouteCorridorResponse struct {
+	RouteDistanceMeters float64          `json:"route_distance_meters"`
+	BufferMeters        float64          `json:"buffer_meters"`
+	Total               int              `json:"total"`      // Jumlah seluruh marker di dalam koridor
+	Truncated           bool             `json:"truncated"`  // true jika masih ada marker setelah halaman ini (lanjutkan dengan offset)
+	Incomplete          bool             `json:"incomplete"` // true jika bagian rute yang padat mencapai batas kandidat, sehingga sebagian marker mungkin terlewat
+	Markers             []CorridorMarker `json:"markers"`    // Diurutkan dari yang paling dekat ke asal rute
+}
+
+// GetRouteCorridor mencari marker yang disetujui di dalam koridor sejauh buffer_meters dari rute,
+// diurutkan berdasarkan posisinya di sepanjang rute dan dibagi per halaman dengan limit dan offset. (Public)
+// Rute berasal dari polyline atau route_id (rute private hanya untuk pemiliknya, token opsional).
+func (tc *RouteController) GetRouteCorridor(c *gin.Context) {
+	var input RouteCorridorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Polyline = strings.TrimSpace(input.Polyline)
+	input.RouteID = strings.TrimSpace(input.RouteID)
+	if (input.Polyline == "") == (input.RouteID == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either polyline or route_id"})
+		return
+	}
+	if input.BufferMeters == 0 {
+		input.BufferMeters = defaultCorridorBufferMeters
+	}
+	if input.Limit == 0 {
+		input.Limit = defaultCorridorLimit
+	}
+
+	path, ok := tc.corridorPath(c, &input)
+	if !ok {
+		return
+	}
+	if len(path) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Route must contain at least two points"})
+		return
+	}
+	if len(path) > maxCorridorPoints {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Route must not contain more than %d points", maxCorridorPoints)})
+		return
+	}
+	// Jarak ke rute dihitung pada rute yang disederhanakan; selisihnya paling jauh 10% buffer
+	simplified := utils.SimplifyPath(path, input.BufferMeters*corridorSimplifyShare)
+
+	query := tc.DB.Model(&models.Marker{}).Where("status = ?", models.MarkerStatusApproved)
+	if input.CategoryID != "" {
+		categoryUUID, err := uuid.Parse(input.CategoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+			return
+		}
+		query = query.Where("category_id IN (?)", categorySubtree(tc.DB, categoryUUID))
+	}
+	if len(input.Tags) > 0 {
+		tagIDs := make([]uuid.UUID, 0, len(input.Tags))
+		for _, tag := range input.Tags {
+			tagID, err := resolveTagRef(tc.DB, tag)
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found: " + tag})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve tag: " + err.Error()})
+				return
+			}
+			tagIDs = append(tagIDs, tagID)
+		}
+		query = query.Where("id IN (?)", tc.DB.Model(&models.MarkerHasTag{}).Select("marker_id").Where("tag_id IN ?", tagIDs))
+	}
+	candidates, incomplete, err := corridorCandidates(tc.DB, query, simplified, input.BufferMeters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch markers: " + err.Error()})
+		return
+	}
+
+	metersPerSecond := corridorDetourSpeedKmh * 1000 / 3600
+	results := []CorridorMarker{}
+	for _, marker := range candidates {
+		offset, along := utils.ProjectOntoPath(simplified, utils.LatLng{Lat: marker.Latitude, Lng: marker.Longitude})
+		if offset > input.BufferMeters {
+			continue
+		}
+		detour := int64(math.Round(2 * offset * corridorDetourRoadFactor / metersPerSecond))
+		if input.MaxDetourMinutes > 0 && float64(detour) > input.MaxDetourMinutes*60 {
+			continue
+		}
+		results = append(results, CorridorMarker{
+			Marker:                   marker,
+			DistanceFromRouteMeters:  math.Round(offset),
+			DistanceAlongRouteMeters: math.Round(along),
+			DetourSeconds:            detour,
+		})
+	}
+	// ID sebagai penentu urutan agar halaman dengan offset stabil
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].DistanceAlongRouteMeters != results[j].DistanceAlongRouteMeters {
+			return results[i].DistanceAlongRouteMeters < results[j].DistanceAlongRouteMeters
+		}
+		return results[i].Marker.ID.String() < results[j].Marker.ID.String()
+	})
+	total := len(results)
+	results = results[min(input.Offset, total):min(input.Offset+input.Limit, total)]
+
+	markers := make([]models.Marker, len(results))
+	for i := range results {
+		markers[i] = results[i].Marker
+	}
+	chain := requestLocaleChain(c)
+	if err := localizeMarkers(tc.DB, chain, markers); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+	if err := attachCategoryBreadcrumbs(tc.DB, chain, markers); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build category breadcrumbs: " + err.Error()})
+		return
+	}
+	for i := range results {
+		results[i].Marker = markers[i]
+	}
+
+	c.JSON(http.StatusOK, RouteCorridorResponse{
+		RouteDistanceMeters: math.Round(utils.PathLengthMeters(path)),
+		BufferMeters:        input.BufferMeters,
+		Total:               total,
+		Truncated:           input.Offset+input.Limit < total,
+		Incomplete:          incomplete,
+		Markers:             results,
+	})
+}
+
+// corridorCandidates mengambil kandidat marker koridor dari query (yang sudah berisi filter status, kategori,
+// dan tag) dengan pra-filter murah: rute dibagi menjadi paling banyak corridorMaxBoxes potongan berurutan, dan
+// marker harus berada di kotak salah satu potongan yang diperlebar sejauh buffer. Setiap kotak mengambil paling
+// banyak corridorBoxCandidates marker terdekat ke titik tengah potongannya, sehingga bagian rute yang padat
+// tidak menyingkirkan marker di bagian lain. capped bernilai true jika ada kotak yang mencapai batas tersebut.
+func corridorCandidates(db, query *gorm.DB, path []utils.LatLng, bufferMeters float64) (candidates []models.Marker, capped bool, err error) {
+	query = query.Session(&gorm.Session{})
+	pointsPerBox := (len(path) + corridorMaxBoxes - 1) / corridorMaxBoxes
+	var placeholders []string
+	var boxes []interface{}
+	for start := 0; start < len(path)-1; start += pointsPerBox {
+		end := min(start+pointsPerBox, len(path)-1)
+		minLat, maxLat, minLng, maxLng := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+		// Potongan saling berbagi titik ujung agar setiap segmen rute tercakup
+		for _, point := range path[start : end+1] {
+			pointMinLat, pointMaxLat, pointMinLng, pointMaxLng := utils.BoundingBox(point.Lat, point.Lng, bufferMeters)
+			minLat, maxLat = math.Min(minLat, pointMinLat), math.Max(maxLat, pointMaxLat)
+			minLng, maxLng = math.Min(minLng, pointMinLng), math.Max(maxLng, pointMaxLng)
+		}
+		// Titik tengah potongan berada di rute, sehingga jaraknya mendekati jarak marker ke rute
+		middle := path[(start+end)/2]
+		cosLat := math.Cos(middle.Lat * math.Pi / 180)
+		boxes = append(boxes, query.
+			Select(fmt.Sprintf("id, %d AS box", len(boxes))).
+			Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?", minLat, maxLat, minLng, maxLng).
+			Order(clause.OrderBy{Expression: gorm.Expr("POWER(latitude - ?, 2) + POWER((longitude - ?) * ?, 2), id", middle.Lat, middle.Lng, cosLat)}).
+			Limit(corridorBoxCandidates+1))
+		placeholders = append(placeholders, "(?)")
+	}
+
+	var rows []struct {
+		ID  uuid.UUID
+		Box int
+	}
+	if err := db.Raw(strings.Join(placeholders, " UNION ALL "), boxes...).Scan(&rows).Error; err != nil {
+		return nil, false, err
+	}
+	perBox := make(map[int]int)
+	seen := make(map[uuid.UUID]bool)
+	ids := []uuid.UUID{}
+	for _, row := range rows {
+		perBox[row.Box]++
+		if perBox[row.Box] > corridorBoxCandidates {
+			capped = true
+			continue
+		}
+		// Kotak yang bertetangga bisa memuat marker yang sama
+		if !seen[row.ID] {
+			seen[row.ID] = true
+			ids = append(ids, row.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return []models.Marker{}, capped, nil
+	}
+	if err := db.Where("id IN ?", ids).Find(&candidates).Error; err != nil {
+		return nil, false, err
+	}
+	return candidates, capped, nil
+}
+
+// corridorPath mengambil titik-titik rute dari polyline atau dari rute tersimpan. Menulis respons error sendiri jika gagal.
+func (tc *RouteController) corridorPath(c *gin.Context, input *RouteCorridorInput) ([]utils.LatLng, bool) {
+	if input.Polyline != "" {
+		path, err := utils.DecodePolyline(input.Polyline)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid polyline: " + err.Error()})
+			return nil, false
+		}
+		return path, true
+	}
+
+	routeID, err := uuid.Parse(input.RouteID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid route ID format"})
+		return nil, false
+	}
+	route, ok := tc.loadReadableRoute(c, routeID)
+	if !ok {
+		return nil, false
+	}
+	data, err := decodeSavedRouteData(route.RouteDataJSON)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read route data: " + err.Error()})
+		return nil, false
+	}
+	result := data.route()
+	if result == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Saved route does not contain route geometry"})
+		return nil, false
+	}
+	path, err := result.Points()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode route polyline: " + err.Error()})
+		return nil, false
+	}
+	return path, true
+}
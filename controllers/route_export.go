@@ -0,0 +1,354 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"ulyngo/directions"
+	"ulyngo/models"
+	"ulyngo/places"
+	"ulyngo/routeexport"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ExportTripPlanInput adalah struktur input untuk mengekspor rute yang belum disimpan.
+type ExportTripPlanInput struct {
+	Name        string                 `json:"name" binding:"max=255"` // Default "<origin> - <destination>"
+	Origin      string                 `json:"origin" binding:"required,max=255"`
+	Destination string                 `json:"destination" binding:"max=255"`
+	Route       *directions.Route      `json:"route"`
+	TripPlan    *FinalTripPlanResponse `json:"trip_plan"`
+}
+
+// storedRouteData adalah bentuk longgar RouteDataJSON untuk membaca semua format yang pernah disimpan:
+// respons Google Directions API mentah tanpa pembungkus, {"source":"directions","directions":<respons
+// Google>}, dan bentuk SavedRouteData sekarang. Rute dan hasil pencarian tempat dibaca mentah lalu
+// dikenali formatnya oleh decodeStoredRoute dan decodeStoredPlaces.
+type storedRouteData struct {
+	Source     string          `json:"source"`
+	Route      json.RawMessage `json:"route"`
+	Directions json.RawMessage `json:"directions"`
+	TripPlan   *storedTripPlan `json:"trip_plan"`
+}
+
+// storedTripPlan adalah FinalTripPlanResponse tersimpan. Rute utama dan rute pulang bisa berupa respons
+// Google Directions API mentah, dan hasil pencarian tempat bisa berupa respons Google Places mentah.
+type storedTripPlan struct {
+	FinalTripPlanResponse
+	MainRoute      json.RawMessage            `json:"main_route"`
+	ReturnRoute    json.RawMessage            `json:"return_route"`
+	SuggestedStops map[string]json.RawMessage `json:"suggested_stops"`
+	ReturnTripShop json.RawMessage            `json:"return_trip_shop"`
+}
+
+// decodeSavedRouteData membaca RouteDataJSON dalam format apa pun yang pernah disimpan dan mengubahnya
+// ke bentuk SavedRouteData sekarang.
+func decodeSavedRouteData(raw json.RawMessage) (*SavedRouteData, error) {
+	if len(raw) == 0 {
+		return &SavedRouteData{}, nil
+	}
+	var stored storedRouteData
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		return nil, err
+	}
+	if stored.Source == "" {
+		// Rute paling lama: respons Google Directions API mentah tanpa pembungkus
+		route, err := directions.ParseGoogleResponse(raw)
+		if err != nil {
+			return nil, err
+		}
+		return &SavedRouteData{Source: SavedRouteSourceDirections, Route: route}, nil
+	}
+
+	data := &SavedRouteData{Source: stored.Source}
+	routeJSON := stored.Route
+	if isJSONNull(routeJSON) {
+		routeJSON = stored.Directions
+	}
+	route, err := decodeStoredRoute(routeJSON)
+	if err != nil {
+		return nil, fmt.Errorf("invalid route: %w", err)
+	}
+	data.Route = route
+
+	if stored.TripPlan != nil {
+		plan := stored.TripPlan.FinalTripPlanResponse
+		if plan.MainRoute, err = decodeStoredRoute(stored.TripPlan.MainRoute); err != nil {
+			return nil, fmt.Errorf("invalid trip_plan.main_route: %w", err)
+		}
+		if plan.ReturnRoute, err = decodeStoredRoute(stored.TripPlan.ReturnRoute); err != nil {
+			return nil, fmt.Errorf("invalid trip_plan.return_route: %w", err)
+		}
+		if plan.ReturnTripShop, err = decodeStoredPlaces(stored.TripPlan.ReturnTripShop); err != nil {
+			return nil, fmt.Errorf("invalid trip_plan.return_trip_shop: %w", err)
+		}
+		plan.SuggestedStops = make(map[string][]places.Place, len(stored.TripPlan.SuggestedStops))
+		for query, results := range stored.TripPlan.SuggestedStops {
+			if plan.SuggestedStops[query], err = decodeStoredPlaces(results); err != nil {
+				return nil, fmt.Errorf("invalid trip_plan.suggested_stops[%q]: %w", query, err)
+			}
+		}
+		data.TripPlan = &plan
+	}
+	return data, nil
+}
+
+// decodeStoredRoute membaca rute tersimpan: respons Google Directions API mentah (memiliki "routes" atau
+// "status") atau directions.Route. JSON kosong atau null menghasilkan nil.
+func decodeStoredRoute(raw json.RawMessage) (*directions.Route, error) {
+	if isJSONNull(raw) {
+		return nil, nil
+	}
+	var probe struct {
+		Routes json.RawMessage `json:"routes"`
+		Status string          `json:"status"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, err
+	}
+	if probe.Routes != nil || probe.Status != "" {
+		return directions.ParseGoogleResponse(raw)
+	}
+	var route directions.Route
+	if err := json.Unmarshal(raw, &route); err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+// decodeStoredPlaces membaca hasil pencarian tempat tersimpan: respons Google Places mentah (objek) atau
+// []places.Place. JSON kosong atau null menghasilkan nil.
+func decodeStoredPlaces(raw json.RawMessage) ([]places.Place, error) {
+	if isJSONNull(raw) {
+		return nil, nil
+	}
+	if trimmed := bytes.TrimSpace(raw); trimmed[0] == '{' {
+		var response PlacesAPIResponse
+		if err := json.Unmarshal(trimmed, &response); err != nil {
+			return nil, err
+		}
+		return response.places(), nil
+	}
+	var results []places.Place
+	if err := json.Unmarshal(raw, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// isJSONNull melaporkan apakah nilai JSON kosong atau null.
+func isJSONNull(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null"))
+}
+
+// places mengubah respons Google Places mentah menjadi hasil pencarian tempat.
+func (r PlacesAPIResponse) places() []places.Place {
+	results := make([]places.Place, 0, len(r.Results))
+	for _, result := range r.Results {
+		results = append(results, places.Place{
+			ID:       result.PlaceID,
+			Source:   places.SourceGoogle,
+			Name:     result.Name,
+			Address:  result.FormattedAddress,
+			Location: utils.LatLng{Lat: result.Geometry.Location.Lat, Lng: result.Geometry.Location.Lng},
+			Rating:   float64(result.Rating),
+		})
+	}
+	return results
+}
+
+// route mengembalikan rute utama dari data rute, atau nil jika tidak ada.
+func (d *SavedRouteData) route() *directions.Route {
+	if d.TripPlan != nil && d.TripPlan.MainRoute != nil {
+		return d.TripPlan.MainRoute
+	}
+	return d.Route
+}
+
+// clientRoutes mengembalikan semua rute di dalam data, diberi label untuk pesan error.
+func (d *SavedRouteData) clientRoutes() map[string]*directions.Route {
+	routes := map[string]*directions.Route{}
+	if d.Route != nil {
+		routes["route"] = d.Route
+	}
+	if d.TripPlan != nil {
+		if d.TripPlan.MainRoute != nil {
+			routes["trip_plan.main_route"] = d.TripPlan.MainRoute
+		}
+		if d.TripPlan.ReturnRoute != nil {
+			routes["trip_plan.return_route"] = d.TripPlan.ReturnRoute
+		}
+	}
+	return routes
+}
+
+// buildExportRoute menyusun rute ekspor: jalur dari overview polyline, waypoint asal dan tujuan, serta
+// tempat singgah yang dipilih (hasil teratas setiap pencarian) dan toko oleh-oleh untuk perjalanan pulang.
+func buildExportRoute(route *models.Route, data *SavedRouteData) (*routeexport.Route, error) {
+	export := &routeexport.Route{
+		Name:            route.Name,
+		Origin:          route.OriginText,
+		Destination:     route.DestinationText,
+		DistanceMeters:  route.DistanceMeters,
+		DurationSeconds: route.DurationSeconds,
+		CreatedAt:       route.CreatedAt,
+	}
+	if route.ID != uuid.Nil {
+		export.ID = route.ID.String()
+	}
+	if result := data.route(); result != nil {
+		track, err := result.Points()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode route polyline: %w", err)
+		}
+		export.Track = track
+	}
+
+	export.Waypoints = append(export.Waypoints, routeexport.Waypoint{
+		Name: route.OriginText, Kind: routeexport.WaypointOrigin, Lat: route.OriginLat, Lng: route.OriginLng,
+	})
+	var returnShop *routeexport.Waypoint
+	if plan := data.TripPlan; plan != nil {
+		export.Description = plan.Interpretation.ReturnTripPlan
+		if len(plan.ChosenStops) > 0 {
+			// Tempat singgah yang dilewati rute, sesuai urutan leg
+			for _, stop := range plan.ChosenStops {
+				waypoint, _ := topPlaceWaypoint([]places.Place{stop.Place}, routeexport.WaypointStop, stop.Query)
+				export.Waypoints = append(export.Waypoints, waypoint)
+			}
+		} else {
+			// Rencana lama tanpa pilihan: urutkan nama tempat singgah agar hasil ekspor stabil (map tidak berurutan)
+			queries := make([]string, 0, len(plan.SuggestedStops))
+			for query := range plan.SuggestedStops {
+				queries = append(queries, query)
+			}
+			sort.Strings(queries)
+			for _, query := range queries {
+				if waypoint, ok := topPlaceWaypoint(plan.SuggestedStops[query], routeexport.WaypointStop, query); ok {
+					export.Waypoints = append(export.Waypoints, waypoint)
+				}
+			}
+		}
+		returnShops := plan.ReturnTripShop
+		if plan.ChosenReturnShop != nil {
+			returnShops = []places.Place{plan.ChosenReturnShop.Place}
+		}
+		if waypoint, ok := topPlaceWaypoint(returnShops, routeexport.WaypointReturnShop, plan.Interpretation.ReturnTripPlan); ok {
+			returnShop = &waypoint
+		}
+	}
+	export.Waypoints = append(export.Waypoints, routeexport.Waypoint{
+		Name: route.DestinationText, Kind: routeexport.WaypointDestination, Lat: route.DestinationLat, Lng: route.DestinationLng,
+	})
+	if returnShop != nil {
+		export.Waypoints = append(export.Waypoints, *returnShop)
+	}
+	return export, nil
+}
+
+// topPlaceWaypoint mengubah hasil teratas pencarian tempat menjadi waypoint.
+func topPlaceWaypoint(results []places.Place, kind, query string) (routeexport.Waypoint, bool) {
+	if len(results) == 0 {
+		return routeexport.Waypoint{}, false
+	}
+	place := results[0]
+	description := place.Address
+	if query != "" {
+		description = strings.TrimSpace(query + " - " + description)
+	}
+	return routeexport.Waypoint{
+		Name:        place.Name,
+		Description: description,
+		Kind:        kind,
+		Lat:         place.Location.Lat,
+		Lng:         place.Location.Lng,
+	}, true
+}
+
+// writeRouteExport menulis rute ekspor sebagai lampiran file dalam format ?format= (default gpx).
+func writeRouteExport(c *gin.Context, export *routeexport.Route) {
+	format := strings.ToLower(c.DefaultQuery("format", routeexport.FormatGPX))
+	contentType, extension, err := routeexport.ContentType(format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var buf bytes.Buffer
+	if err := routeexport.Write(&buf, format, export); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export route: " + err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, routeexport.FileName(export.Name, extension)))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// ExportRoute mengunduh rute tersimpan sebagai GPX, KML, atau GeoJSON (?format=gpx|kml|geojson). (Public)
+// Rute public bisa diunduh siapa saja; rute private hanya oleh pemiliknya (token opsional).
+func (tc *RouteController) ExportRoute(c *gin.Context) {
+	routeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid route ID format"})
+		return
+	}
+	route, ok := tc.loadReadableRoute(c, routeID)
+	if !ok {
+		return
+	}
+
+	data, err := decodeSavedRouteData(route.RouteDataJSON)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read route data: " + err.Error()})
+		return
+	}
+	export, err := buildExportRoute(route, data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	writeRouteExport(c, export)
+}
+
+// ExportTripPlan mengekspor hasil directions atau rencana perjalanan yang belum disimpan sebagai GPX, KML,
+// atau GeoJSON (?format=gpx|kml|geojson). (Protected)
+func (tc *RouteController) ExportTripPlan(c *gin.Context) {
+	var input ExportTripPlanInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (input.Route == nil) == (input.TripPlan == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either route or trip_plan"})
+		return
+	}
+
+	data := &SavedRouteData{Source: SavedRouteSourceDirections, Route: input.Route}
+	destination := strings.TrimSpace(input.Destination)
+	if input.TripPlan != nil {
+		data = &SavedRouteData{Source: SavedRouteSourceTripPlan, TripPlan: input.TripPlan}
+		if destination == "" {
+			destination = input.TripPlan.Interpretation.Destination
+		}
+	}
+	route := models.Route{OriginText: input.Origin, DestinationText: destination, Name: strings.TrimSpace(input.Name)}
+	if route.Name == "" {
+		route.Name = input.Origin + " - " + destination
+	}
+	if !applyDirections(&route, data.route()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Directions result does not contain a route"})
+		return
+	}
+	export, err := buildExportRoute(&route, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	writeRouteExport(c, export)
+}
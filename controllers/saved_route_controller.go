@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ulyngo/directions"
+	"ulyngo/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxSavedRouteBodyBytes membatasi ukuran body SaveRoute; rencana perjalanan lengkap biasanya jauh di bawah ini.
+const maxSavedRouteBodyBytes = 2 << 20
+
+// Sumber data rute yang disimpan.
+const (
+	SavedRouteSourceDirections = "directions"
+	SavedRouteSourceTripPlan   = "trip_plan"
+)
+
+// SavedRouteData adalah isi kolom RouteDataJSON: rute hasil directions, atau rencana perjalanan lengkap
+// (interpretasi, rute utama, tempat singgah) jika rute disimpan dari PlanTripFromQuery.
+type SavedRouteData struct {
+	Source   string                 `json:"source"` // directions atau trip_plan
+	Route    *directions.Route      `json:"route,omitempty"`
+	TripPlan *FinalTripPlanResponse `json:"trip_plan,omitempty"`
+}
+
+// SaveRouteInput adalah struktur input untuk menyimpan rute. Isi route atau trip_plan dengan hasil
+// yang sudah didapat klien; jika keduanya kosong, server mengambil rute dari origin ke destination.
+type SaveRouteInput struct {
+	Name        string                 `json:"name" binding:"required,max=255"`
+	Origin      string                 `json:"origin" binding:"required,max=255"`
+	Destination string                 `json:"destination" binding:"max=255"` // Wajib kecuali trip_plan berisi tujuan
+	Route       *directions.Route      `json:"route"`
+	TripPlan    *FinalTripPlanResponse `json:"trip_plan"`
+	IsPublic    bool                   `json:"is_public"`
+}
+
+// UpdateRouteInput adalah struktur input untuk mengganti nama atau visibilitas rute. Field yang tidak dikirim tidak diubah.
+type UpdateRouteInput struct {
+	Name     *string `json:"name" binding:"omitempty,max=255"`
+	IsPublic *bool   `json:"is_public"`
+}
+
+// routeListColumns adalah kolom untuk daftar rute; route_data_json tidak ikut karena ukurannya besar.
+const routeListColumns = `routes.id, routes.name, routes.origin_text, routes.destination_text, routes.origin_lat, routes.origin_lng,
+	routes.destination_lat, routes.destination_lng, routes.distance_meters, routes.duration_seconds, routes.user_id,
+	routes.is_public, routes.created_at, routes.updated_at, users.username AS owner_username`
+
+// routeQuery menyiapkan query rute beserta username pemiliknya.
+func routeQuery(db *gorm.DB, columns string) *gorm.DB {
+	return db.Model(&models.Route{}).Select(columns).Joins("LEFT JOIN users ON users.id = routes.user_id")
+}
+
+// parsePagination membaca ?limit= (1-100, default 20) dan ?offset=. Menulis respons error sendiri jika tidak valid.
+func parsePagination(c *gin.Context) (int, int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+		return 0, 0, false
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
+
+// loadOwnRoute memuat rute :id milik pengguna saat ini. Menulis respons error sendiri jika gagal.
+func (tc *RouteController) loadOwnRoute(c *gin.Context) (*models.Route, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return nil, false
+	}
+	routeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid route ID format"})
+		return nil, false
+	}
+	var route models.Route
+	if err := routeQuery(tc.DB, "routes.*, users.username AS owner_username").
+		First(&route, "routes.id = ? AND routes.user_id = ?", routeID, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find route: " + err.Error()})
+		}
+		return nil, false
+	}
+	return &route, true
+}
+
+// loadReadableRoute memuat rute yang boleh dibaca pengguna: rute public, atau rute private milik pengguna
+// saat ini (token opsional). Rute private milik orang lain dilaporkan tidak ditemukan. Menulis respons error sendiri jika gagal.
+func (tc *RouteController) loadReadableRoute(c *gin.Context, routeID uuid.UUID) (*models.Route, bool) {
+	var route models.Route
+	if err := tc.DB.First(&route, "id = ?", routeID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch route: " + err.Error()})
+		}
+		return nil, false
+	}
+	if !route.IsPublic {
+		rawUserID, _ := c.Get("userID")
+		userIDStr, _ := rawUserID.(string)
+		if userID, err := uuid.Parse(userIDStr); err != nil || userID != route.UserID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+			return nil, false
+		}
+	}
+	return &route, true
+}
+
+// applyDirections mengisi koordinat, jarak, dan durasi rute tersimpan dari hasil directions.
+func applyDirections(route *models.Route, result *directions.Route) bool {
+	if result == nil || len(result.Legs) == 0 {
+		return false
+	}
+	origin, destination := result.Origin(), result.Destination()
+	route.OriginLat, route.OriginLng = origin.Lat, origin.Lng
+	route.DestinationLat, route.DestinationLng = destination.Lat, destination.Lng
+	route.DistanceMeters = result.DistanceMeters
+	route.DurationSeconds = result.DurationSeconds
+	return true
+}
+
+// SaveRoute menyimpan rute ke daftar rute pengguna saat ini, dari hasil directions, dari rencana
+// perjalanan, atau dengan mengambil directions baru dari origin ke destination. (Protected)
+// Rute yang dikirim klien diperiksa konsistensinya (directions.Route.Validate) karena jarak, durasi,
+// dan koordinatnya ikut ditampilkan di daftar rute public.
+func (tc *RouteController) SaveRoute(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSavedRouteBodyBytes)
+	var input SaveRouteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Route data exceeds the maximum size"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Route != nil && input.TripPlan != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either route or trip_plan, not both"})
+		return
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Route name cannot be empty"})
+		return
+	}
+
+	data := SavedRouteData{Source: SavedRouteSourceDirections, Route: input.Route}
+	destination := strings.TrimSpace(input.Destination)
+	if input.TripPlan != nil {
+		data = SavedRouteData{Source: SavedRouteSourceTripPlan, TripPlan: input.TripPlan}
+		if destination == "" {
+			destination = input.TripPlan.Interpretation.Destination
+		}
+	}
+	if destination == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "destination is required"})
+		return
+	}
+	for label, clientRoute := range data.clientRoutes() {
+		if err := clientRoute.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + label + ": " + err.Error()})
+			return
+		}
+	}
+	if data.route() == nil {
+		var travelMode TravelMode
+		if input.TripPlan != nil {
+			travelMode = input.TripPlan.Interpretation.TravelMode
+		}
+		fetched, err := tc.getDirectionsData(c.Request.Context(), travelMode.directionsRequest(input.Origin, destination))
+		if errors.Is(err, directions.ErrNoRoute) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No route found to the destination"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to get directions", "details": err.Error()})
+			return
+		}
+		data.Route = fetched
+	}
+
+	route := models.Route{
+		Name:            name,
+		OriginText:      input.Origin,
+		DestinationText: destination,
+		UserID:          userID,
+		IsPublic:        input.IsPublic,
+	}
+	if !applyDirections(&route, data.route()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Directions result does not contain a route"})
+		return
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode route data: " + err.Error()})
+		return
+	}
+	route.RouteDataJSON = raw
+
+	if err := tc.DB.Create(&route).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save route: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Route saved successfully", "route": route})
+}
+
+// GetMyRoutes mengambil rute milik pengguna saat ini, terbaru lebih dulu, tanpa data rute lengkap. (Protected)
+// Query opsional: ?limit= (1-100, default 20), ?offset=.
+func (tc *RouteController) GetMyRoutes(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	limit, offset, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	var routes []models.Route
+	if err := routeQuery(tc.DB, routeListColumns).Where("routes.user_id = ?", userID).
+		Order("routes.created_at DESC").Limit(limit).Offset(offset).Find(&routes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch routes: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, routes)
+}
+
+// GetMyRoute mengambil satu rute milik pengguna saat ini beserta data rute lengkapnya. (Protected)
+func (tc *RouteController) GetMyRoute(c *gin.Context) {
+	route, ok := tc.loadOwnRoute(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, route)
+}
+
+// UpdateRoute mengganti nama rute dan/atau menjadikannya public atau private. (Protected)
+func (tc *RouteController) UpdateRoute(c *gin.Context) {
+	route, ok := tc.loadOwnRoute(c)
+	if !ok {
+		return
+	}
+	var input UpdateRouteInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Route name cannot be empty"})
+			return
+		}
+		updates["name"] = name
+		route.Name = name
+	}
+	if input.IsPublic != nil {
+		updates["is_public"] = *input.IsPublic
+		route.IsPublic = *input.IsPublic
+	}
+	if len(updates) > 0 {
+		if err := tc.DB.Model(&models.Route{}).Where("id = ?", route.ID).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update route: " + err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Route updated successfully", "route": route})
+}
+
+// DeleteRoute menghapus (soft delete) rute milik pengguna saat ini. (Protected)
+func (tc *RouteController) DeleteRoute(c *gin.Context) {
+	route, ok := tc.loadOwnRoute(c)
+	if !ok {
+		return
+	}
+	if err := tc.DB.Delete(&models.Route{}, "id = ?", route.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete route: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Route deleted successfully"})
+}
+
+// GetPublicRoutes mengambil daftar rute public, terbaru lebih dulu, tanpa data rute lengkap. (Public)
+// Query opsional: ?user_id= untuk rute milik pengguna tertentu, ?q= untuk mencari nama, asal, atau tujuan,
+// ?limit= (1-100, default 20), ?offset=.
+func (tc *RouteController) GetPublicRoutes(c *gin.Context) {
+	limit, offset, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	query := routeQuery(tc.DB, routeListColumns).Where("routes.is_public = ?", true)
+	if ownerID := c.Query("user_id"); ownerID != "" {
+		ownerUUID, err := uuid.Parse(ownerID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+			return
+		}
+		query = query.Where("routes.user_id = ?", ownerUUID)
+	}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		query = query.Where("routes.name ILIKE ? OR routes.origin_text ILIKE ? OR routes.destination_text ILIKE ?", pattern, pattern, pattern)
+	}
+
+	var routes []models.Route
+	if err := query.Order("routes.created_at DESC").Limit(limit).Offset(offset).Find(&routes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch routes: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, routes)
+}
+
+// GetPublicRoute mengambil satu rute public beserta data rute lengkapnya. (Public)
+func (tc *RouteController) GetPublicRoute(c *gin.Context) {
+	routeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid route ID format"})
+		return
+	}
+	var route models.Route
+	if err := routeQuery(tc.DB, "routes.*, users.username AS owner_username").
+		First(&route, "routes.id = ? AND routes.is_public = ?", routeID, true).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Route not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch route: " + err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, route)
+}
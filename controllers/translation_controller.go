@@ -0,0 +1,434 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ulyngo/i18n"
+	"ulyngo/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// translationEntityTypes memetakan segmen URL :type ke jenis entitas terjemahan dan model sumbernya.
+var translationEntityTypes = map[string]struct {
+	EntityType string
+	Table      string
+}{
+	"markers":    {models.TranslationEntityMarker, "markers"},
+	"categories": {models.TranslationEntityCategory, "marker_categories"},
+	"tags":       {models.TranslationEntityTag, "marker_tags"},
+}
+
+// TranslationController menangani pengelolaan terjemahan konten oleh admin.
+type TranslationController struct {
+	DB *gorm.DB
+}
+
+// NewTranslationController adalah konstruktor untuk TranslationController.
+func NewTranslationController(db *gorm.DB) *TranslationController {
+	return &TranslationController{DB: db}
+}
+
+// requestLocaleChain menentukan rantai locale untuk respons dari ?lang= (diutamakan) atau header
+// Accept-Language, lalu mengisi header Content-Language dengan locale pertama pada rantai.
+func requestLocaleChain(c *gin.Context) []string {
+	requested := i18n.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if lang := c.Query("lang"); lang != "" {
+		requested = append([]string{lang}, requested...)
+	}
+	chain := i18n.FallbackChain(requested)
+	c.Header("Content-Language", chain[0])
+	c.Header("Vary", "Accept-Language")
+	return chain
+}
+
+// translationTarget menghubungkan satu entitas dengan field-field yang akan diisi terjemahannya.
+type translationTarget struct {
+	EntityType string
+	ID         uuid.UUID
+	Setters    map[string]func(string) // Hanya field yang memiliki konten asli
+	Locale     *string
+	Missing    *[]string
+}
+
+func markerTranslationTargets(marker *models.Marker) []translationTarget {
+	setters := map[string]func(string){"name": func(v string) { marker.Name = v }}
+	if marker.Description != nil && *marker.Description != "" {
+		setters["description"] = func(v string) { marker.Description = &v }
+	}
+	targets := []translationTarget{{models.TranslationEntityMarker, marker.ID, setters, &marker.Locale, &marker.MissingTranslations}}
+	if marker.Category.ID != uuid.Nil {
+		targets = append(targets, categoryTranslationTarget(&marker.Category))
+	}
+	for i := range marker.Tags {
+		targets = append(targets, tagTranslationTarget(&marker.Tags[i]))
+	}
+	return targets
+}
+
+func categoryTranslationTarget(category *models.MarkerCategory) translationTarget {
+	setters := map[string]func(string){"name": func(v string) { category.Name = v }}
+	if category.Description != nil && *category.Description != "" {
+		setters["description"] = func(v string) { category.Description = &v }
+	}
+	return translationTarget{models.TranslationEntityCategory, category.ID, setters, &category.Locale, &category.MissingTranslations}
+}
+
+func tagTranslationTarget(tag *models.MarkerTag) translationTarget {
+	setters := map[string]func(string){"name": func(v string) { tag.Name = v }}
+	return translationTarget{models.TranslationEntityTag, tag.ID, setters, &tag.Locale, &tag.MissingTranslations}
+}
+
+// localizeMarkers menerjemahkan marker (beserta kategori dan tag yang dimuat) sesuai rantai locale.
+func localizeMarkers(db *gorm.DB, chain []string, markers []models.Marker) error {
+	targets := []translationTarget{}
+	for i := range markers {
+		targets = append(targets, markerTranslationTargets(&markers[i])...)
+	}
+	return applyTranslations(db, chain, targets)
+}
+
+// localizeCategories menerjemahkan kategori sesuai rantai locale.
+func localizeCategories(db *gorm.DB, chain []string, categories []models.MarkerCategory) error {
+	targets := make([]translationTarget, len(categories))
+	for i := range categories {
+		targets[i] = categoryTranslationTarget(&categories[i])
+	}
+	return applyTranslations(db, chain, targets)
+}
+
+// localizeTags menerjemahkan tag sesuai rantai locale.
+func localizeTags(db *gorm.DB, chain []string, tags []models.MarkerTag) error {
+	targets := make([]translationTarget, len(tags))
+	for i := range tags {
+		targets[i] = tagTranslationTarget(&tags[i])
+	}
+	return applyTranslations(db, chain, targets)
+}
+
+// applyTranslations mengisi setiap field dengan terjemahan dari locale pertama pada rantai yang tersedia.
+// Field yang tidak memiliki terjemahan di locale mana pun sebelum DefaultLocale tetap memakai konten asli
+// dan dicatat pada MissingTranslations. Untuk permintaan berbahasa default tidak ada query yang dijalankan.
+func applyTranslations(db *gorm.DB, chain []string, targets []translationTarget) error {
+	for _, target := range targets {
+		*target.Locale = chain[0]
+	}
+	if chain[0] == i18n.DefaultLocale || len(targets) == 0 {
+		return nil
+	}
+
+	locales := chain[:len(chain)-1] // DefaultLocale (di akhir rantai) adalah konten asli
+	ids := make([]uuid.UUID, 0, len(targets))
+	for _, target := range targets {
+		ids = append(ids, target.ID)
+	}
+	var translations []models.Translation
+	if err := db.Where("entity_id IN ? AND locale IN ?", ids, locales).Find(&translations).Error; err != nil {
+		return err
+	}
+
+	// values[entityType|entityID|field][locale] = terjemahan
+	values := map[string]map[string]string{}
+	for _, translation := range translations {
+		key := translation.EntityType + "|" + translation.EntityID.String() + "|" + translation.Field
+		if values[key] == nil {
+			values[key] = map[string]string{}
+		}
+		values[key][translation.Locale] = translation.Value
+	}
+
+	for _, target := range targets {
+		missing := []string{}
+		for _, field := range models.TranslatableFields[target.EntityType] {
+			set, ok := target.Setters[field]
+			if !ok {
+				continue
+			}
+			byLocale := values[target.EntityType+"|"+target.ID.String()+"|"+field]
+			found := false
+			for _, locale := range locales {
+				if value, ok := byLocale[locale]; ok {
+					set(value)
+					found = true
+					break
+				}
+			}
+			if !found {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			*target.Missing = missing
+		}
+	}
+	return nil
+}
+
+// loadTranslationSource memastikan entitas :type/:id ada dan mengembalikan jenis entitas serta konten aslinya.
+// Menulis respons error sendiri jika gagal.
+func (tc *TranslationController) loadTranslationSource(c *gin.Context) (string, uuid.UUID, map[string]*string, bool) {
+	entity, ok := translationEntityTypes[c.Param("type")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of markers, categories, tags"})
+		return "", uuid.Nil, nil, false
+	}
+	entityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return "", uuid.Nil, nil, false
+	}
+
+	fields := models.TranslatableFields[entity.EntityType]
+	row := map[string]interface{}{}
+	result := tc.DB.Table(entity.Table).Select(fields).Where("id = ? AND deleted_at IS NULL", entityID).Take(&row)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Entity not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find entity: " + result.Error.Error()})
+		}
+		return "", uuid.Nil, nil, false
+	}
+	source := make(map[string]*string, len(fields))
+	for _, field := range fields {
+		if value, ok := row[field].(string); ok {
+			source[field] = &value
+		} else {
+			source[field] = nil
+		}
+	}
+	return entity.EntityType, entityID, source, true
+}
+
+// GetEntityTranslations mengambil konten asli, semua terjemahan, dan field yang belum diterjemahkan
+// per locale yang didukung untuk satu entitas. (Admin Protected)
+func (tc *TranslationController) GetEntityTranslations(c *gin.Context) {
+	entityType, entityID, source, ok := tc.loadTranslationSource(c)
+	if !ok {
+		return
+	}
+	var translations []models.Translation
+	if err := tc.DB.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Order("locale, field").Find(&translations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch translations: " + err.Error()})
+		return
+	}
+
+	byLocale := map[string]map[string]string{}
+	for _, translation := range translations {
+		if byLocale[translation.Locale] == nil {
+			byLocale[translation.Locale] = map[string]string{}
+		}
+		byLocale[translation.Locale][translation.Field] = translation.Value
+	}
+	missing := map[string][]string{}
+	for _, locale := range i18n.SupportedLocales() {
+		if locale == i18n.DefaultLocale {
+			continue
+		}
+		fields := []string{}
+		for _, field := range models.TranslatableFields[entityType] {
+			if source[field] == nil || *source[field] == "" {
+				continue
+			}
+			if _, ok := byLocale[locale][field]; !ok {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) > 0 {
+			missing[locale] = fields
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entity_type":       entityType,
+		"entity_id":         entityID,
+		"source_locale":     i18n.DefaultLocale,
+		"source":            source,
+		"translations":      byLocale,
+		"missing":           missing,
+		"supported_locales": i18n.SupportedLocales(),
+	})
+}
+
+// UpsertEntityTranslations menyimpan terjemahan satu entitas untuk :locale. (Admin Protected)
+// Body berupa objek field -> nilai, misal {"name": "Beach", "description": "..."}. Nilai null atau
+// string kosong menghapus terjemahan field tersebut; field yang tidak dikirim tidak diubah.
+func (tc *TranslationController) UpsertEntityTranslations(c *gin.Context) {
+	entityType, entityID, _, ok := tc.loadTranslationSource(c)
+	if !ok {
+		return
+	}
+	locale := i18n.Normalize(c.Param("locale"))
+	if !i18n.IsSupported(locale) || locale == i18n.DefaultLocale {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "locale must be a supported non-default locale", "supported_locales": i18n.SupportedLocales()})
+		return
+	}
+	var input map[string]*string
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	allowed := map[string]bool{}
+	for _, field := range models.TranslatableFields[entityType] {
+		allowed[field] = true
+	}
+	for field := range input {
+		if !allowed[field] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Field " + field + " cannot be translated", "fields": models.TranslatableFields[entityType]})
+			return
+		}
+	}
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		for field, value := range input {
+			if value == nil || strings.TrimSpace(*value) == "" {
+				if err := tx.Where("entity_type = ? AND entity_id = ? AND field = ? AND locale = ?", entityType, entityID, field, locale).
+					Delete(&models.Translation{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			translation := models.Translation{
+				EntityType:      entityType,
+				EntityID:        entityID,
+				Field:           field,
+				Locale:          locale,
+				Value:           strings.TrimSpace(*value),
+				UpdatedByUserID: &adminID,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}, {Name: "field"}, {Name: "locale"}},
+				DoUpdates: clause.AssignmentColumns([]string{"value", "updated_by_user_id", "updated_at"}),
+			}).Create(&translation).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save translations: " + err.Error()})
+		return
+	}
+	tc.GetEntityTranslations(c)
+}
+
+// DeleteEntityTranslations menghapus semua terjemahan satu entitas untuk :locale. (Admin Protected)
+func (tc *TranslationController) DeleteEntityTranslations(c *gin.Context) {
+	entityType, entityID, _, ok := tc.loadTranslationSource(c)
+	if !ok {
+		return
+	}
+	result := tc.DB.Where("entity_type = ? AND entity_id = ? AND locale = ?", entityType, entityID, i18n.Normalize(c.Param("locale"))).
+		Delete(&models.Translation{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete translations: " + result.Error.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Translations deleted", "deleted": result.RowsAffected})
+}
+
+// MissingTranslationItem adalah satu entitas yang masih memiliki field belum diterjemahkan.
+type MissingTranslationItem struct {
+	EntityType    string    `json:"entity_type"`
+	EntityID      uuid.UUID `json:"entity_id"`
+	Name          string    `json:"name"`
+	MissingFields []string  `json:"missing_fields"`
+}
+
+// GetMissingTranslations melaporkan entitas yang belum lengkap terjemahannya untuk ?locale=. (Admin Protected)
+// Query: ?locale= (wajib), ?type= (markers, categories, tags; default semua), ?limit= (1-500, default 100).
+// Untuk marker, hanya marker yang sudah disetujui yang dilaporkan.
+func (tc *TranslationController) GetMissingTranslations(c *gin.Context) {
+	locale := i18n.Normalize(c.Query("locale"))
+	if !i18n.IsSupported(locale) || locale == i18n.DefaultLocale {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "locale must be a supported non-default locale", "supported_locales": i18n.SupportedLocales()})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+		return
+	}
+	typeNames := []string{"markers", "categories", "tags"}
+	if v := c.Query("type"); v != "" {
+		if _, ok := translationEntityTypes[v]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of markers, categories, tags"})
+			return
+		}
+		typeNames = []string{v}
+	}
+
+	items := []MissingTranslationItem{}
+	totals := map[string]int{}
+	for _, typeName := range typeNames {
+		entity := translationEntityTypes[typeName]
+		fields := models.TranslatableFields[entity.EntityType]
+
+		// Kondisi "field berisi konten asli tetapi belum ada terjemahannya" untuk setiap field
+		missingConditions := make([]string, len(fields))
+		missingArgs := []interface{}{}
+		for i, field := range fields {
+			missingConditions[i] = "(e." + field + " IS NOT NULL AND e." + field + ` <> '' AND NOT EXISTS (SELECT 1 FROM translations t
+				WHERE t.entity_type = ? AND t.entity_id = e.id AND t.field = ? AND t.locale = ?))`
+			missingArgs = append(missingArgs, entity.EntityType, field, locale)
+		}
+		base := func() *gorm.DB {
+			query := tc.DB.Table(entity.Table + " AS e").Where("e.deleted_at IS NULL")
+			if entity.EntityType == models.TranslationEntityMarker {
+				query = query.Where("e.status = ?", models.MarkerStatusApproved)
+			}
+			return query
+		}
+
+		for i, field := range fields {
+			var count int64
+			if err := base().Where(missingConditions[i], missingArgs[i*3:i*3+3]...).Count(&count).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count missing translations: " + err.Error()})
+				return
+			}
+			totals[typeName+"."+field] = int(count)
+		}
+		if len(items) >= limit {
+			continue
+		}
+
+		// Daftar entitas beserta field yang belum diterjemahkan, dibatasi limit
+		selectColumns := []string{"e.id", "e.name", "FALSE AS missing_name", "FALSE AS missing_description"}
+		for i, field := range fields {
+			selectColumns[2+i] = missingConditions[i] + " AS missing_" + field
+		}
+		var rows []struct {
+			ID                 uuid.UUID
+			Name               string
+			MissingName        bool
+			MissingDescription bool
+		}
+		if err := base().Select(strings.Join(selectColumns, ", "), missingArgs...).
+			Where(strings.Join(missingConditions, " OR "), missingArgs...).
+			Order("e.name").Limit(limit - len(items)).Scan(&rows).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch missing translations: " + err.Error()})
+			return
+		}
+		for _, row := range rows {
+			item := MissingTranslationItem{EntityType: entity.EntityType, EntityID: row.ID, Name: row.Name, MissingFields: []string{}}
+			if row.MissingName {
+				item.MissingFields = append(item.MissingFields, "name")
+			}
+			if row.MissingDescription {
+				item.MissingFields = append(item.MissingFields, "description")
+			}
+			items = append(items, item)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"locale": locale, "totals": totals, "items": items})
+}
@@ -0,0 +1,487 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ulyngo/blobstore"
+	"ulyngo/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Jenis item yang dapat dikelola melalui trash.
+const (
+	trashTypeMarkers    = "markers"
+	trashTypeCategories = "categories"
+	trashTypeTags       = "tags"
+)
+
+// TrashController menangani daftar, pemulihan, dan penghapusan permanen item yang sudah di-soft delete.
+type TrashController struct {
+	DB        *gorm.DB
+	Store     blobstore.BlobStore // Penyimpanan file gambar yang ikut dihapus saat purge
+	Retention time.Duration       // Lama item disimpan di trash sebelum boleh di-purge
+}
+
+// NewTrashController adalah konstruktor untuk TrashController.
+func NewTrashController(db *gorm.DB, store blobstore.BlobStore, retention time.Duration) *TrashController {
+	return &TrashController{DB: db, Store: store, Retention: retention}
+}
+
+// PurgeResult merangkum jumlah item yang dihapus permanen oleh satu kali purge.
+type PurgeResult struct {
+	Markers           int64 `json:"markers"`
+	Categories        int64 `json:"categories"`
+	Tags              int64 `json:"tags"`
+	Images            int64 `json:"images"`
+	SkippedCategories int64 `json:"skipped_categories"` // Kategori yang masih dirujuk marker atau subkategori sehingga tidak di-purge
+}
+
+// trashTagLinks memindahkan tautan marker_has_tags milik marker/tag yang dihapus ke tabel arsip.
+// column bernilai "marker_id" atau "tag_id".
+func trashTagLinks(tx *gorm.DB, column string, id uuid.UUID) error {
+	if err := tx.Exec(`INSERT INTO marker_has_tag_trashes (marker_id, tag_id, created_at, trashed_at)
+		SELECT marker_id, tag_id, created_at, ? FROM marker_has_tags WHERE `+column+` = ?
+		ON CONFLICT DO NOTHING`, time.Now(), id).Error; err != nil {
+		return err
+	}
+	return tx.Where(column+" = ?", id).Delete(&models.MarkerHasTag{}).Error
+}
+
+// restoreTagLinks mengembalikan tautan arsip milik marker/tag yang dipulihkan, tetapi hanya jika
+// sisi lainnya juga sudah aktif. Tautan yang sisi lainnya masih di trash tetap diarsipkan.
+func restoreTagLinks(tx *gorm.DB, column string, id uuid.UUID) error {
+	activeLinks := `FROM marker_has_tag_trashes t
+		JOIN markers m ON m.id = t.marker_id AND m.deleted_at IS NULL
+		JOIN marker_tags g ON g.id = t.tag_id AND g.deleted_at IS NULL
+		WHERE t.` + column + ` = ?`
+	if err := tx.Exec(`INSERT INTO marker_has_tags (marker_id, tag_id, created_at)
+		SELECT t.marker_id, t.tag_id, t.created_at `+activeLinks+`
+		ON CONFLICT DO NOTHING`, id).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`DELETE FROM marker_has_tag_trashes WHERE (marker_id, tag_id) IN (
+		SELECT t.marker_id, t.tag_id `+activeLinks+`)`, id).Error
+}
+
+// ListTrash menampilkan item di trash untuk jenis :type (markers, categories, tags). (Admin Protected)
+func (tc *TrashController) ListTrash(c *gin.Context) {
+	var items interface{}
+	var err error
+
+	switch c.Param("type") {
+	case trashTypeMarkers:
+		var markers []models.Marker
+		err = tc.DB.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&markers).Error
+		items = markers
+	case trashTypeCategories:
+		var categories []models.MarkerCategory
+		err = tc.DB.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&categories).Error
+		// Marker aktif yang masih merujuk ke kategori yang dihapus (orphan)
+		orphans := make(map[uuid.UUID]int64)
+		if err == nil && len(categories) > 0 {
+			var rows []struct {
+				CategoryID uuid.UUID
+				Total      int64
+			}
+			err = tc.DB.Model(&models.Marker{}).Select("category_id, COUNT(*) AS total").
+				Where("category_id IN (?)", tc.DB.Unscoped().Model(&models.MarkerCategory{}).Select("id").Where("deleted_at IS NOT NULL")).
+				Group("category_id").Scan(&rows).Error
+			for _, row := range rows {
+				orphans[row.CategoryID] = row.Total
+			}
+		}
+		entries := make([]gin.H, 0, len(categories))
+		for _, category := range categories {
+			entries = append(entries, gin.H{"category": category, "orphaned_marker_count": orphans[category.ID]})
+		}
+		items = entries
+	case trashTypeTags:
+		var tags []models.MarkerTag
+		err = tc.DB.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&tags).Error
+		items = tags
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown trash type. Use markers, categories or tags"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"retention_days": int(tc.Retention.Hours() / 24),
+		"items":          items,
+	})
+}
+
+// RestoreFromTrash memulihkan item :id dari trash jenis :type. (Admin Protected)
+// Tautan MarkerHasTag yang diarsipkan ikut dipulihkan.
+func (tc *TrashController) RestoreFromTrash(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	adminID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	switch c.Param("type") {
+	case trashTypeMarkers:
+		tc.restoreMarker(c, id, adminID)
+	case trashTypeCategories:
+		var category models.MarkerCategory
+		if !tc.findTrashed(c, &category, id) {
+			return
+		}
+		// Kategori tidak boleh hidup di bawah induk yang masih di trash, karena path-nya menunjuk ke induk tersebut
+		if category.ParentID != nil {
+			var parentCount int64
+			if err := tc.DB.Model(&models.MarkerCategory{}).Where("id = ?", *category.ParentID).Count(&parentCount).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check parent category: " + err.Error()})
+				return
+			}
+			if parentCount == 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "Parent category is in the trash. Restore the parent category first", "parent_id": category.ParentID})
+				return
+			}
+		}
+		if err := tc.DB.Unscoped().Model(&category).Update("deleted_at", nil).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore category: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Category restored successfully"})
+	case trashTypeTags:
+		var tag models.MarkerTag
+		if !tc.findTrashed(c, &tag, id) {
+			return
+		}
+		err := tc.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Model(&tag).Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+			return restoreTagLinks(tx, "tag_id", id)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore tag: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Tag restored successfully"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown trash type. Use markers, categories or tags"})
+	}
+}
+
+// restoreMarker memulihkan marker beserta tautan tag-nya dan mencatatnya sebagai revisi.
+func (tc *TrashController) restoreMarker(c *gin.Context, id, adminID uuid.UUID) {
+	var marker models.Marker
+	if !tc.findTrashed(c, &marker, id) {
+		return
+	}
+
+	// Marker hasil merge sudah digantikan marker lain; pemulihannya akan memunculkan duplikat kembali
+	var redirectCount int64
+	if err := tc.DB.Model(&models.MarkerRedirect{}).Where("from_marker_id = ?", id).Count(&redirectCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check marker redirects: " + err.Error()})
+		return
+	}
+	if redirectCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Marker was merged into another marker and cannot be restored"})
+		return
+	}
+
+	var categoryCount int64
+	if err := tc.DB.Model(&models.MarkerCategory{}).Where("id = ?", marker.CategoryID).Count(&categoryCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check marker category: " + err.Error()})
+		return
+	}
+	if categoryCount == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Marker category is in the trash. Restore the category first", "category_id": marker.CategoryID})
+		return
+	}
+
+	err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		before, err := loadMarkerSnapshot(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&marker).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := restoreTagLinks(tx, "marker_id", id); err != nil {
+			return err
+		}
+		after, err := loadMarkerSnapshot(tx, id)
+		if err != nil {
+			return err
+		}
+		_, err = recordMarkerRevision(tx, id, models.RevisionActionRestore, adminID, before, after, nil)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore marker: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Marker restored successfully"})
+}
+
+// findTrashed memuat item yang sudah di-soft delete ke dest. Mengirim 404 jika item tidak ada di trash.
+func (tc *TrashController) findTrashed(c *gin.Context, dest interface{}, id uuid.UUID) bool {
+	if err := tc.DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(dest).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found in trash"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find item: " + err.Error()})
+		}
+		return false
+	}
+	return true
+}
+
+// PurgeTrashItem menghapus permanen satu item dari trash, tanpa menunggu masa retensi. (Admin Protected)
+func (tc *TrashController) PurgeTrashItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	switch c.Param("type") {
+	case trashTypeMarkers:
+		var marker models.Marker
+		if !tc.findTrashed(c, &marker, id) {
+			return
+		}
+		err = tc.purgeMarkers([]uuid.UUID{id})
+	case trashTypeCategories:
+		var category models.MarkerCategory
+		if !tc.findTrashed(c, &category, id) {
+			return
+		}
+		var purged int64
+		purged, _, err = purgeCategories(tc.DB, []uuid.UUID{id})
+		if err == nil && purged == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Category is still referenced by markers or subcategories and cannot be purged"})
+			return
+		}
+	case trashTypeTags:
+		var tag models.MarkerTag
+		if !tc.findTrashed(c, &tag, id) {
+			return
+		}
+		err = tc.DB.Transaction(func(tx *gorm.DB) error { return purgeTags(tx, []uuid.UUID{id}) })
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown trash type. Use markers, categories or tags"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge item: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Item permanently deleted"})
+}
+
+// PurgeTrash menghapus permanen semua item yang sudah berada di trash lebih lama dari masa retensi. (Admin Protected)
+// Query opsional ?older_than_days= untuk menimpa masa retensi default.
+func (tc *TrashController) PurgeTrash(c *gin.Context) {
+	retention := tc.Retention
+	if v := c.Query("older_than_days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "older_than_days must be a non-negative integer"})
+			return
+		}
+		retention = time.Duration(days) * 24 * time.Hour
+	}
+
+	result, err := tc.purgeOlderThan(time.Now().Add(-retention))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge trash: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Trash purged successfully", "purged": result})
+}
+
+// StartPurgeJob menjalankan purge terjadwal di background setiap interval,
+// memakai logika yang sama dengan endpoint PurgeTrash.
+func (tc *TrashController) StartPurgeJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			result, err := tc.purgeOlderThan(time.Now().Add(-tc.Retention))
+			if err != nil {
+				log.Printf("Scheduled trash purge failed: %v", err)
+				continue
+			}
+			log.Printf("Scheduled trash purge completed: %d markers, %d images, %d categories, %d tags (%d categories skipped)",
+				result.Markers, result.Images, result.Categories, result.Tags, result.SkippedCategories)
+		}
+	}()
+}
+
+// purgeOlderThan menghapus permanen item trash yang dihapus sebelum cutoff.
+func (tc *TrashController) purgeOlderThan(cutoff time.Time) (*PurgeResult, error) {
+	result := &PurgeResult{}
+
+	trashedIDs := func(model interface{}) ([]uuid.UUID, error) {
+		var ids []uuid.UUID
+		err := tc.DB.Unscoped().Model(model).Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Pluck("id", &ids).Error
+		return ids, err
+	}
+
+	markerIDs, err := trashedIDs(&models.Marker{})
+	if err != nil {
+		return nil, err
+	}
+	if len(markerIDs) > 0 {
+		if err := tc.purgeMarkers(markerIDs); err != nil {
+			return nil, err
+		}
+		result.Markers = int64(len(markerIDs))
+	}
+
+	// Gambar yang dihapus satu per satu dari marker yang masih aktif
+	var images []models.MarkerImage
+	if err := tc.DB.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Find(&images).Error; err != nil {
+		return nil, err
+	}
+	if len(images) > 0 {
+		imageIDs := make([]uuid.UUID, len(images))
+		for i, img := range images {
+			imageIDs[i] = img.ID
+		}
+		if err := tc.DB.Unscoped().Where("id IN ?", imageIDs).Delete(&models.MarkerImage{}).Error; err != nil {
+			return nil, err
+		}
+		deleteBlobs(tc.Store, imageBlobKeys(images)...)
+		result.Images = int64(len(images))
+	}
+
+	tagIDs, err := trashedIDs(&models.MarkerTag{})
+	if err != nil {
+		return nil, err
+	}
+	if len(tagIDs) > 0 {
+		if err := tc.DB.Transaction(func(tx *gorm.DB) error { return purgeTags(tx, tagIDs) }); err != nil {
+			return nil, err
+		}
+		result.Tags = int64(len(tagIDs))
+	}
+
+	// Kategori di-purge terakhir agar marker yang baru saja di-purge tidak lagi merujuknya
+	categoryIDs, err := trashedIDs(&models.MarkerCategory{})
+	if err != nil {
+		return nil, err
+	}
+	if len(categoryIDs) > 0 {
+		purged, skipped, err := purgeCategories(tc.DB, categoryIDs)
+		if err != nil {
+			return nil, err
+		}
+		result.Categories, result.SkippedCategories = purged, skipped
+	}
+	return result, nil
+}
+
+// purgeMarkers menghapus permanen marker di dalam satu transaksi, lalu menghapus file gambarnya
+// dari BlobStore setelah transaksi berhasil.
+func (tc *TrashController) purgeMarkers(ids []uuid.UUID) error {
+	var images []models.MarkerImage
+	err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("marker_id IN ?", ids).Find(&images).Error; err != nil {
+			return err
+		}
+		return purgeMarkerRows(tx, ids)
+	})
+	if err != nil {
+		return err
+	}
+	deleteBlobs(tc.Store, imageBlobKeys(images)...)
+	return nil
+}
+
+// imageBlobKeys mengumpulkan key blob gambar asli dan thumbnail dari daftar gambar.
+func imageBlobKeys(images []models.MarkerImage) []string {
+	keys := make([]string, 0, len(images)*2)
+	for _, img := range images {
+		keys = append(keys, img.StorageKey, img.ThumbnailKey)
+	}
+	return keys
+}
+
+// purgeMarkerRows menghapus permanen baris marker beserta data yang bergantung padanya.
+// Redirect dari marker hasil merge dipertahankan agar ID lama tetap diarahkan ke marker yang bertahan.
+func purgeMarkerRows(tx *gorm.DB, ids []uuid.UUID) error {
+	steps := []struct {
+		model interface{}
+		where string
+	}{
+		{&models.MarkerHasTag{}, "marker_id IN ?"},
+		{&models.MarkerHasTagTrash{}, "marker_id IN ?"},
+		{&models.MarkerImage{}, "marker_id IN ?"},
+		{&models.ReviewVote{}, "review_id IN (SELECT id FROM marker_reviews WHERE marker_id IN ?)"},
+		{&models.ReviewReply{}, "review_id IN (SELECT id FROM marker_reviews WHERE marker_id IN ?)"},
+		{&models.ReviewReport{}, "review_id IN (SELECT id FROM marker_reviews WHERE marker_id IN ?)"},
+		{&models.MarkerReview{}, "marker_id IN ?"},
+		{&models.MarkerRevision{}, "marker_id IN ?"},
+		{&models.Favorite{}, "marker_id IN ?"},
+		{&models.CollectionItem{}, "marker_id IN ?"},
+		{&models.MarkerRanking{}, "marker_id IN ?"},
+		{&models.Event{}, "marker_id IN ?"},
+		{&models.MarkerRedirect{}, "to_marker_id IN ?"},
+		{&models.Translation{}, "entity_type = '" + models.TranslationEntityMarker + "' AND entity_id IN ?"},
+		{&models.Marker{}, "id IN ?"},
+	}
+	for _, step := range steps {
+		if err := tx.Unscoped().Where(step.where, ids).Delete(step.model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeTags menghapus permanen tag beserta tautan (aktif maupun arsip) ke marker.
+func purgeTags(tx *gorm.DB, ids []uuid.UUID) error {
+	if err := tx.Where("tag_id IN ?", ids).Delete(&models.MarkerHasTag{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("tag_id IN ?", ids).Delete(&models.MarkerHasTagTrash{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("entity_type = ? AND entity_id IN ?", models.TranslationEntityTag, ids).Delete(&models.Translation{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("tag_id IN ?", ids).Delete(&models.MarkerTagAlias{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&models.MarkerTag{}).Error
+}
+
+// purgeCategories menghapus permanen kategori yang tidak lagi dirujuk marker mana pun (termasuk marker di trash)
+// dan tidak memiliki subkategori.
+// Mengembalikan jumlah kategori yang di-purge dan yang dilewati.
+func purgeCategories(db *gorm.DB, ids []uuid.UUID) (int64, int64, error) {
+	result := db.Unscoped().
+		Where("id IN ?", ids).
+		Where("NOT EXISTS (SELECT 1 FROM markers WHERE markers.category_id = marker_categories.id)").
+		Where("NOT EXISTS (SELECT 1 FROM marker_categories child WHERE child.parent_id = marker_categories.id)").
+		Delete(&models.MarkerCategory{})
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+	// Terjemahan hanya dihapus untuk kategori yang benar-benar ter-purge
+	if err := db.Where("entity_type = ? AND entity_id IN ?", models.TranslationEntityCategory, ids).
+		Where("NOT EXISTS (SELECT 1 FROM marker_categories WHERE marker_categories.id = translations.entity_id)").
+		Delete(&models.Translation{}).Error; err != nil {
+		return result.RowsAffected, int64(len(ids)) - result.RowsAffected, err
+	}
+	return result.RowsAffected, int64(len(ids)) - result.RowsAffected, nil
+}
@@ -0,0 +1,346 @@
+package controllers
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ulyngo/models"
+	"ulyngo/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// trendingWindow mendefinisikan rentang data dan waktu paruh peluruhan untuk satu jendela trending.
+type trendingWindow struct {
+	Span     time.Duration // Aktivitas lebih lama dari ini tidak dihitung
+	HalfLife time.Duration // Bobot aktivitas menjadi setengah setiap HalfLife
+}
+
+var trendingWindows = map[string]trendingWindow{
+	models.RankingWindow24h: {Span: 24 * time.Hour, HalfLife: 6 * time.Hour},
+	models.RankingWindow7d:  {Span: 7 * 24 * time.Hour, HalfLife: 2 * 24 * time.Hour},
+	models.RankingWindow30d: {Span: 30 * 24 * time.Hour, HalfLife: 7 * 24 * time.Hour},
+}
+
+const (
+	// trendingReviewWeight adalah bobot satu ulasan baru dibanding satu view.
+	trendingReviewWeight = 5.0
+	// trendingFavoriteWeight adalah bobot satu favorit baru dibanding satu view.
+	trendingFavoriteWeight = 3.0
+	// bayesianPriorReviews adalah jumlah "ulasan semu" bernilai rata-rata global yang ditambahkan ke setiap
+	// marker, agar marker dengan satu ulasan bintang lima tidak langsung mengalahkan marker yang sudah teruji.
+	bayesianPriorReviews = 5.0
+	// defaultGlobalRating dipakai sebagai rata-rata global saat belum ada ulasan sama sekali.
+	defaultGlobalRating = 3.5
+	// defaultTrendingRadius adalah radius default (meter) untuk filter ?near=.
+	defaultTrendingRadius = 10000.0
+	// rankingLockKey adalah kunci pg_advisory_xact_lock untuk perhitungan ulang peringkat.
+	rankingLockKey = 7_302_114
+)
+
+// TrendingController menghitung dan menyajikan peringkat marker trending.
+type TrendingController struct {
+	DB *gorm.DB
+}
+
+// NewTrendingController adalah konstruktor untuk TrendingController.
+func NewTrendingController(db *gorm.DB) *TrendingController {
+	return &TrendingController{DB: db}
+}
+
+// TrendingMarker adalah satu marker pada daftar trending beserta komponen skornya.
+type TrendingMarker struct {
+	Rank           int           `json:"rank"`
+	Score          float64       `json:"score"`
+	Views          int64         `json:"views"`
+	Reviews        int           `json:"reviews"`
+	BayesianRating float64       `json:"bayesian_rating"`
+	DistanceMeters *float64      `json:"distance_meters,omitempty"` // Hanya diisi jika ?near= dikirim
+	Marker         models.Marker `json:"marker"`
+}
+
+// StartRankingJob menghitung peringkat saat server mulai lalu mengulanginya setiap interval di background.
+func (tc *TrendingController) StartRankingJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for ; true; <-ticker.C {
+			if err := tc.RecomputeRankings(time.Now()); err != nil {
+				log.Printf("Trending ranking job failed: %v", err)
+			}
+		}
+	}()
+}
+
+// RecomputeRankings menghitung ulang tabel marker_rankings untuk semua jendela waktu.
+// Seluruh perhitungan berjalan dalam satu transaksi yang memegang advisory lock, sehingga job berkala dan
+// endpoint admin (juga dari instance server lain) tidak saling menimpa; pemanggil kedua menunggu yang pertama.
+func (tc *TrendingController) RecomputeRankings(now time.Time) error {
+	return tc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", rankingLockKey).Error; err != nil {
+			return err
+		}
+		prior, err := globalAverageRating(tx)
+		if err != nil {
+			return err
+		}
+
+		for name, window := range trendingWindows {
+			rankings, err := computeWindow(tx, now, name, window, prior)
+			if err != nil {
+				return err
+			}
+			if err := tx.Where("ranking_window = ?", name).Delete(&models.MarkerRanking{}).Error; err != nil {
+				return err
+			}
+			if len(rankings) == 0 {
+				continue
+			}
+			if err := tx.Omit("Marker").CreateInBatches(rankings, 500).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// globalAverageRating mengembalikan rata-rata rating seluruh ulasan yang tampil, atau defaultGlobalRating
+// jika belum ada ulasan. Nilai ini menjadi prior rating Bayesian.
+func globalAverageRating(db *gorm.DB) (float64, error) {
+	var globalRating struct{ Avg *float64 }
+	if err := db.Model(&models.MarkerReview{}).Where("NOT is_hidden").
+		Select("AVG(rating) AS avg").Scan(&globalRating).Error; err != nil {
+		return 0, err
+	}
+	if globalRating.Avg == nil {
+		return defaultGlobalRating, nil
+	}
+	return *globalRating.Avg, nil
+}
+
+// bayesianRating menarik rata-rata rating marker ke arah prior sebanyak bayesianPriorReviews ulasan semu.
+func bayesianRating(prior, avgRating float64, totalReviews int) float64 {
+	n := float64(totalReviews)
+	return (bayesianPriorReviews*prior + avgRating*n) / (bayesianPriorReviews + n)
+}
+
+// bayesianRatingOrder adalah ekspresi ORDER BY untuk mengurutkan marker dari rating Bayesian tertinggi.
+func bayesianRatingOrder(prior float64) clause.OrderBy {
+	return clause.OrderBy{Expression: gorm.Expr("(? + avg_rating * total_reviews) / (? + total_reviews) DESC, total_reviews DESC",
+		bayesianPriorReviews*prior, bayesianPriorReviews)}
+}
+
+// computeWindow menghitung skor trending satu jendela waktu:
+//
+//	score = (aktivitas + trendingReviewWeight × ulasan baru) × (0.5 + bayesianRating / 5)
+//
+// Aktivitas dan ulasan diberi bobot peluruhan eksponensial berdasarkan umurnya, dan view berulang
+// dari pengguna yang sama dalam satu jam dihitung sekali. Faktor rating berkisar 0.7 sampai 1.5.
+func computeWindow(db *gorm.DB, now time.Time, name string, window trendingWindow, prior float64) ([]models.MarkerRanking, error) {
+	since := now.Add(-window.Span)
+	halfLife := window.HalfLife.Seconds()
+
+	var activity []struct {
+		MarkerID uuid.UUID
+		Score    float64
+		Views    int64
+	}
+	if err := db.Raw(`SELECT target_id AS marker_id,
+			SUM(CASE WHEN activity_type = 'favorite_marker' THEN ? ELSE 1 END
+				* EXP(-LN(2) * EXTRACT(EPOCH FROM (?::timestamptz - bucket)) / ?)) AS score,
+			COUNT(*) FILTER (WHERE activity_type = 'view_marker') AS views
+		FROM (SELECT DISTINCT user_id, target_id, activity_type, date_trunc('hour', timestamp) AS bucket
+			FROM user_activity_logs
+			WHERE activity_type IN ('view_marker', 'favorite_marker') AND target_id IS NOT NULL AND timestamp >= ?) a
+		GROUP BY target_id`, trendingFavoriteWeight, now, halfLife, since).Scan(&activity).Error; err != nil {
+		return nil, err
+	}
+
+	var reviews []struct {
+		MarkerID uuid.UUID
+		Score    float64
+		Reviews  int
+	}
+	if err := db.Model(&models.MarkerReview{}).
+		Select(`marker_id, SUM(EXP(-LN(2) * EXTRACT(EPOCH FROM (?::timestamptz - created_at)) / ?)) AS score, COUNT(*) AS reviews`, now, halfLife).
+		Where("NOT is_hidden AND created_at >= ?", since).
+		Group("marker_id").Scan(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	byMarker := make(map[uuid.UUID]*models.MarkerRanking)
+	entry := func(markerID uuid.UUID) *models.MarkerRanking {
+		if ranking, ok := byMarker[markerID]; ok {
+			return ranking
+		}
+		ranking := &models.MarkerRanking{MarkerID: markerID, Window: name, ComputedAt: now}
+		byMarker[markerID] = ranking
+		return ranking
+	}
+	for _, row := range activity {
+		ranking := entry(row.MarkerID)
+		ranking.ActivityScore = row.Score
+		ranking.Views = row.Views
+	}
+	for _, row := range reviews {
+		ranking := entry(row.MarkerID)
+		ranking.ReviewScore = row.Score
+		ranking.Reviews = row.Reviews
+	}
+	if len(byMarker) == 0 {
+		return nil, nil
+	}
+
+	// Hanya marker yang masih tampil ke publik yang diberi peringkat
+	markerIDs := make([]uuid.UUID, 0, len(byMarker))
+	for id := range byMarker {
+		markerIDs = append(markerIDs, id)
+	}
+	var markers []models.Marker
+	if err := db.Select("id", "avg_rating", "total_reviews").
+		Where("id IN ? AND status = ?", markerIDs, models.MarkerStatusApproved).Find(&markers).Error; err != nil {
+		return nil, err
+	}
+
+	rankings := make([]models.MarkerRanking, 0, len(markers))
+	for _, marker := range markers {
+		ranking := byMarker[marker.ID]
+		ranking.BayesianRating = math.Round(bayesianRating(prior, marker.AvgRating, marker.TotalReviews)*100) / 100
+		activityScore := ranking.ActivityScore + trendingReviewWeight*ranking.ReviewScore
+		ranking.Score = activityScore * (0.5 + ranking.BayesianRating/5)
+		rankings = append(rankings, *ranking)
+	}
+	return rankings, nil
+}
+
+// GetTrendingMarkers mengambil marker trending dari tabel peringkat yang sudah dihitung. (Public)
+// Query opsional:
+//   - ?window= 24h, 7d (default), atau 30d
+//   - ?near=lat,lng dengan ?radius= (meter, default 10000, maksimum 100000)
+//   - ?bbox=min_lng,min_lat,max_lng,max_lat
+//   - ?category_id= dan ?city=
+//   - ?limit= (1-100, default 20)
+func (tc *TrendingController) GetTrendingMarkers(c *gin.Context) {
+	windowName := c.DefaultQuery("window", models.RankingWindow7d)
+	if _, ok := trendingWindows[windowName]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must be one of 24h, 7d, 30d"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+		return
+	}
+
+	query := tc.DB.Model(&models.MarkerRanking{}).
+		Joins("JOIN markers ON markers.id = marker_rankings.marker_id AND markers.deleted_at IS NULL AND markers.status = ?", models.MarkerStatusApproved).
+		Where("marker_rankings.ranking_window = ?", windowName)
+
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		categoryUUID, err := uuid.Parse(categoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+			return
+		}
+		query = query.Where("markers.category_id IN (?)", categorySubtree(tc.DB, categoryUUID))
+	}
+	if city := strings.TrimSpace(c.Query("city")); city != "" {
+		query = query.Where("LOWER(markers.city) = LOWER(?)", city)
+	}
+	if bboxParam := c.Query("bbox"); bboxParam != "" {
+		bounds, err := parseBBox(bboxParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bbox: " + err.Error()})
+			return
+		}
+		query = query.Where("markers.latitude BETWEEN ? AND ? AND markers.longitude BETWEEN ? AND ?",
+			bounds.MinLat, bounds.MaxLat, bounds.MinLng, bounds.MaxLng)
+	}
+
+	var near *[2]float64
+	radius := defaultTrendingRadius
+	if nearParam := c.Query("near"); nearParam != "" {
+		lat, lng, err := parseLatLng(nearParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid near: " + err.Error()})
+			return
+		}
+		if v := c.Query("radius"); v != "" {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil || parsed <= 0 || parsed > 100000 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "radius must be between 0 and 100000 meters"})
+				return
+			}
+			radius = parsed
+		}
+		near = &[2]float64{lat, lng}
+		// Kotak pembatas sebagai pra-filter di database, jarak sebenarnya dihitung setelahnya
+		minLat, maxLat, minLng, maxLng := utils.BoundingBox(lat, lng, radius)
+		query = query.Where("markers.latitude BETWEEN ? AND ? AND markers.longitude BETWEEN ? AND ?", minLat, maxLat, minLng, maxLng)
+	} else {
+		query = query.Limit(limit)
+	}
+
+	var rankings []models.MarkerRanking
+	if err := query.Preload("Marker").Order("marker_rankings.score DESC").Find(&rankings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trending markers: " + err.Error()})
+		return
+	}
+
+	result := make([]TrendingMarker, 0, limit)
+	var computedAt *time.Time
+	for i := range rankings {
+		ranking := &rankings[i]
+		if ranking.Marker == nil {
+			continue
+		}
+		item := TrendingMarker{
+			Score:          ranking.Score,
+			Views:          ranking.Views,
+			Reviews:        ranking.Reviews,
+			BayesianRating: ranking.BayesianRating,
+			Marker:         *ranking.Marker,
+		}
+		if near != nil {
+			distance := utils.HaversineMeters(near[0], near[1], ranking.Marker.Latitude, ranking.Marker.Longitude)
+			if distance > radius {
+				continue
+			}
+			item.DistanceMeters = &distance
+		}
+		item.Rank = len(result) + 1
+		result = append(result, item)
+		if computedAt == nil {
+			computedAt = &ranking.ComputedAt
+		}
+		if len(result) == limit {
+			break
+		}
+	}
+	targets := []translationTarget{}
+	for i := range result {
+		targets = append(targets, markerTranslationTargets(&result[i].Marker)...)
+	}
+	if err := applyTranslations(tc.DB, requestLocaleChain(c), targets); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load translations: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"window": windowName, "computed_at": computedAt, "markers": result})
+}
+
+// RecomputeTrending menjalankan perhitungan peringkat trending saat itu juga. (Admin Protected)
+func (tc *TrendingController) RecomputeTrending(c *gin.Context) {
+	if err := tc.RecomputeRankings(time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute trending rankings: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Trending rankings recomputed"})
+}
@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/google"
+)
+
+// TripExtractor mengubah kalimat bebas user menjadi rencana perjalanan terstruktur (tujuan, moda,
+// tempat singgah, rencana pulang).
+type TripExtractor interface {
+	Extract(ctx context.Context, query string) (*ExtractedTripInfo, error)
+}
+
+// VertexTripExtractor mengekstrak rencana perjalanan dengan Gemini di Vertex AI, memakai kredensial
+// Application Default Credentials.
+type VertexTripExtractor struct {
+	ProjectID string
+	Location  string // e.g., "us-central1"
+	Client    *http.Client
+}
+
+// NewVertexTripExtractorFromEnv membuat VertexTripExtractor dari GOOGLE_VERTEX_AI_PROJECT_ID dan
+// GOOGLE_VERTEX_AI_LOCATION. Konfigurasi yang kosong baru dilaporkan saat Extract dipanggil, agar server
+// tetap bisa berjalan tanpa fitur perencanaan perjalanan.
+func NewVertexTripExtractorFromEnv() *VertexTripExtractor {
+	return &VertexTripExtractor{
+		ProjectID: os.Getenv("GOOGLE_VERTEX_AI_PROJECT_ID"),
+		Location:  os.Getenv("GOOGLE_VERTEX_AI_LOCATION"),
+		Client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Extract memanggil Vertex AI (Gemini) untuk NLU (VERSI DIPERBARUI DENGAN ROLE & MODEL YANG BENAR)
+func (e *VertexTripExtractor) Extract(ctx context.Context, query string) (*ExtractedTripInfo, error) {
+	if e.ProjectID == "" || e.Location == "" {
+		return nil, fmt.Errorf("vertex AI environment variables not configured (GOOGLE_VERTEX_AI_PROJECT_ID, GOOGLE_VERTEX_AI_LOCATION)")
+	}
+
+	scopes := []string{"https://www.googleapis.com/auth/cloud-platform"}
+
+	tokenSource, err := google.DefaultTokenSource(ctx, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token source from service account: %w", err)
+	}
+
+	token, err := tokenSource.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	// Prompt yang spesifik untuk meminta output JSON (tidak berubah)
+	prompt := fmt.Sprintf(`
+      	Ekstrak dan inferensi informasi rencana perjalanan dari kalimat berikut ke dalam format JSON yang ketat. Fokus utama adalah menentukan moda transportasi yang paling mungkin dan preferensi rute.
+		Kalimat: "%s"
+
+		Aturan:
+		1.  **destination**: Harus berupa nama lokasi tujuan yang spesifik. Sertakan kota atau negara jika memungkinkan.
+		2.  **travel_mode**: Objek yang berisi detail tentang cara perjalanan.
+			- **mode**: **Lakukan inferensi** untuk menentukan moda transportasi dengan logika prioritas berikut:
+				a. **Eksplisit**: Jika pengguna menyebut "mobil", gunakan "driving". Jika menyebut "motor", "motoran", atau "touring", gunakan "motorcycle".
+				b. **Implisit/Kontekstual (Sangat Penting)**: Jika pengguna menggunakan frasa yang sangat mengindikasikan sepeda motor di konteks Indonesia seperti "lewat jalan tikus", "cari rute alternatif cepat", "selap-selip", atau "hindari ganjil-genap", **simpulkan sebagai "motorcycle"** bahkan jika kata 'motor' tidak disebut.
+				c. **Default**: Jika sama sekali tidak ada petunjuk, gunakan "driving".
+			- **preferences**: Array string berisi preferensi rute. Ekstrak dari frasa seperti "jangan lewat tol" (menjadi "avoid_tolls"), "hindari jalan raya" (menjadi "avoid_highways"), "nggak mau naik kapal/nyebrang" (menjadi "avoid_ferries"). Hanya gunakan ketiga nilai tersebut.
+		3.  **stops_along_the_way**: Array makanan, minuman, atau aktivitas singkat selama perjalanan.
+		4.  **return_trip_plan**: String rencana untuk perjalanan pulang.
+		5.  **legs**: Array objek untuk setiap segmen perjalanan.
+			- **steps**: Array objek yang berisi detail langkah demi langkah untuk segmen tersebut.
+		6.  **Nilai Kosong**: Gunakan nilai kosong yang sesuai ( "", [], {} ) jika informasi tidak ditemukan.
+
+		---
+		Contoh 1
+		Kalimat: "Rute motoran ke Puncak, tapi jangan lewat tol ya."
+		Output JSON:
+		{
+		"destination": "Puncak, Bogor, Indonesia",
+		"travel_mode": {
+			"mode": "motorcycle",
+			"preferences": ["avoid_tolls"]
+		},
+		"stops_along_the_way": [],
+		"return_trip_plan": "",
+		"legs": [
+			{
+			"steps": []
+			}
+		]
+		}
+		---
+		Contoh 2
+		Kalimat: "Mau ke Kota Tua dari Bekasi, cariin jalan tikus dong biar cepet nyampe."
+		Output JSON:
+		{
+		"destination": "Kota Tua, Jakarta, Indonesia",
+		"travel_mode": {
+			"mode": "motorcycle",
+			"preferences": []
+		},
+		"stops_along_the_way": [],
+		"return_trip_plan": "",
+		"legs": [
+			{
+			"steps": []
+			}
+		]
+		}
+		---
+		Contoh 3
+		Kalimat: "Tolong dong rute ke Lembang, mau beli oleh-oleh bolu susu."
+		Output JSON:
+		{
+		"destination": "Lembang, Bandung Barat, Indonesia",
+		"travel_mode": {
+			"mode": "driving",
+			"preferences": []
+		},
+		"stops_along_the_way": [],
+		"return_trip_plan": "beli oleh-oleh bolu susu",
+		"legs": [
+			{
+			"steps": []
+			}
+		]
+		}
+		---
+
+		JSON output:
+    `, query)
+
+	// ======================================================================
+	// === PERBAIKAN DI SINI: Tambahkan "role": "user" ===
+	// ======================================================================
+	reqPayload := map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{
+				"role": "user", // <-- FIELD WAJIB UNTUK API VERSI BARU
+				"parts": []map[string]string{
+					{"text": prompt},
+				},
+			},
+		},
+	}
+
+	jsonReq, err := json.Marshal(reqPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Vertex AI request: %w", err)
+	}
+
+	// Menggunakan nama model yang valid dan stabil
+	apiURL := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/gemini-2.0-flash-001:generateContent", e.Location, e.ProjectID, e.Location)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonReq))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request for Vertex AI: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Vertex AI API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vertex AI response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Vertex AI API returned non-OK status: %d. Raw response: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("vertex AI API returned non-OK status: %d", resp.StatusCode)
+	}
+
+	// Parsing respons (tidak berubah)
+	var vertexResp VertexAIResponse
+	if err := json.Unmarshal(body, &vertexResp); err != nil || len(vertexResp.Candidates) == 0 {
+		log.Printf("Raw Vertex AI response (unmarshal failed or no candidates): %s", string(body))
+		return nil, fmt.Errorf("failed to parse Vertex AI response or no candidates found")
+	}
+
+	jsonText := vertexResp.Candidates[0].Content.Parts[0].Text
+	jsonText = strings.TrimPrefix(jsonText, "```json")
+	jsonText = strings.TrimSuffix(jsonText, "```")
+
+	var extractedInfo ExtractedTripInfo
+	if err := json.Unmarshal([]byte(jsonText), &extractedInfo); err != nil {
+		log.Printf("Failed to unmarshal JSON from Vertex AI text: %s. Error: %v", jsonText, err)
+		return nil, fmt.Errorf("failed to unmarshal JSON from Vertex AI text: %w", err)
+	}
+
+	return &extractedInfo, nil
+}
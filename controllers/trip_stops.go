@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"ulyngo/directions"
+	"ulyngo/places"
+	"ulyngo/utils"
+)
+
+// TripStop adalah tempat yang dipilih untuk satu tempat singgah dan dilewati rute utama.
+type TripStop struct {
+	Query      string       `json:"query"`       // Nama tempat singgah dari interpretation, e.g. "jajan cimol"
+	Place      places.Place `json:"place"`       // Tempat yang dipilih dari suggested_stops
+	AutoPicked bool         `json:"auto_picked"` // true jika dipilih otomatis (rating tertinggi), bukan oleh user
+}
+
+// TripLeg adalah ringkasan satu segmen rute utama.
+type TripLeg struct {
+	From            string `json:"from"`
+	To              string `json:"to"`
+	DistanceMeters  int64  `json:"distance_meters"`
+	DurationSeconds int64  `json:"duration_seconds"`
+}
+
+// TripDetour membandingkan rute melalui tempat singgah dengan rute langsung ke tujuan.
+type TripDetour struct {
+	DirectDistanceMeters  int64 `json:"direct_distance_meters"`
+	DirectDurationSeconds int64 `json:"direct_duration_seconds"`
+	ExtraDistanceMeters   int64 `json:"extra_distance_meters"`
+	ExtraDurationSeconds  int64 `json:"extra_duration_seconds"`
+}
+
+// chooseTripStops memilih satu tempat untuk setiap tempat singgah sesuai urutan interpretation. Pilihan user
+// (selected, map[nama_stop]id_tempat) dicocokkan tanpa membedakan huruf besar/kecil; tempat singgah tanpa
+// pilihan memakai hasil dengan rating tertinggi, dan yang tidak memiliki hasil dilewati.
+func chooseTripStops(stops []string, suggested map[string][]places.Place, selected map[string]string) ([]TripStop, error) {
+	selectedByStop := make(map[string]string, len(selected))
+	for stop, placeID := range selected {
+		selectedByStop[utils.NormalizeName(stop)] = placeID
+	}
+	known := make(map[string]bool, len(stops))
+	for _, stop := range stops {
+		known[utils.NormalizeName(stop)] = true
+	}
+	for stop := range selected {
+		if !known[utils.NormalizeName(stop)] {
+			return nil, fmt.Errorf("selected_stops contains unknown stop %q", stop)
+		}
+	}
+
+	chosen := []TripStop{}
+	used := map[string]bool{}
+	for _, stop := range stops {
+		candidates := suggested[stop]
+		placeID, hasSelection := selectedByStop[utils.NormalizeName(stop)]
+		if !hasSelection && len(candidates) == 0 {
+			continue
+		}
+		place, err := pickPlace(candidates, placeID)
+		if err != nil {
+			return nil, fmt.Errorf("%w for stop %q", err, stop)
+		}
+		// Tempat yang sama untuk dua tempat singgah cukup disinggahi sekali
+		if used[place.Source+":"+place.ID] {
+			continue
+		}
+		used[place.Source+":"+place.ID] = true
+		chosen = append(chosen, TripStop{Query: stop, Place: *place, AutoPicked: !hasSelection})
+	}
+	return chosen, nil
+}
+
+// pickPlace mengembalikan tempat dengan ID placeID dari hasil pencarian, atau tempat dengan rating tertinggi
+// jika placeID kosong. Jika rating sama, urutan hasil pencarian (marker ulyngo lebih dulu) yang menentukan.
+func pickPlace(candidates []places.Place, placeID string) (*places.Place, error) {
+	var best *places.Place
+	for i := range candidates {
+		if placeID != "" {
+			if candidates[i].ID == placeID {
+				return &candidates[i], nil
+			}
+			continue
+		}
+		if best == nil || candidates[i].Rating > best.Rating {
+			best = &candidates[i]
+		}
+	}
+	if best == nil {
+		if placeID != "" {
+			return nil, fmt.Errorf("selected place %q is not among the search results", placeID)
+		}
+		return nil, fmt.Errorf("no places found")
+	}
+	return best, nil
+}
+
+// tripLegs meringkas leg rute dengan nama asal, tempat singgah, dan tujuan.
+func tripLegs(route *directions.Route, origin, destination string, stops []TripStop) []TripLeg {
+	names := []string{origin}
+	for _, stop := range stops {
+		names = append(names, stop.Place.Name)
+	}
+	names = append(names, destination)
+
+	legs := make([]TripLeg, 0, len(route.Legs))
+	for i, leg := range route.Legs {
+		from, to := leg.StartAddress, leg.EndAddress
+		// Gunakan nama tempat hanya jika jumlah leg sesuai dengan titik yang diminta
+		if len(route.Legs) == len(names)-1 {
+			from, to = names[i], names[i+1]
+		}
+		legs = append(legs, TripLeg{From: from, To: to, DistanceMeters: leg.DistanceMeters, DurationSeconds: leg.DurationSeconds})
+	}
+	return legs
+}
+
+// tripDetour menghitung tambahan jarak dan durasi rute melalui tempat singgah dibanding rute langsung.
+func tripDetour(direct, withStops *directions.Route) *TripDetour {
+	return &TripDetour{
+		DirectDistanceMeters:  direct.DistanceMeters,
+		DirectDurationSeconds: direct.DurationSeconds,
+		ExtraDistanceMeters:   withStops.DistanceMeters - direct.DistanceMeters,
+		ExtraDurationSeconds:  withStops.DurationSeconds - direct.DurationSeconds,
+	}
+}
+
+// Jenis leg itinerary.
+const (
+	TripItineraryOutbound = "outbound"
+	TripItineraryReturn   = "return"
+)
+
+// TripItineraryLeg adalah satu bagian perjalanan pulang-pergi: berangkat ke tujuan atau pulang ke asal.
+type TripItineraryLeg struct {
+	Kind            string     `json:"kind"` // outbound atau return
+	From            string     `json:"from"`
+	To              string     `json:"to"`
+	Via             []string   `json:"via"` // Nama tempat yang disinggahi, berurutan
+	DistanceMeters  int64      `json:"distance_meters"`
+	DurationSeconds int64      `json:"duration_seconds"`
+	DepartureTime   *time.Time `json:"departure_time,omitempty"`
+	ArrivalTime     *time.Time `json:"arrival_time,omitempty"` // Waktu berangkat ditambah durasi, jika waktu berangkat diketahui
+}
+
+// setRoute mengisi jarak, durasi, dan perkiraan waktu tiba dari rute.
+func (l *TripItineraryLeg) setRoute(route *directions.Route, departure *time.Time) {
+	l.DistanceMeters = route.DistanceMeters
+	l.DurationSeconds = route.DurationSeconds
+	if departure != nil {
+		arrival := departure.Add(time.Duration(route.DurationSeconds) * time.Second)
+		l.DepartureTime = departure
+		l.ArrivalTime = &arrival
+	}
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"ulyngo/directions"
+	"ulyngo/places"
+)
+
+func TestChooseTripStops(t *testing.T) {
+	cimol := []places.Place{
+		{ID: "m1", Source: "ulyngo", Name: "Cimol Bojot", Rating: 4.2},
+		{ID: "g1", Source: "google", Name: "Cimol Gembung", Rating: 4.7},
+	}
+	kopi := []places.Place{
+		{ID: "m2", Source: "ulyngo", Name: "Kopi Aroma", Rating: 4.8},
+		{ID: "g2", Source: "google", Name: "Kopi Toko Djawa", Rating: 4.5},
+	}
+	// Tempat yang sama muncul sebagai hasil dua tempat singgah
+	oleh := []places.Place{{ID: "m2", Source: "ulyngo", Name: "Kopi Aroma", Rating: 4.8}}
+	suggested := map[string][]places.Place{"jajan cimol": cimol, "ngopi": kopi, "oleh-oleh kopi": oleh, "kosong": {}}
+
+	tests := []struct {
+		name      string
+		stops     []string
+		selected  map[string]string
+		wantIDs   []string
+		wantAuto  []bool
+		wantQuery []string
+		wantErr   string
+	}{
+		{name: "no stops", wantIDs: []string{}},
+		{
+			name: "auto picks highest rating in order", stops: []string{"ngopi", "jajan cimol"},
+			wantIDs: []string{"m2", "g1"}, wantAuto: []bool{true, true}, wantQuery: []string{"ngopi", "jajan cimol"},
+		},
+		{
+			name: "selection overrides rating", stops: []string{"jajan cimol", "ngopi"}, selected: map[string]string{"jajan cimol": "m1"},
+			wantIDs: []string{"m1", "m2"}, wantAuto: []bool{false, true},
+		},
+		{
+			name: "selection matches stop name case-insensitively", stops: []string{"jajan cimol"}, selected: map[string]string{"Jajan  CIMOL": "m1"},
+			wantIDs: []string{"m1"}, wantAuto: []bool{false},
+		},
+		{
+			name: "stop without results is skipped", stops: []string{"kosong", "tidak dicari", "ngopi"},
+			wantIDs: []string{"m2"}, wantQuery: []string{"ngopi"},
+		},
+		{
+			name: "same place is visited once", stops: []string{"ngopi", "oleh-oleh kopi", "jajan cimol"},
+			wantIDs: []string{"m2", "g1"}, wantQuery: []string{"ngopi", "jajan cimol"},
+		},
+		{
+			name: "different pick avoids duplicate", stops: []string{"ngopi", "oleh-oleh kopi"}, selected: map[string]string{"ngopi": "g2"},
+			wantIDs: []string{"g2", "m2"}, wantAuto: []bool{false, true},
+		},
+		{name: "unknown stop", stops: []string{"ngopi"}, selected: map[string]string{"makan siang": "m1"}, wantErr: `unknown stop "makan siang"`},
+		{name: "selected place not in results", stops: []string{"ngopi"}, selected: map[string]string{"ngopi": "g1"}, wantErr: `selected place "g1" is not among the search results for stop "ngopi"`},
+		{name: "selection for stop without results", stops: []string{"kosong"}, selected: map[string]string{"kosong": "m1"}, wantErr: `for stop "kosong"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := chooseTripStops(tt.stops, suggested, tt.selected)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ids, auto, queries := []string{}, []bool{}, []string{}
+			for _, stop := range got {
+				ids = append(ids, stop.Place.ID)
+				auto = append(auto, stop.AutoPicked)
+				queries = append(queries, stop.Query)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("place IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			if tt.wantAuto != nil && !reflect.DeepEqual(auto, tt.wantAuto) {
+				t.Errorf("auto picked = %v, want %v", auto, tt.wantAuto)
+			}
+			if tt.wantQuery != nil && !reflect.DeepEqual(queries, tt.wantQuery) {
+				t.Errorf("queries = %v, want %v", queries, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestPickPlace(t *testing.T) {
+	candidates := []places.Place{
+		{ID: "m1", Source: "ulyngo", Rating: 4.5},
+		{ID: "g1", Source: "google", Rating: 4.5},
+		{ID: "g2", Source: "google", Rating: 4.1},
+		{ID: "m2", Source: "ulyngo"},
+	}
+	tests := []struct {
+		name       string
+		candidates []places.Place
+		placeID    string
+		wantID     string
+		wantErr    string
+	}{
+		{name: "highest rating, first on ties", candidates: candidates, wantID: "m1"},
+		{name: "highest rating later in list", candidates: candidates[2:], wantID: "g2"},
+		{name: "unrated only", candidates: candidates[3:], wantID: "m2"},
+		{name: "selected ID", candidates: candidates, placeID: "g2", wantID: "g2"},
+		{name: "selected unrated place", candidates: candidates, placeID: "m2", wantID: "m2"},
+		{name: "selected ID missing", candidates: candidates, placeID: "x9", wantErr: `selected place "x9" is not among the search results`},
+		{name: "selected ID with no results", placeID: "m1", wantErr: "not among the search results"},
+		{name: "no results", wantErr: "no places found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pickPlace(tt.candidates, tt.placeID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("picked %q, want %q", got.ID, tt.wantID)
+			}
+			// Hasil menunjuk ke elemen slice, bukan salinan
+			for i := range tt.candidates {
+				if tt.candidates[i].ID == got.ID && &tt.candidates[i] != got {
+					t.Errorf("picked place is a copy of candidate %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestTripLegs(t *testing.T) {
+	stops := []TripStop{{Query: "ngopi", Place: places.Place{Name: "Kopi Aroma"}}}
+	leg := func(start, end string, distance, duration int64) directions.Leg {
+		return directions.Leg{StartAddress: start, EndAddress: end, DistanceMeters: distance, DurationSeconds: duration}
+	}
+	tests := []struct {
+		name  string
+		route *directions.Route
+		stops []TripStop
+		want  []TripLeg
+	}{
+		{
+			name:  "legs named after stops",
+			route: &directions.Route{Legs: []directions.Leg{leg("Jl. Asia Afrika", "Jl. Banceuy", 1200, 300), leg("Jl. Banceuy", "Lembang", 15000, 2400)}},
+			stops: stops,
+			want: []TripLeg{
+				{From: "Bandung", To: "Kopi Aroma", DistanceMeters: 1200, DurationSeconds: 300},
+				{From: "Kopi Aroma", To: "Lembang", DistanceMeters: 15000, DurationSeconds: 2400},
+			},
+		},
+		{
+			name:  "direct route",
+			route: &directions.Route{Legs: []directions.Leg{leg("Jl. Asia Afrika", "Lembang", 16000, 2600)}},
+			want:  []TripLeg{{From: "Bandung", To: "Lembang", DistanceMeters: 16000, DurationSeconds: 2600}},
+		},
+		{
+			name:  "leg count mismatch keeps provider addresses",
+			route: &directions.Route{Legs: []directions.Leg{leg("Jl. Asia Afrika", "Lembang", 16000, 2600)}},
+			stops: stops,
+			want:  []TripLeg{{From: "Jl. Asia Afrika", To: "Lembang", DistanceMeters: 16000, DurationSeconds: 2600}},
+		},
+		{name: "no legs", route: &directions.Route{}, stops: stops, want: []TripLeg{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tripLegs(tt.route, "Bandung", "Lembang", tt.stops); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tripLegs = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTripDetour(t *testing.T) {
+	tests := []struct {
+		name              string
+		direct, withStops directions.Route
+		want              TripDetour
+	}{
+		{
+			name:   "stops add distance and time",
+			direct: directions.Route{DistanceMeters: 16000, DurationSeconds: 2600}, withStops: directions.Route{DistanceMeters: 17500, DurationSeconds: 3100},
+			want: TripDetour{DirectDistanceMeters: 16000, DirectDurationSeconds: 2600, ExtraDistanceMeters: 1500, ExtraDurationSeconds: 500},
+		},
+		{
+			name:   "no detour",
+			direct: directions.Route{DistanceMeters: 16000, DurationSeconds: 2600}, withStops: directions.Route{DistanceMeters: 16000, DurationSeconds: 2600},
+			want: TripDetour{DirectDistanceMeters: 16000, DirectDurationSeconds: 2600},
+		},
+		{
+			// Rute langsung dari penyedia tidak selalu yang terpendek, sehingga selisih bisa negatif
+			name:   "route via stop is shorter",
+			direct: directions.Route{DistanceMeters: 16000, DurationSeconds: 2600}, withStops: directions.Route{DistanceMeters: 15800, DurationSeconds: 2700},
+			want: TripDetour{DirectDistanceMeters: 16000, DirectDurationSeconds: 2600, ExtraDistanceMeters: -200, ExtraDurationSeconds: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tripDetour(&tt.direct, &tt.withStops); *got != tt.want {
+				t.Errorf("tripDetour = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
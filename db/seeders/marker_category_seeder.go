@@ -12,43 +12,76 @@ import (
 func SeedMarkerCategories(db *gorm.DB) {
 	categories := []models.MarkerCategory{
 		{
-			Name:        "Restaurant",
+			Name:        "Kuliner",
 			Description: strPtr("Tempat makan dan minum."),
+			Icon:        strPtr("restaurant"),
+			Color:       strPtr("#e67e22"),
+			SortOrder:   1,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
 		{
 			Name:        "Wisata Alam",
 			Description: strPtr("Destinasi alam seperti pantai, gunung, hutan."),
+			Icon:        strPtr("mountain"),
+			Color:       strPtr("#27ae60"),
+			SortOrder:   2,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
 		{
 			Name:        "Sejarah & Budaya",
 			Description: strPtr("Tempat bersejarah, museum, situs budaya."),
+			Icon:        strPtr("museum"),
+			Color:       strPtr("#8e44ad"),
+			SortOrder:   3,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
 		{
 			Name:        "Akomodasi",
 			Description: strPtr("Hotel, penginapan, villa."),
+			Icon:        strPtr("hotel"),
+			Color:       strPtr("#2980b9"),
+			SortOrder:   4,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
 		{
 			Name:        "Transportasi",
 			Description: strPtr("Stasiun, bandara, terminal."),
+			Icon:        strPtr("train"),
+			Color:       strPtr("#7f8c8d"),
+			SortOrder:   5,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
 		{
 			Name:        "Hiburan",
 			Description: strPtr("Pusat perbelanjaan, bioskop, taman hiburan."),
+			Icon:        strPtr("theme-park"),
+			Color:       strPtr("#e84393"),
+			SortOrder:   6,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		},
 	}
 
+	// Subkategori dibuat setelah induknya; parent merujuk ke nama kategori induk
+	subcategories := []struct {
+		Parent   string
+		Category models.MarkerCategory
+	}{
+		{"Kuliner", models.MarkerCategory{Name: "Restoran", Description: strPtr("Rumah makan dan restoran.")}},
+		{"Kuliner", models.MarkerCategory{Name: "Street Food", Description: strPtr("Jajanan kaki lima dan gerobak.")}},
+		{"Street Food", models.MarkerCategory{Name: "Cimol", Description: strPtr("Penjual cimol dan aci goreng.")}},
+		{"Kuliner", models.MarkerCategory{Name: "Kafe", Description: strPtr("Kedai kopi dan kafe.")}},
+		{"Wisata Alam", models.MarkerCategory{Name: "Pantai", Description: strPtr("Pantai dan pesisir.")}},
+		{"Wisata Alam", models.MarkerCategory{Name: "Gunung", Description: strPtr("Gunung, bukit, dan jalur pendakian.")}},
+		{"Akomodasi", models.MarkerCategory{Name: "Hotel", Description: strPtr("Hotel dan resort.")}},
+		{"Akomodasi", models.MarkerCategory{Name: "Villa", Description: strPtr("Villa dan rumah sewa.")}},
+	}
+
 	log.Println("Seeding marker categories...")
 	for _, category := range categories {
 		var existingCategory models.MarkerCategory
@@ -66,6 +99,25 @@ func SeedMarkerCategories(db *gorm.DB) {
 			log.Printf("Category %s already exists, skipping.", category.Name)
 		}
 	}
+	for _, sub := range subcategories {
+		var parent models.MarkerCategory
+		if err := db.Where("name = ?", sub.Parent).First(&parent).Error; err != nil {
+			log.Printf("Parent category %s for %s not found, skipping: %v", sub.Parent, sub.Category.Name, err)
+			continue
+		}
+		var existingCategory models.MarkerCategory
+		if err := db.Where("name = ?", sub.Category.Name).First(&existingCategory).Error; err == nil {
+			log.Printf("Category %s already exists, skipping.", sub.Category.Name)
+			continue
+		}
+		category := sub.Category
+		category.ParentID = &parent.ID // Path dan Depth diisi oleh hook BeforeCreate
+		if err := db.Create(&category).Error; err != nil {
+			log.Printf("Failed to seed category %s: %v", category.Name, err)
+		} else {
+			log.Printf("Seeded category: %s > %s", sub.Parent, category.Name)
+		}
+	}
 	log.Println("Marker category seeding completed.")
 }
 
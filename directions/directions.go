@@ -0,0 +1,325 @@
+// Package directions menyediakan pencarian rute melalui antarmuka DirectionsProvider, dengan model rute
+// yang tidak bergantung pada penyedia. Implementasi yang tersedia: Google Directions API, OSRM dan
+// Valhalla yang di-host sendiri, serta FakeProvider deterministik untuk pengembangan dan pengujian offline.
+package directions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ulyngo/utils"
+)
+
+// ErrNoRoute dikembalikan ketika penyedia tidak menemukan rute antara asal dan tujuan.
+var ErrNoRoute = errors.New("no route found")
+
+// Moda perjalanan yang didukung.
+const (
+	ModeDriving    = "driving"
+	ModeMotorcycle = "motorcycle"
+)
+
+// Jenis jalan yang dapat dihindari.
+const (
+	AvoidTolls    = "tolls"
+	AvoidHighways = "highways"
+	AvoidFerries  = "ferries"
+)
+
+// Request adalah permintaan rute. Origin dan Destination berupa alamat/nama tempat atau koordinat "lat,lng".
+type Request struct {
+	Origin      string
+	Destination string
+	Waypoints   []string   // Titik singgah berurutan di antara asal dan tujuan, opsional
+	Departure   *time.Time // Waktu berangkat untuk estimasi lalu lintas, opsional dan tidak didukung semua penyedia
+	Mode        string     // driving (default) atau motorcycle
+	Avoid       []string   // Kombinasi tolls, highways, ferries
+}
+
+// Normalize mengembalikan salinan permintaan dengan moda default dan daftar avoid yang valid tanpa duplikat.
+// Jalan tol di Indonesia tertutup untuk sepeda motor, sehingga moda motorcycle selalu menghindari tol.
+func (r Request) Normalize() Request {
+	if r.Mode != ModeMotorcycle {
+		r.Mode = ModeDriving
+	}
+	avoid := append([]string{}, r.Avoid...)
+	if r.Mode == ModeMotorcycle {
+		avoid = append(avoid, AvoidTolls)
+	}
+	r.Avoid = []string{}
+	for _, kind := range []string{AvoidTolls, AvoidHighways, AvoidFerries} {
+		for _, value := range avoid {
+			if value == kind {
+				r.Avoid = append(r.Avoid, kind)
+				break
+			}
+		}
+	}
+	return r
+}
+
+// Stops mengembalikan semua titik permintaan secara berurutan: asal, titik singgah, lalu tujuan.
+// Rute memiliki satu leg untuk setiap pasangan titik yang berurutan.
+func (r Request) Stops() []string {
+	stops := make([]string, 0, len(r.Waypoints)+2)
+	stops = append(stops, r.Origin)
+	stops = append(stops, r.Waypoints...)
+	return append(stops, r.Destination)
+}
+
+// Avoids melaporkan apakah permintaan menghindari jenis jalan tertentu.
+func (r Request) Avoids(kind string) bool {
+	for _, value := range r.Avoid {
+		if value == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Route adalah rute hasil pencarian dalam bentuk yang sama untuk semua penyedia.
+type Route struct {
+	Provider        string   `json:"provider"`          // Nama penyedia yang menghasilkan rute
+	Mode            string   `json:"mode"`              // Moda perjalanan yang dipakai
+	Avoid           []string `json:"avoid"`             // Jenis jalan yang dihindari
+	Summary         string   `json:"summary,omitempty"` // Ringkasan rute, misalnya nama jalan utama
+	DistanceMeters  int64    `json:"distance_meters"`   // Jarak total dalam meter
+	DurationSeconds int64    `json:"duration_seconds"`  // Durasi total dalam detik
+	Polyline        string   `json:"polyline"`          // Geometri seluruh rute sebagai encoded polyline presisi 5 (format Google)
+	Legs            []Leg    `json:"legs"`              // Segmen antar titik (asal, titik singgah, tujuan)
+}
+
+// Leg adalah segmen rute di antara dua titik berurutan.
+type Leg struct {
+	StartAddress    string       `json:"start_address,omitempty"`
+	EndAddress      string       `json:"end_address,omitempty"`
+	Start           utils.LatLng `json:"start"`
+	End             utils.LatLng `json:"end"`
+	DistanceMeters  int64        `json:"distance_meters"`
+	DurationSeconds int64        `json:"duration_seconds"`
+	Steps           []Step       `json:"steps"`
+}
+
+// Step adalah satu instruksi navigasi di dalam leg.
+type Step struct {
+	Instruction     string       `json:"instruction"`        // Instruksi dalam teks biasa, tanpa HTML
+	Maneuver        string       `json:"maneuver,omitempty"` // Jenis manuver, misalnya "turn-left", jika diketahui
+	Start           utils.LatLng `json:"start"`
+	End             utils.LatLng `json:"end"`
+	DistanceMeters  int64        `json:"distance_meters"`
+	DurationSeconds int64        `json:"duration_seconds"`
+	Polyline        string       `json:"polyline,omitempty"` // Geometri langkah, encoded polyline presisi 5
+}
+
+// Points mendekode geometri rute menjadi daftar titik.
+func (r *Route) Points() ([]utils.LatLng, error) {
+	return utils.DecodePolyline(r.Polyline)
+}
+
+// Origin mengembalikan titik awal rute.
+func (r *Route) Origin() utils.LatLng {
+	if len(r.Legs) == 0 {
+		return utils.LatLng{}
+	}
+	return r.Legs[0].Start
+}
+
+// Destination mengembalikan titik akhir rute.
+func (r *Route) Destination() utils.LatLng {
+	if len(r.Legs) == 0 {
+		return utils.LatLng{}
+	}
+	return r.Legs[len(r.Legs)-1].End
+}
+
+// Batas pemeriksaan Validate untuk rute yang dikirim klien.
+const (
+	maxRouteLegs              = 30
+	routeJoinToleranceMeters  = 500.0 // Jarak maksimum antara ujung polyline/leg yang seharusnya bertemu
+	routeTotalToleranceMeters = 50
+	routeTotalToleranceSecs   = 60
+)
+
+// Validate memeriksa konsistensi rute yang tidak dibuat oleh server, misalnya rute yang dikirim klien saat
+// disimpan: polyline bisa didekode dan ujungnya sesuai dengan asal dan tujuan, leg saling bersambung, jumlah
+// jarak dan durasi leg sama dengan totalnya (toleransi 1%), dan jarak total tidak lebih pendek dari garis lurus
+// asal-tujuan maupun jauh lebih pendek dari panjang polyline.
+func (r *Route) Validate() error {
+	if len(r.Legs) == 0 || len(r.Legs) > maxRouteLegs {
+		return fmt.Errorf("route must have between 1 and %d legs", maxRouteLegs)
+	}
+	if r.DistanceMeters < 0 || r.DurationSeconds < 0 {
+		return fmt.Errorf("route distance and duration cannot be negative")
+	}
+	var legDistance, legDuration int64
+	for i, leg := range r.Legs {
+		if leg.DistanceMeters < 0 || leg.DurationSeconds < 0 {
+			return fmt.Errorf("leg %d distance and duration cannot be negative", i+1)
+		}
+		if !validLatLng(leg.Start) || !validLatLng(leg.End) {
+			return fmt.Errorf("leg %d has invalid coordinates", i+1)
+		}
+		if i > 0 && !near(r.Legs[i-1].End, leg.Start) {
+			return fmt.Errorf("leg %d does not start where leg %d ends", i+1, i)
+		}
+		legDistance += leg.DistanceMeters
+		legDuration += leg.DurationSeconds
+	}
+	if !withinTolerance(legDistance, r.DistanceMeters, routeTotalToleranceMeters) {
+		return fmt.Errorf("leg distances add up to %d m but the route distance is %d m", legDistance, r.DistanceMeters)
+	}
+	if !withinTolerance(legDuration, r.DurationSeconds, routeTotalToleranceSecs) {
+		return fmt.Errorf("leg durations add up to %d s but the route duration is %d s", legDuration, r.DurationSeconds)
+	}
+
+	points, err := r.Points()
+	if err != nil {
+		return fmt.Errorf("invalid polyline: %w", err)
+	}
+	if len(points) < 2 {
+		return fmt.Errorf("polyline must contain at least two points")
+	}
+	for _, point := range points {
+		if !validLatLng(point) {
+			return fmt.Errorf("polyline contains invalid coordinates")
+		}
+	}
+	if !near(points[0], r.Origin()) || !near(points[len(points)-1], r.Destination()) {
+		return fmt.Errorf("polyline does not connect the route origin and destination")
+	}
+
+	origin, destination := r.Origin(), r.Destination()
+	straight := utils.HaversineMeters(origin.Lat, origin.Lng, destination.Lat, destination.Lng)
+	if float64(r.DistanceMeters) < straight*0.99-routeJoinToleranceMeters {
+		return fmt.Errorf("route distance is shorter than the straight line between origin and destination")
+	}
+	// Polyline overview disederhanakan sehingga boleh lebih pendek, tetapi tidak jauh lebih panjang dari rute
+	if utils.PathLengthMeters(points) > float64(r.DistanceMeters)*1.2+routeJoinToleranceMeters {
+		return fmt.Errorf("polyline is much longer than the route distance")
+	}
+	return nil
+}
+
+func validLatLng(point utils.LatLng) bool {
+	return point.Lat >= -90 && point.Lat <= 90 && point.Lng >= -180 && point.Lng <= 180
+}
+
+func near(a, b utils.LatLng) bool {
+	return utils.HaversineMeters(a.Lat, a.Lng, b.Lat, b.Lng) <= routeJoinToleranceMeters
+}
+
+// withinTolerance melaporkan apakah got sama dengan want dengan selisih paling banyak 1% atau minimum.
+func withinTolerance(got, want, minimum int64) bool {
+	diff := got - want
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= max(minimum, want/100)
+}
+
+// DirectionsProvider mencari rute antara dua titik.
+type DirectionsProvider interface {
+	// Route mencari rute terbaik untuk req. Mengembalikan ErrNoRoute jika tidak ada rute.
+	Route(ctx context.Context, req Request) (*Route, error)
+	// Name mengembalikan nama penyedia, disimpan di Route.Provider.
+	Name() string
+}
+
+// NewFromEnv membuat DirectionsProvider berdasarkan variabel lingkungan DIRECTIONS_PROVIDER:
+//   - "google" (default): Google Directions API (GOOGLE_MAPS_API_KEY)
+//   - "osrm": server OSRM sendiri (OSRM_URL, opsional OSRM_PROFILE, default "driving")
+//   - "valhalla": server Valhalla sendiri (VALHALLA_URL, opsional VALHALLA_COSTING, default "auto")
+//   - "fake": rute garis lurus deterministik tanpa layanan eksternal
+//
+// OSRM dan Valhalla hanya menerima koordinat; alamat teks di-geocode lewat Nominatim jika GEOCODER_URL diset.
+// DIRECTIONS_TIMEOUT_SECONDS (default 15) membatasi waktu tunggu permintaan HTTP.
+func NewFromEnv() (DirectionsProvider, error) {
+	client := &http.Client{Timeout: time.Duration(envSeconds("DIRECTIONS_TIMEOUT_SECONDS", 15)) * time.Second}
+	var geocoder Geocoder
+	if geocoderURL := os.Getenv("GEOCODER_URL"); geocoderURL != "" {
+		geocoder = &NominatimGeocoder{BaseURL: geocoderURL, HTTPClient: client}
+	}
+
+	switch strings.ToLower(os.Getenv("DIRECTIONS_PROVIDER")) {
+	case "", "google":
+		// Kunci API diperiksa saat permintaan agar server tetap bisa berjalan tanpa fitur rute
+		return &GoogleProvider{APIKey: os.Getenv("GOOGLE_MAPS_API_KEY"), HTTPClient: client}, nil
+	case "osrm":
+		baseURL := os.Getenv("OSRM_URL")
+		if baseURL == "" {
+			return nil, fmt.Errorf("OSRM_URL must be set when DIRECTIONS_PROVIDER=osrm")
+		}
+		return &OSRMProvider{BaseURL: baseURL, Profile: os.Getenv("OSRM_PROFILE"), Geocoder: geocoder, HTTPClient: client}, nil
+	case "valhalla":
+		baseURL := os.Getenv("VALHALLA_URL")
+		if baseURL == "" {
+			return nil, fmt.Errorf("VALHALLA_URL must be set when DIRECTIONS_PROVIDER=valhalla")
+		}
+		return &ValhallaProvider{BaseURL: baseURL, Costing: os.Getenv("VALHALLA_COSTING"), Geocoder: geocoder, HTTPClient: client}, nil
+	case "fake":
+		return &FakeProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown DIRECTIONS_PROVIDER %q (use google, osrm, valhalla, or fake)", os.Getenv("DIRECTIONS_PROVIDER"))
+	}
+}
+
+// ParseLatLng mem-parse koordinat berformat "lat,lng". ok bernilai false jika value bukan koordinat.
+func ParseLatLng(value string) (utils.LatLng, bool) {
+	latText, lngText, found := strings.Cut(value, ",")
+	if !found {
+		return utils.LatLng{}, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latText), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return utils.LatLng{}, false
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(lngText), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return utils.LatLng{}, false
+	}
+	return utils.LatLng{Lat: lat, Lng: lng}, true
+}
+
+// resolveLocation mengubah lokasi menjadi koordinat: langsung jika berformat "lat,lng", selain itu lewat geocoder.
+func resolveLocation(ctx context.Context, geocoder Geocoder, value string) (utils.LatLng, error) {
+	if point, ok := ParseLatLng(value); ok {
+		return point, nil
+	}
+	if geocoder == nil {
+		return utils.LatLng{}, fmt.Errorf("location %q must be formatted as lat,lng because no geocoder is configured", value)
+	}
+	return geocoder.Geocode(ctx, value)
+}
+
+// resolveStops mengubah semua titik permintaan menjadi koordinat.
+func resolveStops(ctx context.Context, geocoder Geocoder, req Request) ([]utils.LatLng, error) {
+	stops := req.Stops()
+	points := make([]utils.LatLng, len(stops))
+	for i, stop := range stops {
+		point, err := resolveLocation(ctx, geocoder, stop)
+		if err != nil {
+			return nil, err
+		}
+		points[i] = point
+	}
+	return points, nil
+}
+
+// formatLatLng menulis koordinat sebagai "lat,lng" untuk alamat leg.
+func formatLatLng(point utils.LatLng) string {
+	return strconv.FormatFloat(point.Lat, 'f', 6, 64) + "," + strconv.FormatFloat(point.Lng, 'f', 6, 64)
+}
+
+func envSeconds(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
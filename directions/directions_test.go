@@ -0,0 +1,317 @@
+package directions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ulyngo/utils"
+)
+
+func TestRequestNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       Request
+		wantMode  string
+		wantAvoid []string
+	}{
+		{"empty defaults to driving", Request{}, ModeDriving, []string{}},
+		{"unknown mode defaults to driving", Request{Mode: "walking"}, ModeDriving, []string{}},
+		{"motorcycle always avoids tolls", Request{Mode: ModeMotorcycle}, ModeMotorcycle, []string{AvoidTolls}},
+		{"motorcycle keeps other avoids", Request{Mode: ModeMotorcycle, Avoid: []string{AvoidFerries}}, ModeMotorcycle, []string{AvoidTolls, AvoidFerries}},
+		{"duplicates removed and ordered", Request{Avoid: []string{AvoidFerries, AvoidTolls, AvoidFerries}}, ModeDriving, []string{AvoidTolls, AvoidFerries}},
+		{"unknown avoid dropped", Request{Avoid: []string{"stairs", AvoidHighways}}, ModeDriving, []string{AvoidHighways}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := append([]string(nil), tt.req.Avoid...)
+			got := tt.req.Normalize()
+			if got.Mode != tt.wantMode {
+				t.Errorf("Mode = %q, want %q", got.Mode, tt.wantMode)
+			}
+			if !reflect.DeepEqual(got.Avoid, tt.wantAvoid) {
+				t.Errorf("Avoid = %v, want %v", got.Avoid, tt.wantAvoid)
+			}
+			if !reflect.DeepEqual(tt.req.Avoid, original) {
+				t.Errorf("Normalize modified the original Avoid: %v", tt.req.Avoid)
+			}
+		})
+	}
+}
+
+func TestParseGoogleResponse(t *testing.T) {
+	okBody := `{"status":"OK","routes":[{"summary":"Jl. Braga","overview_polyline":{"points":"_p~iF~ps|U_ulLnnqC"},
+		"legs":[{"start_address":"A","end_address":"B","distance":{"value":1200},"duration":{"value":300},
+		"start_location":{"lat":38.5,"lng":-120.2},"end_location":{"lat":40.7,"lng":-120.95},
+		"steps":[{"html_instructions":"Turn <b>left</b>","maneuver":"turn-left","distance":{"value":1200},"duration":{"value":300},
+		"start_location":{"lat":38.5,"lng":-120.2},"end_location":{"lat":40.7,"lng":-120.95},"polyline":{"points":"_p~iF~ps|U_ulLnnqC"}}]}]}]}`
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+		errText string
+	}{
+		{name: "ok", body: okBody},
+		{name: "zero results", body: `{"status":"ZERO_RESULTS","routes":[]}`, wantErr: ErrNoRoute},
+		{name: "not found", body: `{"status":"NOT_FOUND"}`, wantErr: ErrNoRoute},
+		{name: "ok without legs", body: `{"status":"OK","routes":[{"legs":[]}]}`, wantErr: ErrNoRoute},
+		{name: "denied", body: `{"status":"REQUEST_DENIED","error_message":"bad key"}`, errText: "bad key"},
+		{name: "invalid json", body: `{`, errText: "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := ParseGoogleResponse([]byte(tt.body))
+			if tt.wantErr != nil || tt.errText != "" {
+				if err == nil {
+					t.Fatalf("expected error, got route %+v", route)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				if tt.errText != "" && !strings.Contains(err.Error(), tt.errText) {
+					t.Errorf("err = %v, want it to contain %q", err, tt.errText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if route.Provider != "google" || route.Summary != "Jl. Braga" || route.Polyline != "_p~iF~ps|U_ulLnnqC" {
+				t.Errorf("unexpected route header: %+v", route)
+			}
+			if route.DistanceMeters != 1200 || route.DurationSeconds != 300 {
+				t.Errorf("totals = %d m / %d s, want 1200 m / 300 s", route.DistanceMeters, route.DurationSeconds)
+			}
+			if len(route.Legs) != 1 || len(route.Legs[0].Steps) != 1 {
+				t.Fatalf("unexpected legs: %+v", route.Legs)
+			}
+			if got := route.Legs[0].Steps[0].Instruction; got != "Turn left" {
+				t.Errorf("Instruction = %q, want HTML stripped", got)
+			}
+			if route.Origin() != (utils.LatLng{Lat: 38.5, Lng: -120.2}) {
+				t.Errorf("Origin = %+v", route.Origin())
+			}
+		})
+	}
+}
+
+func TestOSRMProviderRoute(t *testing.T) {
+	okBody := `{"code":"Ok","routes":[{"distance":1500.4,"duration":180.6,"geometry":"_p~iF~ps|U_ulLnnqC",
+		"legs":[{"summary":"Jalan Braga","distance":1500.4,"duration":180.6,"steps":[
+			{"name":"Jalan Braga","distance":1000.2,"duration":120,"geometry":"_p~iF~ps|U","maneuver":{"type":"depart","location":[107.6,-6.9]}},
+			{"name":"Jalan Asia Afrika","distance":500.2,"duration":60.6,"geometry":"_ulLnnqC","maneuver":{"type":"turn","modifier":"slight left","location":[107.61,-6.91]}}]}]}]}`
+	tests := []struct {
+		name        string
+		req         Request
+		body        string
+		wantErr     error
+		wantExclude string
+	}{
+		{name: "ok", req: Request{Origin: "-6.9,107.6", Destination: "-6.92,107.62"}, body: okBody},
+		{name: "motorcycle excludes toll", req: Request{Origin: "-6.9,107.6", Destination: "-6.92,107.62", Mode: ModeMotorcycle, Avoid: []string{AvoidFerries}}, body: okBody, wantExclude: "toll,ferry"},
+		{name: "no route", req: Request{Origin: "-6.9,107.6", Destination: "-6.92,107.62"}, body: `{"code":"NoRoute","routes":[]}`, wantErr: ErrNoRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotExclude string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				gotExclude = r.URL.Query().Get("exclude")
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := &OSRMProvider{BaseURL: server.URL + "/", HTTPClient: server.Client()}
+			route, err := provider.Route(context.Background(), tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// OSRM memakai urutan bujur,lintang
+			if want := "/route/v1/driving/107.600000,-6.900000;107.620000,-6.920000"; gotPath != want {
+				t.Errorf("path = %q, want %q", gotPath, want)
+			}
+			if gotExclude != tt.wantExclude {
+				t.Errorf("exclude = %q, want %q", gotExclude, tt.wantExclude)
+			}
+			if route.DistanceMeters != 1500 || route.DurationSeconds != 181 {
+				t.Errorf("totals = %d m / %d s, want rounded 1500 m / 181 s", route.DistanceMeters, route.DurationSeconds)
+			}
+			if route.Summary != "Jalan Braga" || route.Polyline != "_p~iF~ps|U_ulLnnqC" {
+				t.Errorf("unexpected route: %+v", route)
+			}
+			steps := route.Legs[0].Steps
+			if len(steps) != 2 {
+				t.Fatalf("got %d steps, want 2", len(steps))
+			}
+			if steps[0].Instruction != "Head out on Jalan Braga" || steps[1].Instruction != "Turn slight left onto Jalan Asia Afrika" {
+				t.Errorf("instructions = %q, %q", steps[0].Instruction, steps[1].Instruction)
+			}
+			if steps[1].Maneuver != "turn-slight-left" {
+				t.Errorf("Maneuver = %q, want turn-slight-left", steps[1].Maneuver)
+			}
+			if steps[0].Start != (utils.LatLng{Lat: -6.9, Lng: 107.6}) || steps[0].End != steps[1].Start {
+				t.Errorf("step locations not chained: %+v", steps)
+			}
+			if steps[1].End != route.Legs[0].End {
+				t.Errorf("last step should end at leg end, got %+v", steps[1].End)
+			}
+		})
+	}
+}
+
+func TestValhallaProviderRoute(t *testing.T) {
+	// Titik dengan digit ke-6 agar terlihat pembulatan ke presisi 5
+	shape := []utils.LatLng{{Lat: -6.900001, Lng: 107.600004}, {Lat: -6.905556, Lng: 107.611116}, {Lat: -6.920003, Lng: 107.619996}}
+	okBody, _ := json.Marshal(map[string]interface{}{
+		"trip": map[string]interface{}{
+			"status": 0,
+			"legs": []map[string]interface{}{{
+				"shape":   encodePolylinePrecision6(shape),
+				"summary": map[string]float64{"length": 2.3456, "time": 300.4},
+				"maneuvers": []map[string]interface{}{
+					{"instruction": "Drive east on Jalan Braga.", "length": 1.2, "time": 150, "begin_shape_index": 0, "end_shape_index": 1, "street_names": []string{"Jalan Braga"}},
+					{"instruction": "You have arrived.", "length": 1.1456, "time": 150.4, "begin_shape_index": 1, "end_shape_index": 9},
+				},
+			}},
+		},
+	})
+	tests := []struct {
+		name        string
+		req         Request
+		status      int
+		body        string
+		wantErr     error
+		wantCosting string
+	}{
+		{name: "ok", req: Request{Origin: "-6.9,107.6", Destination: "-6.92,107.62"}, status: http.StatusOK, body: string(okBody), wantCosting: "auto"},
+		{name: "motorcycle costing", req: Request{Origin: "-6.9,107.6", Destination: "-6.92,107.62", Mode: ModeMotorcycle}, status: http.StatusOK, body: string(okBody), wantCosting: "motorcycle"},
+		{name: "no route", req: Request{Origin: "-6.9,107.6", Destination: "-6.92,107.62"}, status: http.StatusBadRequest, body: `{"error_code":442,"error":"No path could be found for input"}`, wantErr: ErrNoRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload struct {
+				Costing        string                        `json:"costing"`
+				CostingOptions map[string]map[string]float64 `json:"costing_options"`
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&payload)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := &ValhallaProvider{BaseURL: server.URL, HTTPClient: server.Client()}
+			route, err := provider.Route(context.Background(), tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if payload.Costing != tt.wantCosting {
+				t.Errorf("costing = %q, want %q", payload.Costing, tt.wantCosting)
+			}
+			if tt.req.Mode == ModeMotorcycle && payload.CostingOptions["motorcycle"]["use_tolls"] != 0 {
+				t.Errorf("motorcycle should avoid tolls, options = %v", payload.CostingOptions)
+			}
+			if route.DistanceMeters != 2346 || route.DurationSeconds != 300 {
+				t.Errorf("totals = %d m / %d s, want 2346 m / 300 s", route.DistanceMeters, route.DurationSeconds)
+			}
+			if route.Summary != "Jalan Braga" {
+				t.Errorf("Summary = %q", route.Summary)
+			}
+
+			// Geometri presisi 6 dari Valhalla harus ditulis ulang sebagai polyline presisi 5
+			points, err := route.Points()
+			if err != nil {
+				t.Fatalf("route polyline is not valid precision 5: %v", err)
+			}
+			if len(points) != len(shape) {
+				t.Fatalf("got %d points, want %d", len(points), len(shape))
+			}
+			for i, point := range points {
+				wantLat, wantLng := math.Round(shape[i].Lat*1e5)/1e5, math.Round(shape[i].Lng*1e5)/1e5
+				if math.Abs(point.Lat-wantLat) > 1e-9 || math.Abs(point.Lng-wantLng) > 1e-9 {
+					t.Errorf("point %d = %+v, want %v,%v", i, point, wantLat, wantLng)
+				}
+			}
+
+			steps := route.Legs[0].Steps
+			if len(steps) != 2 {
+				t.Fatalf("got %d steps, want 2", len(steps))
+			}
+			// end_shape_index di luar jangkauan dibatasi ke titik terakhir shape
+			if steps[1].End != shape[len(shape)-1] {
+				t.Errorf("last step end = %+v, want %+v", steps[1].End, shape[len(shape)-1])
+			}
+			if stepPoints, err := utils.DecodePolyline(steps[0].Polyline); err != nil || len(stepPoints) != 2 {
+				t.Errorf("step polyline = %q (%v), want 2 points at precision 5", steps[0].Polyline, err)
+			}
+			if steps[0].DistanceMeters != 1200 || steps[1].DistanceMeters != 1146 {
+				t.Errorf("step distances = %d, %d", steps[0].DistanceMeters, steps[1].DistanceMeters)
+			}
+		})
+	}
+}
+
+func TestFakeProviderRouteValidates(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+	}{
+		{"addresses", Request{Origin: "Bandung", Destination: "Jakarta"}},
+		{"coordinates", Request{Origin: "-6.9,107.6", Destination: "-6.2,106.8"}},
+		{"with waypoints", Request{Origin: "Bandung", Destination: "Jakarta", Waypoints: []string{"Cimahi", "-6.5,107.2"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := (&FakeProvider{}).Route(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(route.Legs) != len(tt.req.Waypoints)+1 {
+				t.Errorf("got %d legs, want %d", len(route.Legs), len(tt.req.Waypoints)+1)
+			}
+			if err := route.Validate(); err != nil {
+				t.Errorf("fake route does not validate: %v", err)
+			}
+		})
+	}
+}
+
+// encodePolylinePrecision6 menulis polyline presisi 6 seperti shape Valhalla.
+func encodePolylinePrecision6(points []utils.LatLng) string {
+	var buf []byte
+	var prevLat, prevLng int64
+	for _, point := range points {
+		lat, lng := int64(math.Round(point.Lat*1e6)), int64(math.Round(point.Lng*1e6))
+		for _, delta := range []int64{lat - prevLat, lng - prevLng} {
+			value := delta << 1
+			if delta < 0 {
+				value = ^value
+			}
+			for value >= 0x20 {
+				buf = append(buf, byte((0x20|(value&0x1f))+63))
+				value >>= 5
+			}
+			buf = append(buf, byte(value+63))
+		}
+		prevLat, prevLng = lat, lng
+	}
+	return string(buf)
+}
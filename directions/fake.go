@@ -0,0 +1,127 @@
+package directions
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+
+	"ulyngo/utils"
+)
+
+// Nilai default FakeProvider.
+const (
+	defaultFakeSpeedKmh     = 40.0
+	defaultFakeDetourFactor = 1.3
+	fakeTrackPoints         = 10
+)
+
+// FakeProvider menghasilkan rute garis lurus yang deterministik tanpa layanan eksternal, untuk pengembangan
+// dan pengujian offline. Lokasi berformat "lat,lng" dipakai apa adanya; alamat teks dipetakan secara
+// deterministik (hash) ke sebuah titik di Pulau Jawa sehingga permintaan yang sama selalu menghasilkan rute yang sama.
+type FakeProvider struct {
+	SpeedKmh     float64 // Kecepatan rata-rata, default 40 km/jam
+	DetourFactor float64 // Pengali jarak garis lurus agar mendekati jarak jalan, default 1.3
+}
+
+// Name mengembalikan nama penyedia.
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+// Route membuat rute dengan satu leg garis lurus untuk setiap pasangan titik berurutan (asal, titik singgah, tujuan).
+func (f *FakeProvider) Route(ctx context.Context, req Request) (*Route, error) {
+	req = req.Normalize()
+	if strings.TrimSpace(req.Origin) == "" || strings.TrimSpace(req.Destination) == "" {
+		return nil, fmt.Errorf("origin and destination are required")
+	}
+	speed := f.SpeedKmh
+	if speed <= 0 {
+		speed = defaultFakeSpeedKmh
+	}
+	detour := f.DetourFactor
+	if detour <= 0 {
+		detour = defaultFakeDetourFactor
+	}
+
+	route := &Route{
+		Provider: f.Name(),
+		Mode:     req.Mode,
+		Avoid:    req.Avoid,
+		Summary:  req.Origin + " - " + req.Destination,
+	}
+	names := req.Stops()
+	allPoints := []utils.LatLng{}
+	for i := 0; i+1 < len(names); i++ {
+		start := FakeLocation(names[i])
+		end := FakeLocation(names[i+1])
+		distance := int64(math.Round(utils.HaversineMeters(start.Lat, start.Lng, end.Lat, end.Lng) * detour))
+		duration := int64(math.Round(float64(distance) / (speed * 1000 / 3600)))
+
+		points := make([]utils.LatLng, fakeTrackPoints+1)
+		for j := range points {
+			t := float64(j) / fakeTrackPoints
+			points[j] = utils.LatLng{
+				Lat: start.Lat + (end.Lat-start.Lat)*t,
+				Lng: start.Lng + (end.Lng-start.Lng)*t,
+			}
+		}
+		maneuver := "arrive"
+		if i+2 < len(names) {
+			maneuver = "waypoint"
+		}
+		route.Legs = append(route.Legs, Leg{
+			StartAddress:    names[i],
+			EndAddress:      names[i+1],
+			Start:           start,
+			End:             end,
+			DistanceMeters:  distance,
+			DurationSeconds: duration,
+			Steps: []Step{
+				{
+					Instruction:     "Head toward " + names[i+1],
+					Maneuver:        "depart",
+					Start:           start,
+					End:             end,
+					DistanceMeters:  distance,
+					DurationSeconds: duration,
+					Polyline:        utils.EncodePolyline(points),
+				},
+				{
+					Instruction: "Arrive at " + names[i+1],
+					Maneuver:    maneuver,
+					Start:       end,
+					End:         end,
+				},
+			},
+		})
+		route.DistanceMeters += distance
+		route.DurationSeconds += duration
+		// Titik pertama leg berikutnya sama dengan titik terakhir leg sebelumnya
+		if len(allPoints) > 0 {
+			points = points[1:]
+		}
+		allPoints = append(allPoints, points...)
+	}
+	route.Polyline = utils.EncodePolyline(allPoints)
+	return route, nil
+}
+
+// FakeLocation mengembalikan koordinat deterministik untuk sebuah lokasi: koordinat "lat,lng" apa adanya,
+// atau titik di kotak lintang -8..-6 dan bujur 106..112 (Pulau Jawa) dari hash alamat teks.
+func FakeLocation(value string) utils.LatLng {
+	if point, ok := ParseLatLng(value); ok {
+		return point
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+	sum := hash.Sum64()
+	latFraction := float64(sum&0xffffffff) / float64(math.MaxUint32)
+	lngFraction := float64(sum>>32) / float64(math.MaxUint32)
+	// Dibulatkan ke presisi polyline agar titik asal/tujuan sama dengan geometri rute
+	return utils.LatLng{
+		Lat: math.Round((-8+2*latFraction)*1e5) / 1e5,
+		Lng: math.Round((106+6*lngFraction)*1e5) / 1e5,
+	}
+}
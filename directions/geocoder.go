@@ -0,0 +1,71 @@
+package directions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"ulyngo/utils"
+)
+
+// Geocoder mengubah alamat atau nama tempat menjadi koordinat, untuk penyedia yang hanya menerima koordinat.
+type Geocoder interface {
+	Geocode(ctx context.Context, query string) (utils.LatLng, error)
+}
+
+// NominatimGeocoder melakukan geocoding dengan server Nominatim (OpenStreetMap) yang di-host sendiri.
+type NominatimGeocoder struct {
+	BaseURL    string // Misalnya "http://nominatim:8080"
+	HTTPClient *http.Client
+}
+
+// Geocode mengambil hasil pencarian teratas dari endpoint /search Nominatim.
+func (n *NominatimGeocoder) Geocode(ctx context.Context, query string) (utils.LatLng, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("format", "jsonv2")
+	params.Set("limit", "1")
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(n.BaseURL, "/")+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return utils.LatLng{}, err
+	}
+	httpReq.Header.Set("User-Agent", "ulyngo")
+	resp, err := n.HTTPClient.Do(httpReq)
+	if err != nil {
+		return utils.LatLng{}, fmt.Errorf("failed to call geocoder: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return utils.LatLng{}, fmt.Errorf("failed to read geocoder response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return utils.LatLng{}, fmt.Errorf("geocoder returned HTTP status %s", resp.Status)
+	}
+
+	// Nominatim mengembalikan koordinat sebagai string
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := json.Unmarshal(body, &results); err != nil {
+		return utils.LatLng{}, fmt.Errorf("failed to parse geocoder response: %w", err)
+	}
+	if len(results) == 0 {
+		return utils.LatLng{}, fmt.Errorf("location %q not found: %w", query, ErrNoRoute)
+	}
+	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return utils.LatLng{}, fmt.Errorf("invalid latitude in geocoder response: %w", err)
+	}
+	lng, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return utils.LatLng{}, fmt.Errorf("invalid longitude in geocoder response: %w", err)
+	}
+	return utils.LatLng{Lat: lat, Lng: lng}, nil
+}
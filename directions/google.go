@@ -0,0 +1,177 @@
+package directions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ulyngo/utils"
+)
+
+const googleDirectionsURL = "https://maps.googleapis.com/maps/api/directions/json"
+
+// GoogleProvider mencari rute dengan Google Directions API.
+type GoogleProvider struct {
+	APIKey     string
+	BaseURL    string // Kosong berarti endpoint resmi Google
+	HTTPClient *http.Client
+}
+
+// Name mengembalikan nama penyedia.
+func (g *GoogleProvider) Name() string {
+	return "google"
+}
+
+// Route memanggil Directions API dan mengubah rute pertama ke model Route. Directions API tidak memiliki
+// moda sepeda motor, sehingga motorcycle dikirim sebagai driving dengan tol dihindari.
+func (g *GoogleProvider) Route(ctx context.Context, req Request) (*Route, error) {
+	req = req.Normalize()
+	if g.APIKey == "" {
+		return nil, fmt.Errorf("API key not configured")
+	}
+	baseURL := g.BaseURL
+	if baseURL == "" {
+		baseURL = googleDirectionsURL
+	}
+	params := url.Values{}
+	params.Set("origin", req.Origin)
+	params.Set("destination", req.Destination)
+	params.Set("mode", ModeDriving)
+	if len(req.Waypoints) > 0 {
+		params.Set("waypoints", strings.Join(req.Waypoints, "|"))
+	}
+	if len(req.Avoid) > 0 {
+		params.Set("avoid", strings.Join(req.Avoid, "|"))
+	}
+	// Directions API menolak waktu berangkat di masa lalu
+	if req.Departure != nil && req.Departure.After(time.Now()) {
+		params.Set("departure_time", strconv.FormatInt(req.Departure.Unix(), 10))
+	}
+	params.Set("key", g.APIKey)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Google Directions API: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directions response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Google Directions API returned HTTP status %s", resp.Status)
+	}
+	route, err := ParseGoogleResponse(body)
+	if err != nil {
+		return nil, err
+	}
+	route.Mode, route.Avoid = req.Mode, req.Avoid
+	return route, nil
+}
+
+// googleResponse adalah bagian respons Google Directions API yang dipakai.
+type googleResponse struct {
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	Routes       []struct {
+		Summary string      `json:"summary"`
+		Legs    []googleLeg `json:"legs"`
+		// Polyline seluruh rute
+		OverviewPolyline struct {
+			Points string `json:"points"`
+		} `json:"overview_polyline"`
+	} `json:"routes"`
+}
+
+type googleValue struct {
+	Value int64 `json:"value"`
+}
+
+type googleLocation struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+type googleLeg struct {
+	Distance      googleValue    `json:"distance"`
+	Duration      googleValue    `json:"duration"`
+	StartAddress  string         `json:"start_address"`
+	EndAddress    string         `json:"end_address"`
+	StartLocation googleLocation `json:"start_location"`
+	EndLocation   googleLocation `json:"end_location"`
+	Steps         []struct {
+		HTMLInstructions string         `json:"html_instructions"`
+		Maneuver         string         `json:"maneuver"`
+		Distance         googleValue    `json:"distance"`
+		Duration         googleValue    `json:"duration"`
+		StartLocation    googleLocation `json:"start_location"`
+		EndLocation      googleLocation `json:"end_location"`
+		Polyline         struct {
+			Points string `json:"points"`
+		} `json:"polyline"`
+	} `json:"steps"`
+}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// ParseGoogleResponse mengubah body JSON respons Google Directions API menjadi Route.
+// Dipakai juga untuk membaca data rute lama yang disimpan dalam format Google.
+func ParseGoogleResponse(body []byte) (*Route, error) {
+	var parsed googleResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse directions response: %w", err)
+	}
+	switch parsed.Status {
+	case "OK":
+	case "ZERO_RESULTS", "NOT_FOUND":
+		return nil, ErrNoRoute
+	default:
+		return nil, fmt.Errorf("Directions API returned status '%s'. Full error: %s", parsed.Status, parsed.ErrorMessage)
+	}
+	if len(parsed.Routes) == 0 || len(parsed.Routes[0].Legs) == 0 {
+		return nil, ErrNoRoute
+	}
+
+	source := parsed.Routes[0]
+	route := &Route{Provider: "google", Summary: source.Summary, Polyline: source.OverviewPolyline.Points}
+	for _, sourceLeg := range source.Legs {
+		leg := Leg{
+			StartAddress:    sourceLeg.StartAddress,
+			EndAddress:      sourceLeg.EndAddress,
+			Start:           utils.LatLng(sourceLeg.StartLocation),
+			End:             utils.LatLng(sourceLeg.EndLocation),
+			DistanceMeters:  sourceLeg.Distance.Value,
+			DurationSeconds: sourceLeg.Duration.Value,
+			Steps:           []Step{},
+		}
+		for _, sourceStep := range sourceLeg.Steps {
+			// Instruksi Google berisi HTML (misalnya <b>nama jalan</b>); simpan sebagai teks biasa
+			instruction := htmlTagPattern.ReplaceAllString(strings.ReplaceAll(sourceStep.HTMLInstructions, "<div", " <div"), "")
+			leg.Steps = append(leg.Steps, Step{
+				Instruction:     strings.Join(strings.Fields(html.UnescapeString(instruction)), " "),
+				Maneuver:        sourceStep.Maneuver,
+				Start:           utils.LatLng(sourceStep.StartLocation),
+				End:             utils.LatLng(sourceStep.EndLocation),
+				DistanceMeters:  sourceStep.Distance.Value,
+				DurationSeconds: sourceStep.Duration.Value,
+				Polyline:        sourceStep.Polyline.Points,
+			})
+		}
+		route.Legs = append(route.Legs, leg)
+		route.DistanceMeters += leg.DistanceMeters
+		route.DurationSeconds += leg.DurationSeconds
+	}
+	return route, nil
+}
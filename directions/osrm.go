@@ -0,0 +1,198 @@
+package directions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+
+	"ulyngo/utils"
+)
+
+// OSRMProvider mencari rute dengan server OSRM (Open Source Routing Machine) yang di-host sendiri.
+type OSRMProvider struct {
+	BaseURL    string // Misalnya "http://osrm:5000"
+	Profile    string // Profil routing, default "driving"
+	Geocoder   Geocoder
+	HTTPClient *http.Client
+}
+
+// Name mengembalikan nama penyedia.
+func (o *OSRMProvider) Name() string {
+	return "osrm"
+}
+
+type osrmResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Routes  []struct {
+		Distance float64   `json:"distance"`
+		Duration float64   `json:"duration"`
+		Geometry string    `json:"geometry"`
+		Legs     []osrmLeg `json:"legs"`
+	} `json:"routes"`
+}
+
+type osrmLeg struct {
+	Summary  string  `json:"summary"`
+	Distance float64 `json:"distance"`
+	Duration float64 `json:"duration"`
+	Steps    []struct {
+		Name     string  `json:"name"`
+		Distance float64 `json:"distance"`
+		Duration float64 `json:"duration"`
+		Geometry string  `json:"geometry"`
+		Maneuver struct {
+			Type     string     `json:"type"`
+			Modifier string     `json:"modifier"`
+			Location [2]float64 `json:"location"` // [bujur, lintang]
+		} `json:"maneuver"`
+	} `json:"steps"`
+}
+
+// Route memanggil layanan route OSRM dengan geometri polyline presisi 5 dan langkah navigasi.
+// Departure diabaikan karena OSRM tidak memodelkan lalu lintas.
+func (o *OSRMProvider) Route(ctx context.Context, req Request) (*Route, error) {
+	req = req.Normalize()
+	stops, err := resolveStops(ctx, o.Geocoder, req)
+	if err != nil {
+		return nil, err
+	}
+	profile := o.Profile
+	if profile == "" {
+		profile = "driving"
+	}
+	// OSRM memakai urutan bujur,lintang
+	coordinates := make([]string, len(stops))
+	for i, stop := range stops {
+		coordinates[i] = fmt.Sprintf("%f,%f", stop.Lng, stop.Lat)
+	}
+	endpoint := fmt.Sprintf("%s/route/v1/%s/%s?overview=full&geometries=polyline&steps=true",
+		strings.TrimRight(o.BaseURL, "/"), profile, strings.Join(coordinates, ";"))
+	// Kelas jalan yang dapat dikecualikan bergantung pada profil server; profil car bawaan mendukung toll, motorway, dan ferry
+	if exclude := osrmExclude(req.Avoid); exclude != "" {
+		endpoint += "&exclude=" + exclude
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := o.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call OSRM: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OSRM response: %w", err)
+	}
+	var parsed osrmResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse OSRM response (HTTP %s): %w", resp.Status, err)
+	}
+	switch parsed.Code {
+	case "Ok":
+	case "NoRoute", "NoSegment":
+		return nil, ErrNoRoute
+	default:
+		return nil, fmt.Errorf("OSRM returned code '%s': %s", parsed.Code, parsed.Message)
+	}
+	if len(parsed.Routes) == 0 {
+		return nil, ErrNoRoute
+	}
+
+	source := parsed.Routes[0]
+	route := &Route{
+		Provider:        o.Name(),
+		Mode:            req.Mode,
+		Avoid:           req.Avoid,
+		DistanceMeters:  int64(math.Round(source.Distance)),
+		DurationSeconds: int64(math.Round(source.Duration)),
+		Polyline:        source.Geometry,
+	}
+	names := req.Stops()
+	for i, sourceLeg := range source.Legs {
+		leg := Leg{
+			StartAddress:    names[i],
+			EndAddress:      names[i+1],
+			Start:           stops[i],
+			End:             stops[i+1],
+			DistanceMeters:  int64(math.Round(sourceLeg.Distance)),
+			DurationSeconds: int64(math.Round(sourceLeg.Duration)),
+			Steps:           []Step{},
+		}
+		for j, sourceStep := range sourceLeg.Steps {
+			start := utils.LatLng{Lat: sourceStep.Maneuver.Location[1], Lng: sourceStep.Maneuver.Location[0]}
+			end := leg.End
+			if j+1 < len(sourceLeg.Steps) {
+				next := sourceLeg.Steps[j+1].Maneuver.Location
+				end = utils.LatLng{Lat: next[1], Lng: next[0]}
+			}
+			leg.Steps = append(leg.Steps, Step{
+				Instruction:     osrmInstruction(sourceStep.Maneuver.Type, sourceStep.Maneuver.Modifier, sourceStep.Name),
+				Maneuver:        strings.TrimSuffix(sourceStep.Maneuver.Type+"-"+strings.ReplaceAll(sourceStep.Maneuver.Modifier, " ", "-"), "-"),
+				Start:           start,
+				End:             end,
+				DistanceMeters:  int64(math.Round(sourceStep.Distance)),
+				DurationSeconds: int64(math.Round(sourceStep.Duration)),
+				Polyline:        sourceStep.Geometry,
+			})
+		}
+		if route.Summary == "" {
+			route.Summary = sourceLeg.Summary
+		}
+		route.Legs = append(route.Legs, leg)
+	}
+	return route, nil
+}
+
+// osrmInstruction menyusun instruksi teks sederhana dari manuver OSRM, misalnya "Turn left onto Jalan Braga".
+func osrmInstruction(maneuverType, modifier, name string) string {
+	onto := ""
+	if name != "" {
+		onto = " onto " + name
+	}
+	switch maneuverType {
+	case "depart":
+		if name != "" {
+			return "Head out on " + name
+		}
+		return "Depart"
+	case "arrive":
+		return "Arrive at destination"
+	case "roundabout", "rotary":
+		return "Enter the roundabout" + strings.Replace(onto, " onto ", " and exit onto ", 1)
+	case "merge", "on ramp", "off ramp", "fork":
+		return strings.Join(strings.Fields(capitalize(maneuverType)+" "+modifier), " ") + onto
+	case "continue", "new name":
+		return "Continue" + onto
+	}
+	if modifier == "" || modifier == "straight" {
+		return "Continue straight" + onto
+	}
+	if modifier == "uturn" {
+		return "Make a U-turn" + onto
+	}
+	return "Turn " + modifier + onto
+}
+
+func capitalize(value string) string {
+	if value == "" {
+		return value
+	}
+	return strings.ToUpper(value[:1]) + value[1:]
+}
+
+// osrmExclude mengubah daftar avoid menjadi parameter exclude OSRM.
+func osrmExclude(avoid []string) string {
+	classes := map[string]string{AvoidTolls: "toll", AvoidHighways: "motorway", AvoidFerries: "ferry"}
+	excluded := []string{}
+	for _, kind := range avoid {
+		excluded = append(excluded, classes[kind])
+	}
+	return strings.Join(excluded, ",")
+}
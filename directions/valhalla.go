@@ -0,0 +1,181 @@
+package directions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+
+	"ulyngo/utils"
+)
+
+// ValhallaProvider mencari rute dengan server Valhalla yang di-host sendiri.
+type ValhallaProvider struct {
+	BaseURL    string // Misalnya "http://valhalla:8002"
+	Costing    string // Model biaya Valhalla, default "auto"
+	Geocoder   Geocoder
+	HTTPClient *http.Client
+}
+
+// Name mengembalikan nama penyedia.
+func (v *ValhallaProvider) Name() string {
+	return "valhalla"
+}
+
+type valhallaLocation struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type valhallaResponse struct {
+	ErrorCode int    `json:"error_code"`
+	Error     string `json:"error"`
+	Trip      struct {
+		Status        int    `json:"status"`
+		StatusMessage string `json:"status_message"`
+		Legs          []struct {
+			Shape   string `json:"shape"` // Encoded polyline presisi 6
+			Summary struct {
+				Length float64 `json:"length"` // Kilometer
+				Time   float64 `json:"time"`   // Detik
+			} `json:"summary"`
+			Maneuvers []struct {
+				Instruction     string   `json:"instruction"`
+				Type            int      `json:"type"`
+				Length          float64  `json:"length"`
+				Time            float64  `json:"time"`
+				BeginShapeIndex int      `json:"begin_shape_index"`
+				EndShapeIndex   int      `json:"end_shape_index"`
+				StreetNames     []string `json:"street_names"`
+			} `json:"maneuvers"`
+		} `json:"legs"`
+	} `json:"trip"`
+}
+
+// valhallaNoRouteCodes adalah kode error Valhalla yang berarti rute tidak ditemukan
+// (tidak ada jalan di dekat lokasi, atau tidak ada jalur yang menghubungkan lokasi).
+var valhallaNoRouteCodes = map[int]bool{171: true, 442: true, 443: true}
+
+// Route memanggil endpoint /route Valhalla dan mengubah geometri presisi 6 menjadi presisi 5.
+func (v *ValhallaProvider) Route(ctx context.Context, req Request) (*Route, error) {
+	req = req.Normalize()
+	stops, err := resolveStops(ctx, v.Geocoder, req)
+	if err != nil {
+		return nil, err
+	}
+	costing := v.Costing
+	if costing == "" {
+		costing = "auto"
+	}
+	if req.Mode == ModeMotorcycle {
+		costing = "motorcycle"
+	}
+	// Nilai use_* 0 berarti sebisa mungkin menghindari jenis jalan tersebut
+	costingOptions := map[string]float64{}
+	if req.Avoids(AvoidTolls) {
+		costingOptions["use_tolls"] = 0
+	}
+	if req.Avoids(AvoidHighways) {
+		costingOptions["use_highways"] = 0
+	}
+	if req.Avoids(AvoidFerries) {
+		costingOptions["use_ferry"] = 0
+	}
+	locations := make([]valhallaLocation, len(stops))
+	for i, stop := range stops {
+		locations[i] = valhallaLocation{Lat: stop.Lat, Lon: stop.Lng}
+	}
+	params := map[string]interface{}{
+		"locations":          locations,
+		"costing":            costing,
+		"costing_options":    map[string]interface{}{costing: costingOptions},
+		"directions_options": map[string]string{"units": "kilometers"},
+	}
+	// date_time type 1 berarti waktu berangkat, ditulis tanpa zona waktu sesuai offset yang dikirim pengguna
+	if req.Departure != nil {
+		params["date_time"] = map[string]interface{}{"type": 1, "value": req.Departure.Format("2006-01-02T15:04")}
+	}
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(v.BaseURL, "/")+"/route", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := v.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Valhalla: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Valhalla response: %w", err)
+	}
+	var parsed valhallaResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse Valhalla response (HTTP %s): %w", resp.Status, err)
+	}
+	if valhallaNoRouteCodes[parsed.ErrorCode] {
+		return nil, ErrNoRoute
+	}
+	if resp.StatusCode != http.StatusOK || parsed.Trip.Status != 0 {
+		return nil, fmt.Errorf("Valhalla returned HTTP %s, error %d: %s%s", resp.Status, parsed.ErrorCode, parsed.Error, parsed.Trip.StatusMessage)
+	}
+	if len(parsed.Trip.Legs) == 0 {
+		return nil, ErrNoRoute
+	}
+
+	route := &Route{Provider: v.Name(), Mode: req.Mode, Avoid: req.Avoid}
+	allPoints := []utils.LatLng{}
+	names := req.Stops()
+	for i, sourceLeg := range parsed.Trip.Legs {
+		points, err := utils.DecodePolylinePrecision(sourceLeg.Shape, 6)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Valhalla shape: %w", err)
+		}
+		leg := Leg{
+			StartAddress:    names[i],
+			EndAddress:      names[i+1],
+			Start:           stops[i],
+			End:             stops[i+1],
+			DistanceMeters:  int64(math.Round(sourceLeg.Summary.Length * 1000)),
+			DurationSeconds: int64(math.Round(sourceLeg.Summary.Time)),
+			Steps:           []Step{},
+		}
+		for _, maneuver := range sourceLeg.Maneuvers {
+			begin := min(max(maneuver.BeginShapeIndex, 0), len(points)-1)
+			end := min(max(maneuver.EndShapeIndex, begin), len(points)-1)
+			if begin < 0 {
+				continue
+			}
+			leg.Steps = append(leg.Steps, Step{
+				Instruction:     maneuver.Instruction,
+				Start:           points[begin],
+				End:             points[end],
+				DistanceMeters:  int64(math.Round(maneuver.Length * 1000)),
+				DurationSeconds: int64(math.Round(maneuver.Time)),
+				Polyline:        utils.EncodePolyline(points[begin : end+1]),
+			})
+			if route.Summary == "" && len(maneuver.StreetNames) > 0 {
+				route.Summary = maneuver.StreetNames[0]
+			}
+		}
+		// Titik pertama leg berikutnya sama dengan titik terakhir leg sebelumnya
+		if len(allPoints) > 0 && len(points) > 0 {
+			points = points[1:]
+		}
+		allPoints = append(allPoints, points...)
+		route.Legs = append(route.Legs, leg)
+		route.DistanceMeters += leg.DistanceMeters
+		route.DurationSeconds += leg.DurationSeconds
+	}
+	route.Polyline = utils.EncodePolyline(allPoints)
+	return route, nil
+}
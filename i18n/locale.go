@@ -0,0 +1,115 @@
+// Package i18n menyediakan negosiasi bahasa konten (Accept-Language / ?lang=) dan rantai fallback locale.
+package i18n
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale adalah bahasa konten asli yang tersimpan di kolom model (nama, deskripsi).
+const DefaultLocale = "id"
+
+// Normalize menyeragamkan kode locale: huruf kecil dan tanda hubung, misal "en_US" menjadi "en-us".
+func Normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// Base mengembalikan bahasa dasar dari locale, misal "en-us" menjadi "en".
+func Base(locale string) string {
+	if i := strings.Index(locale, "-"); i > 0 {
+		return locale[:i]
+	}
+	return locale
+}
+
+// SupportedLocales membaca daftar locale yang didukung dari SUPPORTED_LOCALES (dipisah koma),
+// default "id,en". DefaultLocale selalu termasuk.
+func SupportedLocales() []string {
+	raw := os.Getenv("SUPPORTED_LOCALES")
+	if raw == "" {
+		raw = "id,en"
+	}
+	seen := map[string]bool{}
+	locales := []string{}
+	for _, part := range append(strings.Split(raw, ","), DefaultLocale) {
+		locale := Normalize(part)
+		if locale != "" && !seen[locale] {
+			seen[locale] = true
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+// IsSupported bernilai true jika locale ada di daftar locale yang didukung.
+func IsSupported(locale string) bool {
+	locale = Normalize(locale)
+	for _, supported := range SupportedLocales() {
+		if supported == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAcceptLanguage mengurai header Accept-Language menjadi daftar locale berurutan menurut bobot q.
+// Entri dengan q=0 dan wildcard "*" diabaikan.
+func ParseAcceptLanguage(header string) []string {
+	type entry struct {
+		locale  string
+		quality float64
+	}
+	entries := []entry{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale := Normalize(fields[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			entries = append(entries, entry{locale: locale, quality: quality})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].quality > entries[j].quality })
+	locales := make([]string, len(entries))
+	for i, e := range entries {
+		locales[i] = e.locale
+	}
+	return locales
+}
+
+// FallbackChain menyusun rantai locale dari permintaan: setiap locale diikuti bahasa dasarnya
+// (misal "en-au" lalu "en"), hanya locale yang didukung yang diambil, dan DefaultLocale selalu di akhir.
+// Contoh: ["en-au", "ja"] dengan dukungan id,en menghasilkan ["en", "id"].
+func FallbackChain(requested []string) []string {
+	supported := map[string]bool{}
+	for _, locale := range SupportedLocales() {
+		supported[locale] = true
+	}
+	seen := map[string]bool{}
+	chain := []string{}
+	add := func(locale string) {
+		if supported[locale] && !seen[locale] {
+			seen[locale] = true
+			chain = append(chain, locale)
+		}
+	}
+	for _, locale := range requested {
+		locale = Normalize(locale)
+		add(locale)
+		add(Base(locale))
+	}
+	add(DefaultLocale)
+	return chain
+}
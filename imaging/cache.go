@@ -0,0 +1,163 @@
+package imaging
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiskCache adalah cache file di disk dengan batas ukuran total. Jika batas terlampaui,
+// entri yang paling lama tidak diakses (LRU) dihapus lebih dulu.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List               // Depan = paling baru diakses
+	entries map[string]*list.Element // key -> elemen berisi *cacheEntry
+}
+
+type cacheEntry struct {
+	key  string
+	size int64
+}
+
+// NewDiskCache membuat cache di direktori dir dengan batas maxBytes. File yang sudah ada
+// di direktori dimuat kembali (urutan LRU berdasarkan waktu modifikasi) sehingga cache bertahan setelah restart.
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	cache := &DiskCache{dir: dir, maxBytes: maxBytes, order: list.New(), entries: make(map[string]*list.Element)}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type existing struct {
+		key  string
+		size int64
+		mod  int64
+	}
+	var found []existing
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if strings.HasPrefix(f.Name(), ".tmp-") { // Sisa penulisan yang terputus
+			os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, existing{key: f.Name(), size: info.Size(), mod: info.ModTime().UnixNano()})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].mod > found[j].mod })
+	for _, f := range found {
+		cache.entries[f.key] = cache.order.PushBack(&cacheEntry{key: f.key, size: f.size})
+		cache.size += f.size
+	}
+	cache.mu.Lock()
+	cache.evictLocked()
+	cache.mu.Unlock()
+	return cache, nil
+}
+
+// Get membaca entri cache. Mengembalikan false jika key tidak ada.
+func (dc *DiskCache) Get(key string) ([]byte, bool) {
+	dc.mu.Lock()
+	elem, ok := dc.entries[key]
+	if ok {
+		dc.order.MoveToFront(elem)
+	}
+	dc.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(filepath.Join(dc.dir, key))
+	if err != nil {
+		// File hilang dari disk (misalnya dihapus manual): buang entrinya
+		dc.mu.Lock()
+		if elem, ok := dc.entries[key]; ok {
+			dc.removeLocked(elem)
+		}
+		dc.mu.Unlock()
+		return nil, false
+	}
+	// Waktu modifikasi diperbarui agar urutan LRU tetap terjaga setelah restart
+	now := time.Now()
+	_ = os.Chtimes(filepath.Join(dc.dir, key), now, now)
+	return data, true
+}
+
+// Put menyimpan entri cache lalu menghapus entri LRU hingga ukuran total kembali di bawah batas.
+// key harus berupa nama file sederhana tanpa pemisah direktori.
+func (dc *DiskCache) Put(key string, data []byte) error {
+	if key == "" || filepath.Base(key) != key {
+		return fmt.Errorf("invalid cache key %q", key)
+	}
+	if int64(len(data)) > dc.maxBytes {
+		return nil // Lebih besar dari seluruh cache: tidak disimpan
+	}
+
+	tmp, err := os.CreateTemp(dc.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	if err := os.Rename(tmp.Name(), filepath.Join(dc.dir, key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if elem, ok := dc.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		dc.size += int64(len(data)) - entry.size
+		entry.size = int64(len(data))
+		dc.order.MoveToFront(elem)
+	} else {
+		dc.entries[key] = dc.order.PushFront(&cacheEntry{key: key, size: int64(len(data))})
+		dc.size += int64(len(data))
+	}
+	dc.evictLocked()
+	return nil
+}
+
+// evictLocked menghapus entri paling lama tidak diakses hingga ukuran total tidak melebihi batas.
+func (dc *DiskCache) evictLocked() {
+	for dc.size > dc.maxBytes {
+		oldest := dc.order.Back()
+		if oldest == nil {
+			return
+		}
+		dc.removeLocked(oldest)
+	}
+}
+
+// removeLocked menghapus satu entri dari indeks dan dari disk.
+func (dc *DiskCache) removeLocked(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	dc.order.Remove(elem)
+	delete(dc.entries, entry.key)
+	dc.size -= entry.size
+	os.Remove(filepath.Join(dc.dir, entry.key))
+}
@@ -0,0 +1,29 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+)
+
+// MaxSourcePixels adalah batas jumlah piksel gambar yang boleh didekode (~40 MP). Gambar kecil yang
+// mengaku berdimensi sangat besar (decompression bomb) ditolak sebelum memori untuk pikselnya dialokasikan.
+const MaxSourcePixels = 40_000_000
+
+// ErrTooManyPixels dikembalikan Decode jika dimensi gambar melebihi MaxSourcePixels.
+var ErrTooManyPixels = errors.New("image dimensions exceed the maximum of 40 megapixels")
+
+// Decode membaca dimensi gambar dari header terlebih dahulu dan hanya mendekode seluruh gambar jika
+// jumlah pikselnya tidak melebihi MaxSourcePixels. Decoder format harus sudah diregistrasi oleh pemanggil.
+func Decode(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if int64(config.Width)*int64(config.Height) > MaxSourcePixels {
+		return nil, fmt.Errorf("%w (%dx%d)", ErrTooManyPixels, config.Width, config.Height)
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	return decoded, err
+}
@@ -0,0 +1,75 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+)
+
+// ErrWebPUnavailable dikembalikan jika encoder WebP (cwebp) tidak terpasang.
+// Library standar Go hanya memiliki decoder WebP, sehingga encoding memakai binary cwebp dari libwebp.
+var ErrWebPUnavailable = errors.New("webp encoder (cwebp) is not available")
+
+var (
+	cwebpOnce sync.Once
+	cwebpPath string
+)
+
+// WebPSupported melaporkan apakah binary cwebp tersedia di PATH.
+func WebPSupported() bool {
+	cwebpOnce.Do(func() {
+		cwebpPath, _ = exec.LookPath("cwebp")
+	})
+	return cwebpPath != ""
+}
+
+// EncodeJPEG meng-encode gambar ke JPEG dengan kualitas tertentu (1-100).
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeWebP meng-encode gambar ke WebP lossy dengan kualitas tertentu (0-100) melalui cwebp.
+// Gambar ditulis sebagai PNG ke file sementara agar tidak ada kehilangan kualitas sebelum encoding.
+func EncodeWebP(img image.Image, quality int) ([]byte, error) {
+	if !WebPSupported() {
+		return nil, ErrWebPUnavailable
+	}
+	dir, err := os.MkdirTemp("", "webp-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "in.png")
+	output := filepath.Join(dir, "out.webp")
+	f, err := os.Create(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := (&png.Encoder{CompressionLevel: png.BestSpeed}).Encode(f, img); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(cwebpPath, "-quiet", "-q", fmt.Sprint(quality), "-metadata", "none", input, "-o", output)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("cwebp failed: %v: %s", err, stderr.String())
+	}
+	return os.ReadFile(output)
+}
@@ -0,0 +1,182 @@
+// Package imaging berisi utilitas pemrosesan gambar marker: pembersihan metadata EXIF,
+// koreksi orientasi, dan perubahan ukuran, hanya dengan library standar Go.
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Tag EXIF yang relevan.
+const (
+	exifTagOrientation = 0x0112
+	exifTagGPSInfo     = 0x8825
+)
+
+// tiffTypeSizes adalah ukuran byte per komponen untuk setiap tipe data TIFF (indeks = kode tipe).
+var tiffTypeSizes = [...]int{0, 1, 1, 2, 4, 8, 1, 1, 2, 4, 8, 4, 8}
+
+// StripGPS mengembalikan salinan data gambar tanpa informasi lokasi GPS.
+// Untuk JPEG, seluruh isi GPS IFD pada segmen EXIF dan properti GPS pada paket XMP dikosongkan sementara
+// metadata lain tetap utuh. Untuk PNG, chunk eXIf dan chunk XMP dihapus. Format lain dikembalikan apa adanya.
+func StripGPS(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		return stripJPEGGPS(data)
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return stripPNGExif(data)
+	}
+	return data
+}
+
+// Orientation membaca tag Orientation EXIF (1-8) dari JPEG. Mengembalikan 1 jika tidak ada.
+func Orientation(data []byte) int {
+	orientation := 1
+	forEachJPEGExif(data, func(tiff []byte) {
+		order, ifd0, ok := tiffHeader(tiff)
+		if !ok {
+			return
+		}
+		forEachIFDEntry(tiff, order, ifd0, func(entry int, tag uint16) {
+			if tag == exifTagOrientation && entry+10 <= len(tiff) {
+				if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+					orientation = v
+				}
+			}
+		})
+	})
+	return orientation
+}
+
+// stripJPEGGPS mengosongkan GPS IFD pada setiap segmen APP1 EXIF di salinan data JPEG.
+func stripJPEGGPS(data []byte) []byte {
+	out := append([]byte{}, data...)
+	forEachJPEGExif(out, func(tiff []byte) {
+		order, ifd0, ok := tiffHeader(tiff)
+		if !ok {
+			return
+		}
+		forEachIFDEntry(tiff, order, ifd0, func(entry int, tag uint16) {
+			if tag == exifTagGPSInfo {
+				clearIFD(tiff, order, int(order.Uint32(tiff[entry+8:])))
+			}
+		})
+	})
+	stripJPEGXMPGPS(out)
+	return out
+}
+
+// forEachJPEGExif memanggil fn untuk isi TIFF dari setiap segmen APP1 "Exif". Slice yang diberikan
+// merujuk langsung ke data sehingga fn dapat memodifikasinya di tempat.
+func forEachJPEGExif(data []byte, fn func(tiff []byte)) {
+	forEachJPEGSegment(data, func(marker byte, segment []byte) {
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			fn(segment[6:])
+		}
+	})
+}
+
+// forEachJPEGSegment memanggil fn untuk isi setiap segmen metadata JPEG sebelum Start of Scan.
+// Slice yang diberikan merujuk langsung ke data sehingga fn dapat memodifikasinya di tempat.
+func forEachJPEGSegment(data []byte, fn func(marker byte, segment []byte)) {
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return
+		}
+		marker := data[i+1]
+		if marker == 0xD8 || (marker >= 0xD0 && marker <= 0xD7) || marker == 0x01 || marker == 0xFF {
+			i += 2
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 { // Start of Scan / End of Image: metadata sudah habis
+			return
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return
+		}
+		fn(marker, data[i+4:end])
+		i = end
+	}
+}
+
+// tiffHeader membaca urutan byte dan offset IFD0 dari header TIFF.
+func tiffHeader(tiff []byte) (binary.ByteOrder, int, bool) {
+	if len(tiff) < 8 {
+		return nil, 0, false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil, 0, false
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return nil, 0, false
+	}
+	return order, int(order.Uint32(tiff[4:])), true
+}
+
+// forEachIFDEntry memanggil fn untuk setiap entri 12-byte pada IFD di offset tertentu.
+func forEachIFDEntry(tiff []byte, order binary.ByteOrder, offset int, fn func(entry int, tag uint16)) {
+	if offset <= 0 || offset+2 > len(tiff) {
+		return
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return
+		}
+		fn(entry, order.Uint16(tiff[entry:]))
+	}
+}
+
+// clearIFD mengosongkan semua entri pada IFD beserta nilai yang disimpan di luar entri,
+// lalu menyetel jumlah entri menjadi nol sehingga IFD tetap valid tetapi tidak berisi data.
+func clearIFD(tiff []byte, order binary.ByteOrder, offset int) {
+	if offset <= 0 || offset+2 > len(tiff) {
+		return
+	}
+	forEachIFDEntry(tiff, order, offset, func(entry int, tag uint16) {
+		valueType := int(order.Uint16(tiff[entry+2:]))
+		count := int(order.Uint32(tiff[entry+4:]))
+		if valueType > 0 && valueType < len(tiffTypeSizes) {
+			size := tiffTypeSizes[valueType] * count
+			if size > 4 {
+				valueOffset := int(order.Uint32(tiff[entry+8:]))
+				if valueOffset > 0 && valueOffset+size <= len(tiff) {
+					clear(tiff[valueOffset : valueOffset+size])
+				}
+			}
+		}
+		clear(tiff[entry : entry+12])
+	})
+	order.PutUint16(tiff[offset:], 0)
+}
+
+// stripPNGExif menghapus chunk eXIf dan chunk iTXt XMP dari PNG. Chunk lain tidak diubah sehingga CRC tetap valid.
+func stripPNGExif(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:8]...)
+	i := 8
+	for i+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return data
+		}
+		chunkType := string(data[i+4 : i+8])
+		isXMP := chunkType == "iTXt" && bytes.HasPrefix(data[i+8:end-4], []byte(pngXMPKeyword+"\x00"))
+		if chunkType != "eXIf" && !isXMP {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out
+}
@@ -0,0 +1,245 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// Letak struktur di dalam TIFF buatan testTIFF.
+const (
+	testGPSIFDOffset  = 38
+	testGPSDataOffset = 68
+	testTIFFLength    = 92
+)
+
+// testTIFF membuat blok TIFF EXIF berisi Orientation 6 pada IFD0 dan GPS IFD dengan GPSLatitudeRef serta
+// GPSLatitude (rational yang disimpan di luar entri).
+func testTIFF(order binary.ByteOrder) []byte {
+	tiff := make([]byte, testTIFFLength)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	entry := func(offset int, tag, valueType uint16, count uint32) {
+		order.PutUint16(tiff[offset:], tag)
+		order.PutUint16(tiff[offset+2:], valueType)
+		order.PutUint32(tiff[offset+4:], count)
+	}
+
+	order.PutUint16(tiff[8:], 2)
+	entry(10, exifTagOrientation, 3, 1)
+	order.PutUint16(tiff[18:], 6)
+	entry(22, exifTagGPSInfo, 4, 1)
+	order.PutUint32(tiff[30:], testGPSIFDOffset)
+
+	order.PutUint16(tiff[testGPSIFDOffset:], 2)
+	entry(testGPSIFDOffset+2, 1, 2, 2) // GPSLatitudeRef "S"
+	copy(tiff[testGPSIFDOffset+10:], "S\x00")
+	entry(testGPSIFDOffset+14, 2, 5, 3) // GPSLatitude, 3 rational
+	order.PutUint32(tiff[testGPSIFDOffset+22:], testGPSDataOffset)
+	for i, value := range []uint32{6, 1, 54, 1, 1234, 100} {
+		order.PutUint32(tiff[testGPSDataOffset+4*i:], value)
+	}
+	return tiff
+}
+
+func jpegSegment(marker byte, content []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(content)+2))
+	return append(segment, content...)
+}
+
+func testJPEG(segments ...[]byte) []byte {
+	data := []byte{0xFF, 0xD8}
+	for _, segment := range segments {
+		data = append(data, segment...)
+	}
+	// Data setelah Start of Scan tidak boleh disentuh
+	data = append(data, jpegSegment(0xDA, []byte("GPSLatitude in scan data"))...)
+	return append(data, 0xFF, 0xD9)
+}
+
+const testXMP = `<x:xmpmeta><rdf:Description exif:GPSLatitude="6,54.2S" xmp:Rating="5">` +
+	`<exif:GPSLongitude>107,36.5E</exif:GPSLongitude><drone-dji:GpsAltitude/><dc:title>Braga</dc:title>` +
+	`</rdf:Description></x:xmpmeta>`
+
+func TestStripGPSJPEG(t *testing.T) {
+	exif := func(order binary.ByteOrder) []byte {
+		return jpegSegment(0xE1, append([]byte("Exif\x00\x00"), testTIFF(order)...))
+	}
+	xmp := jpegSegment(0xE1, []byte(jpegXMPPrefix+testXMP))
+	tests := []struct {
+		name            string
+		data            []byte
+		order           binary.ByteOrder // nil jika tidak ada EXIF
+		wantOrientation int
+	}{
+		{"little endian exif", testJPEG(exif(binary.LittleEndian)), binary.LittleEndian, 6},
+		{"big endian exif", testJPEG(exif(binary.BigEndian)), binary.BigEndian, 6},
+		{"exif and xmp", testJPEG(jpegSegment(0xE0, []byte("JFIF\x00")), exif(binary.LittleEndian), xmp), binary.LittleEndian, 6},
+		{"xmp only", testJPEG(xmp), nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := append([]byte{}, tt.data...)
+			out := StripGPS(tt.data)
+			if !bytes.Equal(tt.data, original) {
+				t.Fatal("StripGPS modified its input")
+			}
+			if len(out) != len(tt.data) {
+				t.Fatalf("length changed from %d to %d", len(tt.data), len(out))
+			}
+			if got := Orientation(out); got != tt.wantOrientation {
+				t.Errorf("Orientation = %d, want %d", got, tt.wantOrientation)
+			}
+
+			if tt.order != nil {
+				start := bytes.Index(out, []byte("Exif\x00\x00")) + 6
+				tiff := out[start : start+testTIFFLength]
+				if count := tt.order.Uint16(tiff[testGPSIFDOffset:]); count != 0 {
+					t.Errorf("GPS IFD still has %d entries", count)
+				}
+				if gps := tiff[testGPSIFDOffset+2 : testTIFFLength]; !bytes.Equal(gps, make([]byte, len(gps))) {
+					t.Errorf("GPS entries or values not cleared: % x", gps)
+				}
+			}
+
+			metadata := out[:bytes.Index(out, []byte{0xFF, 0xDA})]
+			for _, leaked := range []string{"GPSLatitude", "GPSLongitude", "GpsAltitude", "54.2S", "36.5E"} {
+				if bytes.Contains(metadata, []byte(leaked)) {
+					t.Errorf("metadata still contains %q", leaked)
+				}
+			}
+			if bytes.Contains(tt.data, []byte("dc:title")) {
+				for _, kept := range []string{`xmp:Rating="5"`, "<dc:title>Braga</dc:title>"} {
+					if !bytes.Contains(out, []byte(kept)) {
+						t.Errorf("non-GPS XMP %q was removed", kept)
+					}
+				}
+			}
+			if !bytes.HasSuffix(out, append(jpegSegment(0xDA, []byte("GPSLatitude in scan data")), 0xFF, 0xD9)) {
+				t.Error("scan data was modified")
+			}
+		})
+	}
+}
+
+func pngChunk(chunkType string, content []byte) []byte {
+	chunk := make([]byte, 4, 12+len(content))
+	binary.BigEndian.PutUint32(chunk, uint32(len(content)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, content...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestStripGPSPNG(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	base := encoded.Bytes()
+	iend := len(base) - 12
+	withChunks := func(chunks ...[]byte) []byte {
+		data := append([]byte{}, base[:iend]...)
+		for _, chunk := range chunks {
+			data = append(data, chunk...)
+		}
+		return append(data, base[iend:]...)
+	}
+	exif := pngChunk("eXIf", testTIFF(binary.BigEndian))
+	xmp := pngChunk("iTXt", []byte(pngXMPKeyword+"\x00\x00\x00\x00\x00"+testXMP))
+	comment := pngChunk("tEXt", []byte("Comment\x00Braga"))
+
+	tests := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{"no metadata", base, base},
+		{"exif removed", withChunks(exif), base},
+		{"xmp removed, text kept", withChunks(comment, xmp), withChunks(comment)},
+		{"exif and xmp removed", withChunks(xmp, comment, exif), withChunks(comment)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := StripGPS(tt.data)
+			if !bytes.Equal(out, tt.want) {
+				t.Fatalf("unexpected output:\n got % x\nwant % x", out, tt.want)
+			}
+			if _, err := png.Decode(bytes.NewReader(out)); err != nil {
+				t.Errorf("output is not a valid PNG: %v", err)
+			}
+		})
+	}
+}
+
+func TestStripGPSPassthrough(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"gif", []byte("GIF89a GPSLatitude")},
+		{"truncated jpeg segment", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF, 'E', 'x'}},
+		{"jpeg with bad tiff header", testJPEG(jpegSegment(0xE1, []byte("Exif\x00\x00XX\x00\x2a")))},
+		{"truncated png chunk", append([]byte("\x89PNG\r\n\x1a\n"), 0, 0, 0xFF, 0xFF, 'e', 'X', 'I', 'f', 0, 0, 0, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if out := StripGPS(tt.data); !bytes.Equal(out, tt.data) {
+				t.Errorf("StripGPS changed data it cannot parse: % x", out)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatal(err)
+	}
+	// withSize menulis ulang dimensi pada chunk IHDR (tepat setelah signature) beserta CRC-nya
+	withSize := func(width, height uint32) []byte {
+		data := append([]byte{}, encoded.Bytes()...)
+		binary.BigEndian.PutUint32(data[16:], width)
+		binary.BigEndian.PutUint32(data[20:], height)
+		binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+		return data
+	}
+	tests := []struct {
+		name     string
+		data     []byte
+		wantSize image.Point
+		wantErr  error
+	}{
+		{name: "small png", data: encoded.Bytes(), wantSize: image.Pt(3, 2)},
+		{name: "decompression bomb", data: withSize(100000, 100000), wantErr: ErrTooManyPixels},
+		{name: "wide bomb", data: withSize(40_000_001, 1), wantErr: ErrTooManyPixels},
+		{name: "not an image", data: []byte("hello"), wantErr: image.ErrFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := decoded.Bounds().Size(); got != tt.wantSize {
+				t.Errorf("size = %v, want %v", got, tt.wantSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Mode penyesuaian ukuran gambar terhadap kotak tujuan.
+const (
+	FitContain = "contain" // Seluruh gambar masuk ke dalam kotak, rasio dipertahankan
+	FitCover   = "cover"   // Kotak terisi penuh, kelebihan dipotong dari tengah
+	FitFill    = "fill"    // Gambar diregangkan tepat ke ukuran kotak
+)
+
+// toRGBA mengonversi gambar apa pun ke *image.RGBA dengan origin (0,0) agar piksel mudah diakses.
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// Resize mengubah ukuran gambar menjadi width x height menggunakan rata-rata area (box filter),
+// yang menghasilkan kualitas baik untuk pengecilan. Pembesaran menjadi nearest-neighbor.
+func Resize(src image.Image, width, height int) *image.RGBA {
+	in := toRGBA(src)
+	sw, sh := in.Rect.Dx(), in.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if sw == 0 || sh == 0 || width <= 0 || height <= 0 {
+		return dst
+	}
+
+	scaleX := float64(sw) / float64(width)
+	scaleY := float64(sh) / float64(height)
+	for y := 0; y < height; y++ {
+		y0 := int(float64(y) * scaleY)
+		y1 := max(int(float64(y+1)*scaleY), y0+1)
+		y1 = min(y1, sh)
+		for x := 0; x < width; x++ {
+			x0 := int(float64(x) * scaleX)
+			x1 := max(int(float64(x+1)*scaleX), x0+1)
+			x1 = min(x1, sw)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := in.Pix[sy*in.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					n++
+				}
+			}
+			o := dst.Pix[y*dst.Stride+x*4:]
+			o[0], o[1], o[2], o[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// Transform menyesuaikan gambar ke kotak width x height dengan mode fit (contain, cover, fill).
+// Jika salah satu dimensi bernilai 0, dimensi tersebut dihitung dari rasio aspek gambar.
+// Gambar tidak pernah diperbesar melebihi ukuran aslinya.
+func Transform(src image.Image, width, height int, fit string) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw == 0 || sh == 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+	if width <= 0 && height <= 0 {
+		return toRGBA(src)
+	}
+	if width <= 0 {
+		width = max(1, sw*height/sh)
+	}
+	if height <= 0 {
+		height = max(1, sh*width/sw)
+	}
+	// Jangan memperbesar: kotak tujuan dibatasi pada ukuran asli dengan rasio kotak tetap
+	if width > sw || height > sh {
+		ratio := min(float64(sw)/float64(width), float64(sh)/float64(height))
+		width, height = max(1, int(float64(width)*ratio)), max(1, int(float64(height)*ratio))
+	}
+
+	switch fit {
+	case FitFill:
+		return Resize(src, width, height)
+	case FitCover:
+		// Potong bagian tengah gambar sumber dengan rasio yang sama dengan kotak tujuan
+		cropW, cropH := sw, sw*height/width
+		if cropH > sh {
+			cropW, cropH = sh*width/height, sh
+		}
+		x0 := b.Min.X + (sw-cropW)/2
+		y0 := b.Min.Y + (sh-cropH)/2
+		cropped := image.NewRGBA(image.Rect(0, 0, cropW, cropH))
+		draw.Draw(cropped, cropped.Bounds(), src, image.Pt(x0, y0), draw.Src)
+		return Resize(cropped, width, height)
+	default: // FitContain
+		ratio := min(float64(width)/float64(sw), float64(height)/float64(sh))
+		return Resize(src, max(1, int(float64(sw)*ratio+0.5)), max(1, int(float64(sh)*ratio+0.5)))
+	}
+}
+
+// Thumbnail memperkecil gambar agar sisi terpanjangnya tidak melebihi maxSize.
+func Thumbnail(src image.Image, maxSize int) *image.RGBA {
+	return Transform(src, maxSize, maxSize, FitContain)
+}
+
+// ApplyOrientation memutar/membalik gambar sesuai nilai Orientation EXIF (1-8)
+// sehingga foto dari ponsel tampil tegak setelah metadata dibuang.
+func ApplyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	in := toRGBA(src)
+	w, h := in.Rect.Dx(), in.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 { // Orientasi 5-8 menukar lebar dan tinggi
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], in.Pix[y*in.Stride+x*4:y*in.Stride+x*4+4])
+		}
+	}
+	return dst
+}
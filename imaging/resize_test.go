@@ -0,0 +1,88 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// testStripes membuat gambar width x height dengan seperempat kiri merah dan sisanya biru.
+func testStripes(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/4 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestTransform(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	tests := []struct {
+		name          string
+		src           image.Image
+		width, height int
+		fit           string
+		wantSize      image.Point
+		wantLeft      color.RGBA // Warna piksel kiri atas hasil
+	}{
+		{"contain keeps aspect", testStripes(400, 200), 100, 100, FitContain, image.Pt(100, 50), red},
+		{"unknown fit is contain", testStripes(400, 200), 100, 100, "stretch", image.Pt(100, 50), red},
+		{"cover crops the center", testStripes(400, 200), 100, 100, FitCover, image.Pt(100, 100), blue},
+		{"fill stretches", testStripes(400, 200), 100, 100, FitFill, image.Pt(100, 100), red},
+		{"width only", testStripes(400, 200), 100, 0, FitContain, image.Pt(100, 50), red},
+		{"height only", testStripes(400, 200), 0, 50, FitCover, image.Pt(100, 50), red},
+		{"no size returns original", testStripes(400, 200), 0, 0, FitCover, image.Pt(400, 200), red},
+		{"contain never upscales", testStripes(400, 200), 800, 800, FitContain, image.Pt(200, 100), red},
+		{"cover never upscales", testStripes(400, 200), 800, 800, FitCover, image.Pt(200, 200), blue},
+		{"fill upscale keeps box ratio", testStripes(400, 200), 1000, 100, FitFill, image.Pt(400, 40), red},
+		{"portrait cover", testStripes(200, 400), 100, 100, FitCover, image.Pt(100, 100), red},
+		{"tiny result is at least one pixel", testStripes(400, 4), 100, 0, FitContain, image.Pt(100, 1), red},
+		{"offset bounds", testStripes(400, 200).SubImage(image.Rect(100, 0, 400, 200)), 150, 0, FitContain, image.Pt(150, 100), blue},
+		{"empty source", image.NewRGBA(image.Rect(0, 0, 0, 0)), 100, 100, FitContain, image.Pt(0, 0), color.RGBA{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Transform(tt.src, tt.width, tt.height, tt.fit)
+			if size := got.Bounds().Size(); size != tt.wantSize {
+				t.Fatalf("size = %v, want %v", size, tt.wantSize)
+			}
+			if got.Bounds().Min != (image.Point{}) {
+				t.Errorf("bounds start at %v, want origin", got.Bounds().Min)
+			}
+			if tt.wantSize != (image.Point{}) && got.RGBAAt(0, 0) != tt.wantLeft {
+				t.Errorf("top-left pixel = %v, want %v", got.RGBAAt(0, 0), tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestResizeAveragesArea(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.Set(0, 0, color.RGBA{R: 200, A: 255})
+	src.Set(1, 0, color.RGBA{R: 100, A: 255})
+	src.Set(0, 1, color.RGBA{G: 40, A: 255})
+	src.Set(1, 1, color.RGBA{B: 80, A: 255})
+	tests := []struct {
+		name          string
+		width, height int
+		want          color.RGBA
+	}{
+		{"single pixel averages all", 1, 1, color.RGBA{R: 75, G: 10, B: 20, A: 255}},
+		{"column average", 1, 2, color.RGBA{R: 150, A: 255}},
+		{"upscale is nearest neighbor", 4, 4, color.RGBA{R: 200, A: 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resize(src, tt.width, tt.height).RGBAAt(0, 0); got != tt.want {
+				t.Errorf("pixel = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
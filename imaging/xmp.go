@@ -0,0 +1,56 @@
+package imaging
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// Penanda paket XMP pada JPEG (segmen APP1) dan PNG (chunk iTXt).
+const (
+	jpegXMPPrefix         = "http://ns.adobe.com/xap/1.0/\x00"
+	jpegExtendedXMPPrefix = "http://ns.adobe.com/xmp/extension/\x00"
+	pngXMPKeyword         = "XML:com.adobe.xmp"
+)
+
+// xmpGPSPatterns mencocokkan properti XMP yang berisi lokasi, misalnya exif:GPSLatitude atau
+// drone-dji:GpsLongitude, dalam bentuk elemen, elemen kosong, maupun atribut.
+var xmpGPSPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?s)<[A-Za-z0-9_.-]+:(?i:gps)[A-Za-z0-9_]*(\s[^>]*)?>.*?</[A-Za-z0-9_.-]+:(?i:gps)[A-Za-z0-9_]*\s*>`),
+	regexp.MustCompile(`<[A-Za-z0-9_.-]+:(?i:gps)[A-Za-z0-9_]*(\s[^>]*)?/>`),
+	regexp.MustCompile(`\s[A-Za-z0-9_.-]+:(?i:gps)[A-Za-z0-9_]*\s*=\s*("[^"]*"|'[^']*')`),
+}
+
+// stripJPEGXMPGPS mengosongkan properti GPS pada setiap paket XMP JPEG di tempat. Properti diganti spasi
+// dengan panjang yang sama sehingga panjang segmen dan struktur XML tetap valid. Extended XMP dikosongkan
+// seluruhnya karena satu properti bisa terpotong di antara beberapa segmen.
+func stripJPEGXMPGPS(data []byte) {
+	forEachJPEGSegment(data, func(marker byte, segment []byte) {
+		if marker != 0xE1 {
+			return
+		}
+		switch {
+		case bytes.HasPrefix(segment, []byte(jpegXMPPrefix)):
+			blankXMPGPS(segment[len(jpegXMPPrefix):])
+		case bytes.HasPrefix(segment, []byte(jpegExtendedXMPPrefix)):
+			// Header extended XMP: GUID 32 byte, panjang total 4 byte, offset 4 byte
+			if header := len(jpegExtendedXMPPrefix) + 40; len(segment) > header {
+				blank(segment[header:])
+			}
+		}
+	})
+}
+
+// blankXMPGPS mengganti setiap properti GPS pada paket XMP dengan spasi.
+func blankXMPGPS(packet []byte) {
+	for _, pattern := range xmpGPSPatterns {
+		for _, loc := range pattern.FindAllIndex(packet, -1) {
+			blank(packet[loc[0]:loc[1]])
+		}
+	}
+}
+
+func blank(b []byte) {
+	for i := range b {
+		b[i] = ' '
+	}
+}
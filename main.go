@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http" // Import net/http untuk StatusUnauthorized
 	"os"
+	"strconv"
 	"strings" // Import strings untuk AuthMiddleware
+	"time"
 
+	"ulyngo/blobstore"   // Import blobstore untuk penyimpanan file
 	"ulyngo/controllers" // Import controllers
 	"ulyngo/db/seeders"  // Import seeders untuk seeding data awal
+	"ulyngo/directions"  // Import directions untuk penyedia rute
+	"ulyngo/imaging"     // Import imaging untuk cache gambar
 	"ulyngo/models"      // Import models untuk AutoMigrate
+	"ulyngo/places"      // Import places untuk pencarian tempat
+	"ulyngo/sentiment"   // Import sentiment untuk analisis sentimen ulasan
 	"ulyngo/utils"       // Import utils
 
 	"github.com/gin-gonic/gin"
@@ -57,6 +65,39 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware mengisi informasi pengguna ke konteks Gin jika token JWT valid dikirim,
+// tetapi tetap meneruskan permintaan tanpa token (atau dengan token tidak valid) sebagai anonim.
+// Dipakai untuk rute publik yang perilakunya sedikit berbeda untuk pengguna yang login.
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := c.GetHeader("Authorization")
+		if strings.HasPrefix(tokenString, "Bearer ") {
+			if claims, err := utils.VerifyToken(strings.TrimPrefix(tokenString, "Bearer ")); err == nil {
+				c.Set("userID", claims["sub"])
+				c.Set("username", claims["username"])
+				c.Set("email", claims["email"])
+				c.Set("role", claims["role"])
+			}
+		}
+		c.Next()
+	}
+}
+
+// envInt membaca variabel lingkungan bertipe bilangan bulat positif, atau mengembalikan fallback
+// jika tidak diset atau tidak valid.
+func envInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("WARNING: Invalid value for %s (%q). Using default %d.", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
 // DBRefresh menghapus semua tabel yang terkait dengan model dan kemudian melakukan AutoMigrate.
 // Ini SANGAT berisiko untuk produksi karena akan MENGHILANGKAN SEMUA DATA.
 // Gunakan HANYA untuk lingkungan pengembangan/pengujian.
@@ -90,32 +131,18 @@ func main() {
 		log.Printf("Warning: Error loading .env file: %v", err)
 	}
 
-	// os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
-
-	// //Create Google Cloud client
-	// ctx := context.Background()
-	// client, err := storage.NewClient(ctx)
-	// if err != nil {
-	// 	log.Fatalf("Failed to create client: %v", err)
-	// }
-	// defer client.Close()
-
 	// END LOCAL MODE
 
-	// credsJSON := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS_JSON")
-	// if credsJSON == "" {
-	// 	log.Fatal("❌ GOOGLE_APPLICATION_CREDENTIALS_JSON not found")
-	// }
-
-	// // Inisialisasi client dengan credentials dari JSON string
-	// ctx := context.Background()
-	// client, err := storage.NewClient(ctx, option.WithCredentialsJSON([]byte(credsJSON)))
-	// if err != nil {
-	// 	log.Fatalf("❌ Failed to create client: %v", err)
-	// }
-	// defer client.Close()
-
-	// log.Println("✅ GCS client created successfully")
+	// Inisialisasi penyimpanan file (gambar marker). BLOB_STORE=gcs memakai Google Cloud Storage,
+	// selain itu file disimpan di filesystem lokal.
+	ctx := context.Background()
+	blobStore, err := blobstore.NewFromEnv(ctx)
+	if err != nil {
+		log.Fatalf("Failed to initialize blob store: %v", err)
+	}
+	if gcsStore, ok := blobStore.(*blobstore.GCSStore); ok {
+		defer gcsStore.Close()
+	}
 
 	// Inisialisasi koneksi database
 	utils.ConnectDatabase()
@@ -145,8 +172,24 @@ func main() {
 			&models.MarkerReview{},
 			&models.Route{},
 			&models.UserActivityLog{},
+			&models.MarkerRevision{},
+			&models.MarkerRedirect{},
+			&models.MarkerHasTagTrash{},
+			&models.MarkerTagAlias{},
+			&models.ReviewVote{},
+			&models.ReviewReply{},
+			&models.ReviewReport{},
+			&models.Favorite{},
+			&models.Collection{},
+			&models.CollectionItem{},
+			&models.MarkerRanking{},
+			&models.Translation{},
+			&models.Event{},
 		)
 		log.Println("AutoMigrate completed.")
+		if err := controllers.BackfillCategoryPaths(utils.DB); err != nil {
+			log.Printf("Failed to backfill category paths: %v", err)
+		}
 	}
 
 	// Mengatur mode Gin (misal: debug, release)
@@ -185,10 +228,61 @@ func main() {
 
 	// Inisialisasi controller dengan dependensi database yang sudah terhubung
 	authController := controllers.NewAuthController(utils.DB)
-	markerController := controllers.NewMarkerController(utils.DB)
+	markerViews := controllers.NewMarkerViewBuffer(utils.DB)
+	markerController := controllers.NewMarkerController(utils.DB, markerViews)
 	markerCategoryController := controllers.NewMarkerCategoryController(utils.DB)
 	markerTagController := controllers.NewMarkerTagController(utils.DB)
-	routeController := controllers.NewRouteController(utils.DB)
+	directionsProvider, err := directions.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize directions provider: %v", err)
+	}
+	placesProvider, err := places.NewFromEnv(utils.DB)
+	if err != nil {
+		log.Fatalf("Failed to initialize places provider: %v", err)
+	}
+	routeController := controllers.NewRouteController(utils.DB, directionsProvider, placesProvider, controllers.NewVertexTripExtractorFromEnv())
+	sentimentAnalyzer, err := sentiment.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize sentiment analyzer: %v", err)
+	}
+	sentimentWorker := controllers.NewReviewSentimentWorker(utils.DB, sentimentAnalyzer, envInt("SENTIMENT_QUEUE_SIZE", 1000))
+	reviewController := controllers.NewReviewController(utils.DB, sentimentWorker)
+	moderationController := controllers.NewModerationController(utils.DB)
+	activityController := controllers.NewActivityController(utils.DB)
+	favoriteController := controllers.NewFavoriteController(utils.DB)
+	collectionController := controllers.NewCollectionController(utils.DB)
+	trendingController := controllers.NewTrendingController(utils.DB)
+	preferenceController := controllers.NewPreferenceController(utils.DB)
+	recommendationController := controllers.NewRecommendationController(utils.DB)
+	translationController := controllers.NewTranslationController(utils.DB)
+	eventController := controllers.NewEventController(utils.DB)
+	imageCacheDir := os.Getenv("IMAGE_CACHE_DIR")
+	if imageCacheDir == "" {
+		imageCacheDir = "./cache/images"
+	}
+	imageCache, err := imaging.NewDiskCache(imageCacheDir, int64(envInt("IMAGE_CACHE_MAX_MB", 512))<<20)
+	if err != nil {
+		log.Fatalf("Failed to initialize image cache: %v", err)
+	}
+	markerImageController := controllers.NewMarkerImageController(utils.DB, blobStore, imageCache)
+	trashController := controllers.NewTrashController(utils.DB, blobStore, time.Duration(envInt("TRASH_RETENTION_DAYS", 30))*24*time.Hour)
+
+	// Purge trash terjadwal memakai logika yang sama dengan endpoint admin
+	trashController.StartPurgeJob(time.Duration(envInt("TRASH_PURGE_INTERVAL_HOURS", 24)) * time.Hour)
+
+	// Peringkat trending dihitung ulang di background agar endpoint trending cukup membaca tabel peringkat
+	trendingController.StartRankingJob(time.Duration(envInt("TRENDING_RECOMPUTE_INTERVAL_MINUTES", 15)) * time.Minute)
+
+	// view_count marker ditulis berkala dalam satu UPDATE, bukan di setiap GET /api/markers/:id
+	markerViews.Start(time.Duration(envInt("MARKER_VIEW_FLUSH_INTERVAL_SECONDS", 10)) * time.Second)
+
+	// Analisis sentimen ulasan berjalan di background, termasuk backfill berkala untuk ulasan yang terlewat
+	sentimentWorker.Start(envInt("SENTIMENT_WORKERS", 2), time.Duration(envInt("SENTIMENT_BACKFILL_INTERVAL_MINUTES", 60))*time.Minute)
+
+	// File upload disajikan langsung oleh server jika memakai penyimpanan lokal
+	if localStore, ok := blobStore.(*blobstore.LocalStore); ok {
+		router.Static(localStore.URLPrefix, localStore.Dir)
+	}
 
 	// Grup Rute Autentikasi
 	authRoutes := router.Group("/api/auth")
@@ -198,16 +292,38 @@ func main() {
 	}
 
 	// Rute Perjalanan (Beberapa rute bersifat publik, beberapa dilindungi)
-	// router.POST("/api/routes", routeController.GetDirections)                       // Publik
-	router.GET("/api/markers", markerController.GetMarkers)                         // Publik (mendapatkan semua marker, tidak difilter berdasarkan user)
-	router.GET("/api/marker/categories", markerCategoryController.GetAllCategories) // Publik (mendapatkan semua kategori marker)
-	router.GET("/api/marker/tags", markerTagController.GetAllTags)                  // Publik (mendapatkan semua tag marker)
+	router.GET("/api/routes", routeController.GetPublicRoutes)                                      // Publik (feed rute public, filter ?user_id= dan ?q=)
+	router.GET("/api/routes/:id", routeController.GetPublicRoute)                                   // Publik (detail rute public)
+	router.GET("/api/routes/:id/export", OptionalAuthMiddleware(), routeController.ExportRoute)     // Publik (unduh GPX/KML/GeoJSON; rute private hanya untuk pemiliknya)
+	router.POST("/api/routes/corridor", OptionalAuthMiddleware(), routeController.GetRouteCorridor) // Publik (marker di sepanjang rute dari polyline atau route_id)
+	router.GET("/api/markers", markerController.GetMarkers)                                         // Publik (mendapatkan semua marker, tidak difilter berdasarkan user)
+	router.GET("/api/markers/trending", trendingController.GetTrendingMarkers)                      // Publik (marker trending dari tabel peringkat)
+	router.GET("/api/markers/:id", OptionalAuthMiddleware(), markerController.GetMarkerByID)        // Publik (detail marker, mengikuti redirect hasil merge; view dicatat untuk trending)
+	router.GET("/api/marker/categories", markerCategoryController.GetAllCategories)                 // Publik (mendapatkan semua kategori marker)
+	router.GET("/api/marker/categories/tree", markerCategoryController.GetCategoryTree)             // Publik (kategori sebagai pohon bersarang)
+	router.GET("/api/marker/categories/icons", markerCategoryController.GetCategoryIcons)           // Publik (daftar kunci ikon kategori yang valid)
+	router.GET("/api/marker/categories/:id", markerCategoryController.GetCategoryByID)              // Publik (detail kategori dengan breadcrumb dan subkategori)
+	router.GET("/api/marker/tags", markerTagController.GetAllTags)                                  // Publik (mendapatkan semua tag marker beserta jumlah pemakaian dan alias)
+	router.GET("/api/marker/tags/cloud", markerTagController.GetTagCloud)                           // Publik (tag terpopuler dengan bobot tampilan)
+	router.GET("/api/marker/tags/:id", markerTagController.GetTagByID)                              // Publik (detail tag berdasarkan ID, nama, atau alias)
+	router.GET("/img/:imageID", OptionalAuthMiddleware(), markerImageController.ServeImage)         // Publik (gambar marker dengan resize & negosiasi format)
+	router.GET("/api/markers/:id/reviews", reviewController.GetReviews)                             // Publik (ulasan marker)
+	router.GET("/api/markers/:id/reviews/summary", reviewController.GetReviewSummary)               // Publik (rata-rata & histogram rating)
+	router.GET("/api/collections", collectionController.GetPublicCollections)                       // Publik (daftar koleksi public)
+	router.GET("/api/collections/:id", collectionController.GetPublicCollection)                    // Publik (koleksi public, atau unlisted dengan ?token=)
+	router.GET("/api/events", eventController.GetEvents)                                            // Publik (kejadian event dalam rentang waktu, filter bbox/kota/kategori)
+	router.GET("/api/events/:id", eventController.GetEventByID)                                     // Publik (detail event dan kejadian berikutnya)
+	router.GET("/api/markers/:id/events", eventController.GetMarkerEvents)                          // Publik (event mendatang di satu marker)
+	router.GET("/api/markers/:id/events.ics", eventController.GetMarkerEventsICS)                   // Publik (feed iCalendar per marker)
+	router.GET("/api/cities/:city/events.ics", eventController.GetCityEventsICS)                    // Publik (feed iCalendar per kota)
 
 	// Rute CRUD Marker yang Dilindungi dengan AuthMiddleware
 	protectedMarkerRoutes := router.Group("/api/markers")
 	protectedMarkerCategoriesRoutes := router.Group("/api/marker/categories")
 	protectedMarkerTagsRoutes := router.Group("/api/marker/tags")
 	protectedServicesRoutes := router.Group("/api")
+	moderationRoutes := router.Group("/api/moderation")
+	adminRoutes := router.Group("/api/admin")
 	// Middleware untuk membatasi akses hanya untuk role admin
 	adminOnly := func(c *gin.Context) {
 		role, exists := c.Get("role")
@@ -218,23 +334,125 @@ func main() {
 		}
 		c.Next()
 	}
+	// Middleware untuk membatasi akses hanya untuk moderator (admin juga termasuk moderator)
+	moderatorOnly := func(c *gin.Context) {
+		role, exists := c.Get("role")
+		if !exists || (role != "admin" && role != "moderator") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied. Moderator privileges are required."})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
 
 	// Grup Rute yang Dilindungi (membutuhkan otentikasi)
 	protectedServicesRoutes.Use(AuthMiddleware())
 	{
 		// Rute rute (termasuk penyimpanan ke DB, sekarang dilindungi)
-		// protectedServicesRoutes.POST("/routes", routeController.GetDirections)               // Pindahkan ke protectedRoutes
 		// protectedServicesRoutes.POST("/places/search", routeController.SearchPlaces)         // Pindahkan ke protectedRoutes
-		// protectedServicesRoutes.POST("/analyze-sentiment", routeController.AnalyzeSentiment) // Pindahkan ke protectedRoutes
+		protectedServicesRoutes.POST("/analyze-sentiment", reviewController.AnalyzeSentiment)
 		protectedServicesRoutes.POST("/plan-trip", routeController.PlanTripFromQuery)
+
+		// Rute tersimpan milik pengguna (dari directions atau rencana perjalanan)
+		protectedServicesRoutes.GET("/me/routes", routeController.GetMyRoutes)
+		protectedServicesRoutes.POST("/me/routes", routeController.SaveRoute)
+		protectedServicesRoutes.GET("/me/routes/:id", routeController.GetMyRoute)
+		protectedServicesRoutes.PUT("/me/routes/:id", routeController.UpdateRoute) // Ganti nama dan/atau is_public
+		protectedServicesRoutes.DELETE("/me/routes/:id", routeController.DeleteRoute)
+		protectedServicesRoutes.POST("/routes/export", routeController.ExportTripPlan) // Ekspor directions/rencana perjalanan yang belum disimpan
+		protectedServicesRoutes.GET("/me/activity", activityController.GetMyActivity)  // Log aktivitas & notifikasi pengguna
+
+		// Favorit dan koleksi marker milik pengguna
+		protectedServicesRoutes.GET("/me/favorites", favoriteController.GetMyFavorites)
+		protectedServicesRoutes.PUT("/me/favorites/:markerID", favoriteController.AddFavorite)
+		protectedServicesRoutes.DELETE("/me/favorites/:markerID", favoriteController.RemoveFavorite)
+		protectedServicesRoutes.GET("/me/collections", collectionController.GetMyCollections)
+		protectedServicesRoutes.POST("/me/collections", collectionController.CreateCollection)
+		protectedServicesRoutes.GET("/me/collections/:id", collectionController.GetMyCollection)
+		protectedServicesRoutes.PUT("/me/collections/:id", collectionController.UpdateCollection)
+		protectedServicesRoutes.DELETE("/me/collections/:id", collectionController.DeleteCollection)
+		protectedServicesRoutes.POST("/me/collections/:id/share-token", collectionController.RegenerateShareToken)
+		protectedServicesRoutes.POST("/me/collections/:id/items", collectionController.AddCollectionItem)
+		protectedServicesRoutes.PUT("/me/collections/:id/items/order", collectionController.ReorderCollectionItems)
+		protectedServicesRoutes.PUT("/me/collections/:id/items/:markerID", collectionController.UpdateCollectionItem)
+		protectedServicesRoutes.DELETE("/me/collections/:id/items/:markerID", collectionController.RemoveCollectionItem)
+		protectedServicesRoutes.POST("/collections/:id/copy", collectionController.CopyCollection) // Salin koleksi public/unlisted (?token=) ke koleksi sendiri
+
+		// Preferensi dan rekomendasi marker yang dipersonalisasi
+		protectedServicesRoutes.GET("/me/preferences", preferenceController.GetMyPreferences)
+		protectedServicesRoutes.PUT("/me/preferences", preferenceController.UpdateMyPreferences)
+		protectedServicesRoutes.DELETE("/me/preferences/:key", preferenceController.DeleteMyPreference)
+		protectedServicesRoutes.GET("/me/recommendations", recommendationController.GetMyRecommendations)
+
+		// Event: pemilik marker atau admin
+		protectedServicesRoutes.PUT("/events/:id", eventController.UpdateEvent)
+		protectedServicesRoutes.DELETE("/events/:id", eventController.DeleteEvent)
 	}
 
-	protectedMarkerRoutes.Use(AuthMiddleware(), adminOnly)
+	// Semua pengguna terautentikasi boleh mengirim marker (masuk antrean moderasi jika bukan admin/moderator),
+	// sedangkan perubahan dan penghapusan tetap khusus admin.
+	protectedMarkerRoutes.Use(AuthMiddleware())
 	{
 		// Mengubah rute POST dari "/" menjadi "" untuk menghilangkan trailing slash
-		protectedMarkerRoutes.POST("", markerController.AddMarker)          // Menambah marker
-		protectedMarkerRoutes.PUT("/:id", markerController.UpdateMarker)    // Memperbarui marker berdasarkan ID
-		protectedMarkerRoutes.DELETE("/:id", markerController.DeleteMarker) // Menghapus marker berdasarkan ID
+		protectedMarkerRoutes.POST("", markerController.AddMarker)                     // Menambah / mengirim marker
+		protectedMarkerRoutes.GET("/submissions", markerController.GetMySubmissions)   // Marker kiriman pengguna saat ini
+		protectedMarkerRoutes.PUT("/:id", adminOnly, markerController.UpdateMarker)    // Memperbarui marker berdasarkan ID
+		protectedMarkerRoutes.DELETE("/:id", adminOnly, markerController.DeleteMarker) // Menghapus marker berdasarkan ID
+		protectedMarkerRoutes.GET("/:id/revisions", markerController.GetMarkerRevisions)
+		protectedMarkerRoutes.POST("/:id/revisions/:rev/revert", adminOnly, markerController.RevertMarkerRevision)
+
+		// Gambar marker (pengirim marker atau moderator)
+		protectedMarkerRoutes.POST("/:id/images", markerImageController.UploadImage)
+		protectedMarkerRoutes.PUT("/:id/images/order", markerImageController.ReorderImages)
+		protectedMarkerRoutes.PUT("/:id/images/:imageID/cover", markerImageController.SetCoverImage)
+		protectedMarkerRoutes.DELETE("/:id/images/:imageID", markerImageController.DeleteImage)
+
+		// Ulasan marker (satu ulasan per pengguna per marker)
+		protectedMarkerRoutes.POST("/:id/reviews", reviewController.CreateReview)
+		protectedMarkerRoutes.PUT("/:id/reviews/:reviewID", reviewController.UpdateReview)
+		protectedMarkerRoutes.DELETE("/:id/reviews/:reviewID", reviewController.DeleteReview)
+		protectedMarkerRoutes.PUT("/:id/reviews/:reviewID/vote", reviewController.VoteReview)
+		protectedMarkerRoutes.DELETE("/:id/reviews/:reviewID/vote", reviewController.RemoveReviewVote)
+		protectedMarkerRoutes.PUT("/:id/reviews/:reviewID/reply", reviewController.ReplyToReview) // Pemilik marker atau moderator
+		protectedMarkerRoutes.DELETE("/:id/reviews/:reviewID/reply", reviewController.DeleteReviewReply)
+		protectedMarkerRoutes.POST("/:id/reviews/:reviewID/report", reviewController.ReportReview)
+
+		// Event: pemilik marker atau admin
+		protectedMarkerRoutes.POST("/:id/events", eventController.CreateEvent)
+	}
+
+	// Rute Moderasi (admin atau moderator)
+	moderationRoutes.Use(AuthMiddleware(), moderatorOnly)
+	{
+		moderationRoutes.GET("/markers", moderationController.GetMarkerQueue)
+		moderationRoutes.POST("/markers/:id/approve", moderationController.ApproveMarker)
+		moderationRoutes.POST("/markers/:id/reject", moderationController.RejectMarker)
+		moderationRoutes.GET("/reviews/reports", moderationController.GetReviewReportQueue)
+		moderationRoutes.POST("/reviews/:reviewID/hide", moderationController.HideReview)
+		moderationRoutes.POST("/reviews/:reviewID/unhide", moderationController.UnhideReview)
+		moderationRoutes.POST("/reviews/reports/:reportID/dismiss", moderationController.DismissReviewReport)
+	}
+
+	// Rute Administrasi
+	adminRoutes.Use(AuthMiddleware(), adminOnly)
+	{
+		adminRoutes.GET("/markers/duplicates", markerController.GetDuplicateReport)
+		adminRoutes.POST("/markers/:id/merge", markerController.MergeMarkers)
+		adminRoutes.POST("/markers/trending/recompute", trendingController.RecomputeTrending)
+
+		// Trash: :type bernilai markers, categories, atau tags
+		adminRoutes.GET("/trash/:type", trashController.ListTrash)
+		adminRoutes.POST("/trash/:type/:id/restore", trashController.RestoreFromTrash)
+		adminRoutes.DELETE("/trash/:type/:id", trashController.PurgeTrashItem)
+		adminRoutes.POST("/trash/purge", trashController.PurgeTrash)
+
+		adminRoutes.POST("/reviews/sentiment/backfill", reviewController.BackfillSentiment)
+
+		// Terjemahan konten: :type bernilai markers, categories, atau tags
+		adminRoutes.GET("/translations/missing", translationController.GetMissingTranslations)
+		adminRoutes.GET("/translations/:type/:id", translationController.GetEntityTranslations)
+		adminRoutes.PUT("/translations/:type/:id/:locale", translationController.UpsertEntityTranslations)
+		adminRoutes.DELETE("/translations/:type/:id/:locale", translationController.DeleteEntityTranslations)
 	}
 
 	// Rute Marker Categories
@@ -243,6 +461,8 @@ func main() {
 		protectedMarkerCategoriesRoutes.POST("", markerCategoryController.CreateCategory)
 		protectedMarkerCategoriesRoutes.PUT("/:id", markerCategoryController.UpdateCategory)
 		protectedMarkerCategoriesRoutes.DELETE("/:id", markerCategoryController.DeleteCategory)
+		protectedMarkerCategoriesRoutes.POST("/:id/move", markerCategoryController.MoveCategory)              // Pindahkan kategori beserta turunannya
+		protectedMarkerCategoriesRoutes.POST("/:id/children/move", markerCategoryController.ReparentChildren) // Pindahkan semua subkategori langsung
 	}
 
 	// Rute Marker Tags
@@ -251,6 +471,9 @@ func main() {
 		protectedMarkerTagsRoutes.POST("", markerTagController.CreateTag)
 		protectedMarkerTagsRoutes.PUT("/:id", markerTagController.UpdateTag)
 		protectedMarkerTagsRoutes.DELETE("/:id", markerTagController.DeleteTag)
+		protectedMarkerTagsRoutes.POST("/:id/merge", markerTagController.MergeTag)                    // Gabungkan tag ke target_id
+		protectedMarkerTagsRoutes.POST("/:id/aliases", markerTagController.AddTagAlias)               // Tambah alias tag
+		protectedMarkerTagsRoutes.DELETE("/:id/aliases/:aliasID", markerTagController.DeleteTagAlias) // Hapus alias tag
 	}
 
 	// Mendapatkan port dari variabel lingkungan, default ke 3000
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Visibilitas koleksi. Koleksi unlisted hanya bisa dibuka dengan share token,
+// sedangkan koleksi public bisa dibuka siapa saja melalui ID-nya.
+const (
+	CollectionVisibilityPrivate  = "private"
+	CollectionVisibilityUnlisted = "unlisted"
+	CollectionVisibilityPublic   = "public"
+)
+
+// Collection adalah kumpulan marker bernama yang dikurasi pengguna, misalnya "kuliner Bandung".
+type Collection struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`            // ID koleksi (UUID)
+	UserID        uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`                             // ID pemilik koleksi
+	Name          string         `gorm:"type:varchar(100);not null" json:"name"`                              // Nama koleksi
+	Description   *string        `gorm:"type:text" json:"description"`                                        // Deskripsi koleksi, bisa null
+	Visibility    string         `gorm:"type:varchar(10);not null;default:'private';index" json:"visibility"` // private, unlisted, atau public
+	ShareToken    *string        `gorm:"type:varchar(64);uniqueIndex" json:"share_token,omitempty"`           // Token tautan berbagi, hanya untuk koleksi unlisted
+	CopiedFromID  *uuid.UUID     `gorm:"type:uuid" json:"copied_from_id,omitempty"`                           // ID koleksi asal jika koleksi ini hasil salinan
+	ItemCount     int            `gorm:"->;-:migration" json:"item_count"`                                    // Jumlah marker, diisi dari query (read-only)
+	OwnerUsername string         `gorm:"->;-:migration" json:"owner_username,omitempty"`                      // Username pemilik, diisi dari query (read-only)
+	CreatedAt     time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`                // Waktu pembuatan record
+	UpdatedAt     time.Time      `json:"updated_at"`                                                          // Waktu pembaruan record
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`                                   // Untuk soft delete
+
+	// Relasi
+	Items []CollectionItem `gorm:"foreignKey:CollectionID" json:"items,omitempty"`
+}
+
+// BeforeCreate hook untuk Collection: Otomatis menghasilkan UUID untuk Collection.ID jika belum ada.
+func (col *Collection) BeforeCreate(tx *gorm.DB) (err error) {
+	if col.ID == uuid.Nil {
+		col.ID = uuid.New()
+	}
+	return
+}
+
+// CollectionItem adalah satu marker di dalam koleksi beserta urutan dan catatan pemilik koleksi.
+type CollectionItem struct {
+	CollectionID uuid.UUID `gorm:"type:uuid;primaryKey" json:"collection_id"`          // ID koleksi, bagian dari PK komposit
+	MarkerID     uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"marker_id"`        // ID marker, bagian dari PK komposit
+	SortOrder    int       `gorm:"not null;default:0" json:"sort_order"`               // Urutan marker di dalam koleksi
+	Note         *string   `gorm:"type:text" json:"note"`                              // Catatan pribadi untuk marker ini, bisa null
+	AddedAt      time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"added_at"` // Waktu marker ditambahkan ke koleksi
+
+	// Relasi
+	Marker *Marker `gorm:"foreignKey:MarkerID" json:"marker,omitempty"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultEventTimezone adalah zona waktu event jika tidak ditentukan.
+const DefaultEventTimezone = "Asia/Jakarta"
+
+// Event adalah kegiatan berjangka waktu di sebuah marker, misalnya festival, pasar malam, atau konser.
+// Event berulang menyimpan aturan RRULE; StartsAt/EndsAt adalah kejadian pertama.
+type Event struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`         // ID event (UUID)
+	MarkerID        uuid.UUID      `gorm:"type:uuid;not null;index" json:"marker_id"`                        // ID marker tempat event berlangsung
+	Title           string         `gorm:"type:varchar(200);not null" json:"title"`                          // Judul event
+	Description     *string        `gorm:"type:text" json:"description"`                                     // Deskripsi event, bisa null
+	StartsAt        time.Time      `gorm:"not null;index" json:"starts_at"`                                  // Waktu mulai kejadian pertama
+	EndsAt          time.Time      `gorm:"not null" json:"ends_at"`                                          // Waktu selesai kejadian pertama
+	Timezone        string         `gorm:"type:varchar(64);not null;default:'Asia/Jakarta'" json:"timezone"` // Zona waktu IANA untuk menghitung pengulangan
+	RRule           *string        `gorm:"column:rrule;type:varchar(255)" json:"rrule"`                      // Aturan pengulangan RFC 5545, null untuk event tunggal
+	SeriesEndsAt    *time.Time     `gorm:"index" json:"series_ends_at"`                                      // Waktu selesai kejadian terakhir, null jika berulang tanpa batas
+	Price           *float64       `gorm:"type:numeric(12,2)" json:"price"`                                  // Harga tiket, 0 untuk gratis, null jika tidak diketahui
+	Currency        string         `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"`           // Kode mata uang ISO 4217
+	URL             *string        `gorm:"type:varchar(500)" json:"url"`                                     // Tautan informasi atau tiket, bisa null
+	CreatedByUserID uuid.UUID      `gorm:"type:uuid;not null" json:"created_by_user_id"`                     // ID pengguna yang membuat event
+	CreatedAt       time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`             // Waktu pembuatan record
+	UpdatedAt       time.Time      `json:"updated_at"`                                                       // Waktu pembaruan record
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`                                // Untuk soft delete
+
+	// Relasi
+	Marker *Marker `gorm:"foreignKey:MarkerID" json:"marker,omitempty"`
+}
+
+// BeforeCreate hook untuk Event: Otomatis menghasilkan UUID untuk Event.ID jika belum ada.
+func (e *Event) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return
+}
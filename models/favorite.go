@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Favorite menandai marker yang disimpan pengguna sebagai favorit.
+// Setiap pengguna hanya bisa memfavoritkan satu marker sekali.
+type Favorite struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`                  // ID pengguna, bagian dari PK komposit
+	MarkerID  uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"marker_id"`          // ID marker favorit, bagian dari PK komposit
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"` // Waktu marker difavoritkan
+
+	// Relasi
+	Marker *Marker `gorm:"foreignKey:MarkerID" json:"marker,omitempty"`
+}
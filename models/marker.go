@@ -7,6 +7,14 @@ import (
 	"gorm.io/gorm"
 )
 
+// Status moderasi marker. Marker kiriman pengguna biasa dimulai sebagai pending
+// dan tidak tampil di daftar publik sampai disetujui moderator.
+const (
+	MarkerStatusPending  = "pending"
+	MarkerStatusApproved = "approved"
+	MarkerStatusRejected = "rejected"
+)
+
 // Marker merepresentasikan entitas lokasi atau tempat menarik di peta.
 type Marker struct {
 	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"` // ID marker (UUID)
@@ -14,16 +22,30 @@ type Marker struct {
 	Description *string   `json:"description"`                                              // Deskripsi marker, bisa null
 	Latitude    float64   `gorm:"not null" json:"latitude"`                                 // Koordinat lintang, tidak null
 	Longitude   float64   `gorm:"not null" json:"longitude"`                                // Koordinat bujur, tidak null
+	City        *string   `gorm:"type:varchar(100);index" json:"city"`                      // Nama kota/kabupaten, bisa null
 	// Geometry (GEOGRAPHY): GORM tidak memiliki tipe Go langsung. Penanganan untuk PostGIS
 	// biasanya dilakukan dengan plugin GORM atau menggunakan raw SQL untuk kolom geometry.
-	CategoryID    uuid.UUID      `gorm:"type:uuid;not null" json:"category_id"`                // ID kategori marker, tidak null
-	AvgRating     float64        `gorm:"type:numeric(2,1);default:0.0" json:"avg_rating"`      // Rata-rata rating, default 0.0
-	TotalReviews  int            `gorm:"type:integer;default:0" json:"total_reviews"`          // Total ulasan, default 0
-	ViewCount     int64          `gorm:"type:bigint;default:0" json:"view_count"`              // Jumlah tampilan, default 0
-	AddedByUserID uuid.UUID      `gorm:"type:uuid;not null" json:"added_by_user_id"`           // ID pengguna yang menambahkan, tidak null
-	CreatedAt     time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"` // Waktu pembuatan record
-	UpdatedAt     time.Time      `json:"updated_at"`                                           // Waktu pembaruan record
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`                    // Untuk soft delete
+	CategoryID    uuid.UUID `gorm:"type:uuid;not null" json:"category_id"`                            // ID kategori marker, tidak null
+	AvgRating     float64   `gorm:"type:numeric(2,1);default:0.0" json:"avg_rating"`                  // Rata-rata rating, default 0.0
+	TotalReviews  int       `gorm:"type:integer;default:0" json:"total_reviews"`                      // Total ulasan, default 0
+	AvgSentiment  *float64  `gorm:"type:numeric(3,2)" json:"avg_sentiment"`                           // Rata-rata skor sentimen ulasan (-1..1), null jika belum ada yang dianalisis
+	ViewCount     int64     `gorm:"type:bigint;default:0" json:"view_count"`                          // Jumlah tampilan, default 0
+	AddedByUserID uuid.UUID `gorm:"type:uuid;not null" json:"added_by_user_id"`                       // ID pengguna yang menambahkan, tidak null
+	Status        string    `gorm:"type:varchar(20);not null;default:'approved';index" json:"status"` // Status moderasi: pending, approved, rejected
+	// Informasi moderasi untuk marker yang dikirim oleh komunitas
+	ModerationNote    *string        `json:"moderation_note,omitempty"`                            // Alasan penolakan dari moderator, bisa null
+	ModeratedByUserID *uuid.UUID     `gorm:"type:uuid" json:"moderated_by_user_id,omitempty"`      // ID moderator yang memproses, bisa null
+	ModeratedAt       *time.Time     `json:"moderated_at,omitempty"`                               // Waktu marker dimoderasi, bisa null
+	CreatedAt         time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"` // Waktu pembuatan record
+	UpdatedAt         time.Time      `json:"updated_at"`                                           // Waktu pembaruan record
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`                    // Untuk soft delete
+
+	// Informasi terjemahan, diisi saat respons dilokalkan (tidak disimpan di database)
+	Locale              string   `gorm:"-" json:"locale,omitempty"`               // Locale konten yang disajikan
+	MissingTranslations []string `gorm:"-" json:"missing_translations,omitempty"` // Field yang belum diterjemahkan ke locale tersebut
+
+	// Jalur kategori dari akar, misalnya Kuliner > Street Food (tidak disimpan di database)
+	CategoryBreadcrumbs []CategoryBreadcrumb `gorm:"-" json:"category_breadcrumbs,omitempty"`
 
 	// Relasi
 	Category MarkerCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
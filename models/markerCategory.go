@@ -7,23 +7,83 @@ import (
 	"gorm.io/gorm"
 )
 
-// MarkerCategory mendefinisikan kategori untuk pengelompokan marker.
+// MaxCategoryDepth adalah kedalaman maksimum pohon kategori (kategori akar berkedalaman 0).
+const MaxCategoryDepth = 4
+
+// CategoryIcons adalah daftar kunci ikon kategori yang dikenali klien peta.
+var CategoryIcons = []string{
+	"restaurant", "street-food", "cafe", "bakery", "bar",
+	"hotel", "villa", "camping",
+	"beach", "mountain", "waterfall", "park", "garden",
+	"museum", "monument", "temple", "mosque", "church", "landmark",
+	"shopping", "market", "cinema", "theme-park",
+	"train", "airport", "bus", "harbor", "parking", "gas-station",
+	"hospital", "atm", "other",
+}
+
+// IsCategoryIcon bernilai true jika key terdaftar di CategoryIcons.
+func IsCategoryIcon(key string) bool {
+	for _, icon := range CategoryIcons {
+		if icon == key {
+			return true
+		}
+	}
+	return false
+}
+
+// CategoryBreadcrumb adalah satu langkah pada jalur kategori dari akar, misalnya Kuliner > Street Food.
+type CategoryBreadcrumb struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// MarkerCategory mendefinisikan kategori untuk pengelompokan marker. Kategori membentuk pohon melalui
+// ParentID; Path menyimpan ID leluhur hingga kategori itu sendiri ("/<akar>/.../<id>/") sehingga
+// seluruh turunan sebuah kategori dapat dipilih dengan satu kondisi LIKE prefiks.
 type MarkerCategory struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"` // ID kategori (UUID)
-	Name        string         `gorm:"type:varchar(100);not null;unique" json:"name"`            // Nama kategori, unik dan tidak null
-	Description *string        `json:"description"`                                              // Deskripsi kategori, bisa null
-	CreatedAt   time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`     // Waktu pembuatan record
-	UpdatedAt   time.Time      `json:"updated_at"`                                               // Waktu pembaruan record
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`                        // Untuk soft delete
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`                                                    // ID kategori (UUID)
+	Name        string         `gorm:"type:varchar(100);not null;unique" json:"name"`                                                               // Nama kategori, unik dan tidak null
+	Description *string        `json:"description"`                                                                                                 // Deskripsi kategori, bisa null
+	ParentID    *uuid.UUID     `gorm:"type:uuid;index" json:"parent_id"`                                                                            // ID kategori induk, null untuk kategori akar
+	Path        string         `gorm:"type:varchar(1000);not null;default:'';index:idx_marker_category_path,class:varchar_pattern_ops" json:"path"` // Jalur materialized dari akar, diisi otomatis
+	Depth       int            `gorm:"not null;default:0" json:"depth"`                                                                             // Kedalaman di pohon, 0 untuk kategori akar
+	Icon        *string        `gorm:"type:varchar(50)" json:"icon"`                                                                                // Kunci ikon dari CategoryIcons, bisa null
+	Color       *string        `gorm:"type:varchar(7)" json:"color"`                                                                                // Warna penanda di peta dalam format hex #rrggbb, bisa null
+	SortOrder   int            `gorm:"not null;default:0" json:"sort_order"`                                                                        // Urutan tampilan di antara kategori yang satu induk
+	CreatedAt   time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`                                                        // Waktu pembuatan record
+	UpdatedAt   time.Time      `json:"updated_at"`                                                                                                  // Waktu pembaruan record
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`                                                                           // Untuk soft delete
+
+	// Informasi terjemahan, diisi saat respons dilokalkan (tidak disimpan di database)
+	Locale              string   `gorm:"-" json:"locale,omitempty"`               // Locale konten yang disajikan
+	MissingTranslations []string `gorm:"-" json:"missing_translations,omitempty"` // Field yang belum diterjemahkan ke locale tersebut
+
+	// Informasi pohon dan jumlah marker, diisi oleh endpoint tertentu (tidak disimpan di database)
+	MarkerCount      *int64               `gorm:"-" json:"marker_count,omitempty"`       // Jumlah marker yang disetujui langsung di kategori ini
+	TotalMarkerCount *int64               `gorm:"-" json:"total_marker_count,omitempty"` // Jumlah marker termasuk seluruh subkategori
+	Breadcrumbs      []CategoryBreadcrumb `gorm:"-" json:"breadcrumbs,omitempty"`        // Jalur dari kategori akar sampai kategori ini
+	Children         []MarkerCategory     `gorm:"-" json:"children,omitempty"`           // Subkategori langsung
 
 	// Relasi (opsional untuk GORM, digunakan untuk memuat marker dalam kategori ini)
 	Markers []Marker `gorm:"foreignKey:CategoryID" json:"markers,omitempty"`
 }
 
-// BeforeCreate hook untuk MarkerCategory: Otomatis menghasilkan UUID untuk MarkerCategory.ID jika belum ada.
+// BeforeCreate hook untuk MarkerCategory: Otomatis menghasilkan UUID untuk MarkerCategory.ID jika belum ada,
+// lalu mengisi Path dan Depth dari kategori induk.
 func (mc *MarkerCategory) BeforeCreate(tx *gorm.DB) (err error) {
 	if mc.ID == uuid.Nil {
 		mc.ID = uuid.New()
 	}
+	if mc.Path != "" {
+		return
+	}
+	mc.Path, mc.Depth = "/"+mc.ID.String()+"/", 0
+	if mc.ParentID != nil {
+		var parent MarkerCategory
+		if err = tx.Session(&gorm.Session{NewDB: true}).Select("path", "depth").First(&parent, "id = ?", *mc.ParentID).Error; err != nil {
+			return
+		}
+		mc.Path, mc.Depth = parent.Path+mc.ID.String()+"/", parent.Depth+1
+	}
 	return
 }
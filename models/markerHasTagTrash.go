@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MarkerHasTagTrash mengarsipkan tautan marker-tag ketika marker atau tag-nya dipindahkan ke trash.
+// Tautan dikembalikan ke marker_has_tags saat kedua sisinya kembali aktif, dan dihapus permanen saat purge.
+type MarkerHasTagTrash struct {
+	MarkerID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"marker_id"`                // ID marker, bagian dari PK komposit
+	TagID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"tag_id"`                   // ID tag, bagian dari PK komposit
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`                           // Waktu tautan asli dibuat
+	TrashedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"trashed_at"` // Waktu tautan diarsipkan
+}
@@ -9,12 +9,23 @@ import (
 
 // MarkerImage menyimpan URL dan deskripsi gambar yang terkait dengan sebuah marker.
 type MarkerImage struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"` // ID gambar marker (UUID)
-	MarkerID    uuid.UUID      `gorm:"type:uuid;not null" json:"marker_id"`                      // ID marker terkait, tidak null
-	ImageURL    string         `gorm:"type:varchar(255);not null" json:"image_url"`              // URL gambar, tidak null
-	Description *string        `json:"description"`                                              // Deskripsi gambar, bisa null
-	UploadedAt  time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"uploaded_at"`    // Waktu upload gambar
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`                        // Untuk soft delete
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"` // ID gambar marker (UUID)
+	MarkerID    uuid.UUID `gorm:"type:uuid;not null" json:"marker_id"`                      // ID marker terkait, tidak null
+	ImageURL    string    `gorm:"type:varchar(255);not null" json:"image_url"`              // URL gambar, tidak null
+	Description *string   `json:"description"`                                              // Deskripsi gambar, bisa null
+	// Informasi file yang diunggah melalui pipeline upload (kosong untuk gambar lama yang hanya berupa URL)
+	StorageKey       string         `gorm:"type:varchar(255)" json:"-"`                            // Key blob gambar asli di BlobStore
+	ThumbnailKey     string         `gorm:"type:varchar(255)" json:"-"`                            // Key blob thumbnail di BlobStore
+	ThumbnailURL     *string        `gorm:"type:varchar(255)" json:"thumbnail_url"`                // URL thumbnail, bisa null
+	ContentType      string         `gorm:"type:varchar(50)" json:"content_type,omitempty"`        // MIME type gambar asli
+	SizeBytes        int64          `gorm:"type:bigint;default:0" json:"size_bytes"`               // Ukuran file asli dalam byte
+	Width            int            `gorm:"default:0" json:"width"`                                // Lebar gambar dalam piksel
+	Height           int            `gorm:"default:0" json:"height"`                               // Tinggi gambar dalam piksel
+	SortOrder        int            `gorm:"not null;default:0" json:"sort_order"`                  // Urutan tampil, kecil lebih dulu
+	IsCover          bool           `gorm:"not null;default:false" json:"is_cover"`                // Gambar sampul marker (maksimal satu per marker)
+	UploadedByUserID *uuid.UUID     `gorm:"type:uuid" json:"uploaded_by_user_id,omitempty"`        // ID pengunggah, bisa null
+	UploadedAt       time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"uploaded_at"` // Waktu upload gambar
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`                     // Untuk soft delete
 }
 
 // BeforeCreate hook untuk MarkerImage: Otomatis menghasilkan UUID untuk MarkerImage.ID jika belum ada.
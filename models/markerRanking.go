@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Jendela waktu peringkat marker trending.
+const (
+	RankingWindow24h = "24h"
+	RankingWindow7d  = "7d"
+	RankingWindow30d = "30d"
+)
+
+// MarkerRanking menyimpan skor trending marker per jendela waktu. Tabel ini diisi ulang secara berkala
+// oleh job latar belakang sehingga endpoint trending cukup membaca hasil yang sudah dihitung.
+type MarkerRanking struct {
+	MarkerID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"marker_id"`                                                                    // ID marker, bagian dari PK komposit
+	Window         string    `gorm:"column:ranking_window;type:varchar(5);primaryKey;index:idx_marker_ranking_score,priority:1" json:"window"` // Jendela waktu: 24h, 7d, atau 30d
+	Score          float64   `gorm:"not null;index:idx_marker_ranking_score,priority:2,sort:desc" json:"score"`                                // Skor akhir untuk pengurutan
+	ActivityScore  float64   `gorm:"not null" json:"activity_score"`                                                                           // Jumlah aktivitas (view, favorit) dengan peluruhan waktu
+	ReviewScore    float64   `gorm:"not null" json:"review_score"`                                                                             // Kecepatan ulasan baru dengan peluruhan waktu
+	BayesianRating float64   `gorm:"type:numeric(3,2);not null" json:"bayesian_rating"`                                                        // Rating yang disesuaikan dengan rata-rata global (Bayesian average)
+	Views          int64     `gorm:"not null" json:"views"`                                                                                    // Jumlah view unik per jam dalam jendela
+	Reviews        int       `gorm:"not null" json:"reviews"`                                                                                  // Jumlah ulasan baru dalam jendela
+	ComputedAt     time.Time `gorm:"not null" json:"computed_at"`                                                                              // Waktu skor dihitung
+
+	// Relasi
+	Marker *Marker `gorm:"foreignKey:MarkerID" json:"marker,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MarkerRedirect mencatat marker yang telah digabungkan (merge) ke marker lain,
+// sehingga ID lama tetap bisa diakses dan diarahkan ke marker yang bertahan.
+type MarkerRedirect struct {
+	FromMarkerID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"from_marker_id"`           // ID marker yang digabungkan (sudah dihapus)
+	ToMarkerID     uuid.UUID `gorm:"type:uuid;not null;index" json:"to_marker_id"`         // ID marker tujuan yang bertahan
+	MergedByUserID uuid.UUID `gorm:"type:uuid;not null" json:"merged_by_user_id"`          // ID admin yang melakukan penggabungan
+	CreatedAt      time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"` // Waktu penggabungan
+}
@@ -9,18 +9,36 @@ import (
 
 // MarkerReview menyimpan ulasan dan rating yang diberikan pengguna untuk sebuah marker.
 type MarkerReview struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`               // ID ulasan (UUID)
-	MarkerID  uuid.UUID      `gorm:"type:uuid;not null" json:"marker_id"`                                    // ID marker terkait, tidak null
-	UserID    uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`                                      // ID pengguna yang memberikan ulasan, tidak null
-	Rating    int            `gorm:"type:smallint;not null;check:rating >= 1 AND rating <= 5" json:"rating"` // Rating 1-5 bintang
-	Comment   *string        `json:"comment"`                                                                // Komentar ulasan, bisa null
-	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`                   // Waktu pembuatan record
-	UpdatedAt time.Time      `json:"updated_at"`                                                             // Waktu pembaruan record
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`                                      // Untuk soft delete
-
-	// Relasi
-	Marker Marker `gorm:"foreignKey:MarkerID" json:"marker,omitempty"`
-	User   User   `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`                                        // ID ulasan (UUID)
+	MarkerID  uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_marker_review_user,where:deleted_at IS NULL" json:"marker_id"` // ID marker terkait, tidak null
+	UserID    uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_marker_review_user,where:deleted_at IS NULL" json:"user_id"`   // ID pengguna yang memberikan ulasan; satu ulasan aktif per pengguna per marker
+	Rating    int            `gorm:"type:smallint;not null;check:rating >= 1 AND rating <= 5" json:"rating"`                          // Rating 1-5 bintang
+	Comment   *string        `json:"comment"`                                                                                         // Komentar ulasan, bisa null
+	CreatedAt time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`                                            // Waktu pembuatan record
+	UpdatedAt time.Time      `json:"updated_at"`                                                                                      // Waktu pembaruan record
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`                                                               // Untuk soft delete
+
+	// Penilaian kegunaan dan moderasi
+	HelpfulCount   int        `gorm:"not null;default:0" json:"helpful_count"`       // Jumlah suara "membantu"
+	UnhelpfulCount int        `gorm:"not null;default:0" json:"unhelpful_count"`     // Jumlah suara "tidak membantu"
+	IsHidden       bool       `gorm:"not null;default:false;index" json:"is_hidden"` // Disembunyikan moderator; tidak tampil dan tidak dihitung di AvgRating
+	HiddenReason   *string    `json:"hidden_reason,omitempty"`                       // Alasan penyembunyian
+	HiddenByUserID *uuid.UUID `gorm:"type:uuid" json:"hidden_by_user_id,omitempty"`  // ID moderator yang menyembunyikan
+	HiddenAt       *time.Time `json:"hidden_at,omitempty"`                           // Waktu ulasan disembunyikan
+
+	// Hasil analisis sentimen komentar, diisi secara asinkron oleh worker sentimen
+	SentimentScore      *float64   `gorm:"type:numeric(4,3)" json:"sentiment_score"`             // Skor -1 (negatif) sampai 1 (positif), null jika belum dianalisis
+	SentimentLabel      *string    `gorm:"type:varchar(10);index" json:"sentiment_label"`        // positive, neutral, atau negative
+	SentimentAnalyzer   *string    `gorm:"type:varchar(50)" json:"sentiment_analyzer,omitempty"` // Nama analyzer yang menghasilkan skor
+	SentimentAnalyzedAt *time.Time `gorm:"index" json:"sentiment_analyzed_at,omitempty"`         // Waktu analisis terakhir; null berarti menunggu analisis
+
+	// Username pengulas, hanya dibaca melalui JOIN ke tabel users (bukan kolom tabel)
+	Username string `gorm:"->;-:migration" json:"username,omitempty"`
+
+	// Relasi (pointer agar tidak ikut diserialisasi saat tidak di-preload)
+	Marker *Marker      `gorm:"foreignKey:MarkerID" json:"marker,omitempty"`
+	User   *User        `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Reply  *ReviewReply `gorm:"foreignKey:ReviewID" json:"reply,omitempty"`
 }
 
 // BeforeCreate hook untuk MarkerReview: Otomatis menghasilkan UUID untuk MarkerReview.ID jika belum ada.
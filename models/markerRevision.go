@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Jenis aksi yang dicatat pada riwayat revisi marker.
+const (
+	RevisionActionCreate  = "create"
+	RevisionActionUpdate  = "update"
+	RevisionActionDelete  = "delete"
+	RevisionActionRevert  = "revert"
+	RevisionActionMerge   = "merge"
+	RevisionActionRestore = "restore"
+)
+
+// MarkerRevision menyimpan satu revisi marker: snapshot lengkap setelah perubahan beserta diff per field.
+// Tabel ini bersifat append-only sehingga tidak memiliki kolom soft delete.
+type MarkerRevision struct {
+	ID                 uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`            // ID revisi (UUID)
+	MarkerID           uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_marker_revision" json:"marker_id"` // ID marker terkait
+	Revision           int             `gorm:"not null;uniqueIndex:idx_marker_revision" json:"revision"`            // Nomor revisi, berurutan per marker
+	Action             string          `gorm:"type:varchar(20);not null" json:"action"`                             // Aksi: create, update, delete, revert, merge, restore
+	AuthorUserID       uuid.UUID       `gorm:"type:uuid;not null" json:"author_user_id"`                            // ID pengguna yang melakukan perubahan
+	Snapshot           json.RawMessage `gorm:"type:jsonb;not null" json:"snapshot"`                                 // Kondisi marker (termasuk tag) pada revisi ini
+	Changes            json.RawMessage `gorm:"type:jsonb" json:"changes"`                                           // Diff per field: {"field": {"old": ..., "new": ...}}
+	RevertedToRevision *int            `json:"reverted_to_revision,omitempty"`                                      // Nomor revisi tujuan jika aksi adalah revert
+	CreatedAt          time.Time       `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`                // Waktu revisi dibuat
+}
+
+// BeforeCreate hook untuk MarkerRevision: Otomatis menghasilkan UUID untuk MarkerRevision.ID jika belum ada.
+func (mr *MarkerRevision) BeforeCreate(tx *gorm.DB) (err error) {
+	if mr.ID == uuid.Nil {
+		mr.ID = uuid.New()
+	}
+	return
+}
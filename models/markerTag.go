@@ -15,6 +15,14 @@ type MarkerTag struct {
 	UpdatedAt time.Time      `json:"updated_at"`                                               // Waktu pembaruan record
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`                        // Untuk soft delete
 
+	// Informasi terjemahan, diisi saat respons dilokalkan (tidak disimpan di database)
+	Locale              string   `gorm:"-" json:"locale,omitempty"`               // Locale konten yang disajikan
+	MissingTranslations []string `gorm:"-" json:"missing_translations,omitempty"` // Field yang belum diterjemahkan ke locale tersebut
+
+	// Statistik dan sinonim, diisi oleh endpoint tertentu (tidak disimpan di database)
+	UsageCount *int64   `gorm:"-" json:"usage_count,omitempty"` // Jumlah marker yang disetujui dengan tag ini
+	Aliases    []string `gorm:"-" json:"aliases,omitempty"`     // Alias yang mengarah ke tag ini
+
 	// Relasi Many-to-Many
 	Markers []Marker `gorm:"many2many:marker_has_tags;" json:"markers,omitempty"`
 }
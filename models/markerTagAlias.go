@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MarkerTagAlias adalah sinonim yang mengarah ke tag kanonik, misalnya "culinary" dan "makanan" untuk tag "kuliner".
+// Alias juga dibuat otomatis saat tag digabungkan sehingga nama dan ID tag lama tetap dapat dipakai.
+type MarkerTagAlias struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"` // ID alias (UUID)
+	Alias           string     `gorm:"type:varchar(100);not null;uniqueIndex" json:"alias"`      // Nama alias dalam huruf kecil, unik
+	TagID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"tag_id"`                   // ID tag kanonik yang dituju
+	MergedFromTagID *uuid.UUID `gorm:"type:uuid;index" json:"merged_from_tag_id,omitempty"`      // ID tag lama jika alias berasal dari penggabungan tag
+	CreatedByUserID *uuid.UUID `gorm:"type:uuid" json:"created_by_user_id,omitempty"`            // ID admin yang membuat alias
+	CreatedAt       time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`     // Waktu pembuatan record
+}
+
+// BeforeCreate hook untuk MarkerTagAlias: Otomatis menghasilkan UUID untuk MarkerTagAlias.ID jika belum ada.
+func (a *MarkerTagAlias) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReviewReply adalah balasan resmi atas sebuah ulasan, ditulis oleh pemilik marker atau moderator.
+// Setiap ulasan hanya memiliki satu balasan resmi.
+type ReviewReply struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"` // ID balasan (UUID)
+	ReviewID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"review_id"`          // ID ulasan yang dibalas, satu balasan per ulasan
+	AuthorUserID uuid.UUID `gorm:"type:uuid;not null" json:"author_user_id"`                 // ID pemilik marker atau moderator yang membalas
+	Body         string    `gorm:"type:text;not null" json:"body"`                           // Isi balasan
+	CreatedAt    time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`     // Waktu balasan dibuat
+	UpdatedAt    time.Time `json:"updated_at"`                                               // Waktu balasan terakhir diubah
+}
+
+// BeforeCreate hook untuk ReviewReply: Otomatis menghasilkan UUID untuk ReviewReply.ID jika belum ada.
+func (rr *ReviewReply) BeforeCreate(tx *gorm.DB) (err error) {
+	if rr.ID == uuid.Nil {
+		rr.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status laporan ulasan.
+const (
+	ReviewReportStatusPending   = "pending"   // Menunggu tindakan moderator
+	ReviewReportStatusResolved  = "resolved"  // Ulasan disembunyikan oleh moderator
+	ReviewReportStatusDismissed = "dismissed" // Laporan dinilai tidak valid
+)
+
+// Alasan yang dapat dipilih saat melaporkan ulasan.
+var ReviewReportReasons = []string{"spam", "offensive", "off_topic", "fake", "personal_info", "other"}
+
+// ReviewReport menyimpan laporan penyalahgunaan atas sebuah ulasan dan menjadi sumber antrean moderasi ulasan.
+type ReviewReport struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`                          // ID laporan (UUID)
+	ReviewID         uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_review_report_reporter" json:"review_id"`  // ID ulasan yang dilaporkan
+	ReporterUserID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_review_report_reporter" json:"reporter_user_id"` // ID pelapor; satu laporan per pelapor per ulasan
+	Reason           string     `gorm:"type:varchar(30);not null" json:"reason"`                                           // Alasan laporan (lihat ReviewReportReasons)
+	Details          *string    `gorm:"type:text" json:"details"`                                                          // Keterangan tambahan dari pelapor, bisa null
+	Status           string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`                   // Status: pending, resolved, dismissed
+	ResolvedByUserID *uuid.UUID `gorm:"type:uuid" json:"resolved_by_user_id,omitempty"`                                    // ID moderator yang menindaklanjuti
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty"`                                                             // Waktu laporan ditindaklanjuti
+	CreatedAt        time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`                              // Waktu laporan dibuat
+
+	// Relasi
+	Review *MarkerReview `gorm:"foreignKey:ReviewID" json:"review,omitempty"`
+}
+
+// BeforeCreate hook untuk ReviewReport: Otomatis menghasilkan UUID untuk ReviewReport.ID jika belum ada.
+func (rr *ReviewReport) BeforeCreate(tx *gorm.DB) (err error) {
+	if rr.ID == uuid.Nil {
+		rr.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewVote menyimpan penilaian pengguna terhadap kegunaan sebuah ulasan (membantu / tidak membantu).
+// Setiap pengguna hanya memiliki satu suara per ulasan.
+type ReviewVote struct {
+	ReviewID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"review_id"`                // ID ulasan, bagian dari PK komposit
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`                  // ID pemberi suara, bagian dari PK komposit
+	Helpful   bool      `gorm:"not null" json:"helpful"`                              // true = membantu, false = tidak membantu
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"` // Waktu suara pertama kali diberikan
+	UpdatedAt time.Time `json:"updated_at"`                                           // Waktu suara terakhir diubah
+}
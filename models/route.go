@@ -22,14 +22,15 @@ type Route struct {
 	RouteDataJSON   json.RawMessage `gorm:"type:jsonb" json:"route_data_json"`                    // Data rute lengkap (JSONB)
 	DistanceMeters  int64           `gorm:"type:bigint" json:"distance_meters"`                   // Jarak total dalam meter
 	DurationSeconds int64           `gorm:"type:bigint" json:"duration_seconds"`                  // Durasi total dalam detik
-	UserID          uuid.UUID       `gorm:"type:uuid;not null" json:"user_id"`                    // ID pengguna yang menyimpan rute, tidak null
-	IsPublic        bool            `gorm:"not null;default:false" json:"is_public"`              // Apakah rute publik, default false
+	UserID          uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`              // ID pengguna yang menyimpan rute, tidak null
+	IsPublic        bool            `gorm:"not null;default:false;index" json:"is_public"`        // Apakah rute publik, default false
+	OwnerUsername   string          `gorm:"->;-:migration" json:"owner_username,omitempty"`       // Username pemilik, diisi dari query (read-only)
 	CreatedAt       time.Time       `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"` // Waktu pembuatan record
 	UpdatedAt       time.Time       `json:"updated_at"`                                           // Waktu pembaruan record
 	DeletedAt       gorm.DeletedAt  `gorm:"index" json:"deleted_at,omitempty"`                    // Untuk soft delete
 
 	// Relasi
-	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
 // BeforeCreate hook untuk Route: Otomatis menghasilkan UUID untuk Route.ID jika belum ada.
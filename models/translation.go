@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Jenis entitas yang kontennya bisa diterjemahkan.
+const (
+	TranslationEntityMarker   = "marker"
+	TranslationEntityCategory = "category"
+	TranslationEntityTag      = "tag"
+)
+
+// TranslatableFields adalah field yang bisa diterjemahkan untuk setiap jenis entitas.
+var TranslatableFields = map[string][]string{
+	TranslationEntityMarker:   {"name", "description"},
+	TranslationEntityCategory: {"name", "description"},
+	TranslationEntityTag:      {"name"},
+}
+
+// Translation menyimpan terjemahan satu field konten (misal nama marker) ke satu locale.
+// Konten asli tetap tersimpan di kolom model dalam bahasa default.
+type Translation struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`                                  // ID terjemahan (UUID)
+	EntityType      string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_translation_entry,priority:1" json:"entity_type"` // marker, category, atau tag
+	EntityID        uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_translation_entry,priority:2" json:"entity_id"`          // ID entitas yang diterjemahkan
+	Field           string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_translation_entry,priority:3" json:"field"`       // Nama field, misal name atau description
+	Locale          string     `gorm:"type:varchar(10);not null;uniqueIndex:idx_translation_entry,priority:4" json:"locale"`      // Kode locale, misal en atau en-us
+	Value           string     `gorm:"type:text;not null" json:"value"`                                                           // Isi terjemahan
+	UpdatedByUserID *uuid.UUID `gorm:"type:uuid" json:"updated_by_user_id,omitempty"`                                             // ID admin yang terakhir mengubah
+	CreatedAt       time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`                                      // Waktu pembuatan record
+	UpdatedAt       time.Time  `json:"updated_at"`                                                                                // Waktu pembaruan record
+}
+
+// BeforeCreate hook untuk Translation: Otomatis menghasilkan UUID untuk Translation.ID jika belum ada.
+func (t *Translation) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,85 @@
+package places
+
+import (
+	"context"
+	"log"
+
+	"ulyngo/utils"
+)
+
+// Batas deduplikasi hasil cadangan terhadap marker lokal.
+const (
+	duplicateNameRadiusMeters = 75.0 // Dalam radius ini, nama yang mirip dianggap tempat yang sama
+	duplicateRadiusMeters     = 25.0 // Dalam radius ini, tempat dianggap sama tanpa melihat nama
+	duplicateNameSimilarity   = 0.5
+)
+
+// ChainProvider mencari di Primary (marker ulyngo) lebih dulu dan hanya memanggil Fallback jika hasil lokal
+// kurang dari MinResults dengan skor minimal MinScore. Hasil Fallback yang merupakan tempat yang sama dengan
+// marker lokal dibuang, dan hasil lokal selalu diletakkan di depan.
+type ChainProvider struct {
+	Primary    PlacesProvider
+	Fallback   PlacesProvider // Opsional, nil berarti hanya hasil lokal
+	MinResults int
+	MinScore   float64
+}
+
+// Name mengembalikan nama penyedia.
+func (p *ChainProvider) Name() string {
+	if p.Fallback == nil {
+		return p.Primary.Name()
+	}
+	return p.Primary.Name() + "+" + p.Fallback.Name()
+}
+
+// Search menjalankan pencarian berantai. Kegagalan Fallback hanya dicatat di log agar hasil lokal tetap dikembalikan.
+func (p *ChainProvider) Search(ctx context.Context, req Request) ([]Place, error) {
+	local, err := p.Primary.Search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if p.Fallback == nil || p.strongEnough(local) {
+		return local, nil
+	}
+
+	external, err := p.Fallback.Search(ctx, req)
+	if err != nil {
+		log.Printf("Warning: %s places search failed for %q, using %s results only: %v", p.Fallback.Name(), req.Query, p.Primary.Name(), err)
+		return local, nil
+	}
+	results := append([]Place{}, local...)
+	for _, place := range external {
+		if !duplicatesAny(place, local) {
+			results = append(results, place)
+		}
+	}
+	if len(results) > req.limitOf() {
+		results = results[:req.limitOf()]
+	}
+	return results, nil
+}
+
+// strongEnough melaporkan apakah hasil lokal sudah cukup sehingga Fallback tidak perlu dipanggil.
+func (p *ChainProvider) strongEnough(local []Place) bool {
+	strong := 0
+	for _, place := range local {
+		if place.Score >= p.MinScore {
+			strong++
+		}
+	}
+	return strong >= p.MinResults
+}
+
+// duplicatesAny melaporkan apakah tempat sama dengan salah satu tempat lain berdasarkan jarak dan kemiripan nama.
+func duplicatesAny(place Place, others []Place) bool {
+	for _, other := range others {
+		distance := utils.HaversineMeters(place.Location.Lat, place.Location.Lng, other.Location.Lat, other.Location.Lng)
+		if distance <= duplicateRadiusMeters {
+			return true
+		}
+		if distance <= duplicateNameRadiusMeters && utils.NameSimilarity(place.Name, other.Name) >= duplicateNameSimilarity {
+			return true
+		}
+	}
+	return false
+}
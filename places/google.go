@@ -0,0 +1,99 @@
+package places
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"ulyngo/utils"
+)
+
+const googleTextSearchURL = "https://maps.googleapis.com/maps/api/place/textsearch/json"
+
+// GoogleProvider mencari tempat dengan Google Places Text Search.
+type GoogleProvider struct {
+	APIKey     string
+	BaseURL    string // Kosong berarti endpoint resmi Google
+	HTTPClient *http.Client
+}
+
+// Name mengembalikan nama penyedia.
+func (g *GoogleProvider) Name() string {
+	return SourceGoogle
+}
+
+type googleTextSearchResponse struct {
+	Results []struct {
+		PlaceID          string `json:"place_id"`
+		Name             string `json:"name"`
+		FormattedAddress string `json:"formatted_address"`
+		Geometry         struct {
+			Location utils.LatLng `json:"location"`
+		} `json:"geometry"`
+		Rating float64 `json:"rating,omitempty"`
+	} `json:"results"`
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// Search memanggil Text Search dengan lokasi bias jika ada.
+func (g *GoogleProvider) Search(ctx context.Context, req Request) ([]Place, error) {
+	if g.APIKey == "" {
+		return nil, fmt.Errorf("API key not configured")
+	}
+	baseURL := g.BaseURL
+	if baseURL == "" {
+		baseURL = googleTextSearchURL
+	}
+	params := url.Values{}
+	params.Set("query", req.Query)
+	params.Set("key", g.APIKey)
+	// Lokasi bias membantu API memberikan hasil yang lebih relevan dengan area tujuan
+	if req.Near != nil {
+		params.Set("location", fmt.Sprintf("%f,%f", req.Near.Lat, req.Near.Lng))
+		if req.RadiusMeters > 0 {
+			params.Set("radius", fmt.Sprintf("%.0f", req.RadiusMeters))
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Google Places API: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read places response: %w", err)
+	}
+	var parsed googleTextSearchResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse places response: %w", err)
+	}
+	if parsed.Status != "OK" && parsed.Status != "ZERO_RESULTS" {
+		return nil, fmt.Errorf("places API error: %s - %s", parsed.Status, parsed.ErrorMessage)
+	}
+
+	results := []Place{}
+	for _, result := range parsed.Results {
+		if len(results) >= req.limitOf() {
+			break
+		}
+		results = append(results, Place{
+			ID:             result.PlaceID,
+			Source:         SourceGoogle,
+			Name:           result.Name,
+			Address:        result.FormattedAddress,
+			Location:       result.Geometry.Location,
+			Rating:         result.Rating,
+			DistanceMeters: distanceFrom(req.Near, result.Geometry.Location),
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,211 @@
+package places
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"ulyngo/models"
+	"ulyngo/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Batas pencarian marker lokal.
+const (
+	maxMarkerCandidates  = 200
+	defaultRadiusMeters  = 5000.0
+	markerProximityShare = 0.3 // Porsi skor dari kedekatan ke lokasi bias
+)
+
+// stopWords adalah kata umum dalam kalimat pencarian yang tidak membantu mencocokkan nama tempat.
+var stopWords = map[string]bool{
+	"di": true, "ke": true, "dan": true, "yang": true, "dekat": true, "sekitar": true, "cari": true,
+	"beli": true, "mau": true, "pengen": true, "tempat": true, "the": true, "near": true, "in": true, "at": true,
+}
+
+// MarkerProvider mencari marker kurasi (approved) berdasarkan teks dan kedekatan ke lokasi bias.
+// Teks dicocokkan ke nama, deskripsi, kota, kategori, serta nama dan alias tag marker.
+type MarkerProvider struct {
+	DB *gorm.DB
+}
+
+// Name mengembalikan nama penyedia.
+func (m *MarkerProvider) Name() string {
+	return SourceUlyngo
+}
+
+// Search mengembalikan marker yang cocok, diurutkan dari skor tertinggi. Skor menggabungkan kecocokan teks
+// (nama bernilai paling tinggi, lalu kategori/tag, lalu deskripsi/kota), kedekatan, dan sedikit bonus rating.
+func (m *MarkerProvider) Search(ctx context.Context, req Request) ([]Place, error) {
+	tokens := searchTokens(req.Query)
+	if len(tokens) == 0 {
+		return []Place{}, nil
+	}
+	radius := req.RadiusMeters
+	if radius <= 0 {
+		radius = defaultRadiusMeters
+	}
+
+	query := m.DB.WithContext(ctx).Model(&models.Marker{}).
+		Where("markers.status = ?", models.MarkerStatusApproved).
+		Preload("Category").Preload("Tags")
+	if req.Near != nil {
+		minLat, maxLat, minLng, maxLng := utils.BoundingBox(req.Near.Lat, req.Near.Lng, radius)
+		query = query.Where("markers.latitude BETWEEN ? AND ? AND markers.longitude BETWEEN ? AND ?", minLat, maxLat, minLng, maxLng)
+	}
+	textMatch := m.DB.Where("1 = 0")
+	for _, token := range tokens {
+		pattern := "%" + token + "%"
+		textMatch = textMatch.
+			Or("markers.name ILIKE ? OR markers.description ILIKE ? OR markers.city ILIKE ?", pattern, pattern, pattern).
+			Or("EXISTS (SELECT 1 FROM marker_categories mc WHERE mc.id = markers.category_id AND mc.name ILIKE ?)", pattern).
+			Or(`EXISTS (SELECT 1 FROM marker_has_tags mht JOIN marker_tags mt ON mt.id = mht.tag_id AND mt.deleted_at IS NULL
+				WHERE mht.marker_id = markers.id AND (mt.name ILIKE ? OR EXISTS (
+					SELECT 1 FROM marker_tag_aliases mta WHERE mta.tag_id = mt.id AND mta.alias ILIKE ?)))`, pattern, pattern)
+	}
+	var markers []models.Marker
+	if err := query.Where(textMatch).Order(candidateOrder(tokens, req.Near)).Limit(maxMarkerCandidates).Find(&markers).Error; err != nil {
+		return nil, err
+	}
+
+	aliases, err := m.tagAliases(ctx, markers)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Place, 0, len(markers))
+	for _, marker := range markers {
+		location := utils.LatLng{Lat: marker.Latitude, Lng: marker.Longitude}
+		distance := distanceFrom(req.Near, location)
+		if distance != nil && *distance > radius {
+			continue
+		}
+		score := markerTextScore(&marker, aliases, tokens)
+		if distance != nil {
+			score = score*(1-markerProximityShare) + markerProximityShare*(1-*distance/radius)
+		}
+		score = math.Min(1, score+marker.AvgRating/5*0.05)
+
+		markerID := marker.ID
+		address := ""
+		if marker.City != nil {
+			address = *marker.City
+		}
+		results = append(results, Place{
+			ID:             marker.ID.String(),
+			Source:         SourceUlyngo,
+			Name:           marker.Name,
+			Address:        address,
+			Location:       location,
+			Rating:         marker.AvgRating,
+			MarkerID:       &markerID,
+			DistanceMeters: distance,
+			Score:          math.Round(score*1000) / 1000,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > req.limitOf() {
+		results = results[:req.limitOf()]
+	}
+	return results, nil
+}
+
+// candidateOrder mengurutkan kandidat sebelum dibatasi maxMarkerCandidates agar kandidat yang terpotong adalah
+// yang paling tidak relevan: jumlah kata kunci yang cocok dengan nama lebih dulu, lalu jarak ke lokasi bias
+// (jarak derajat dengan koreksi bujur, cukup untuk mengurutkan di dalam radius pencarian), lalu rating.
+func candidateOrder(tokens []string, near *utils.LatLng) clause.OrderBy {
+	nameMatches := make([]string, len(tokens))
+	args := make([]interface{}, 0, len(tokens)+3)
+	for i, token := range tokens {
+		nameMatches[i] = "CASE WHEN markers.name ILIKE ? THEN 1 ELSE 0 END"
+		args = append(args, "%"+token+"%")
+	}
+	order := "(" + strings.Join(nameMatches, " + ") + ") DESC"
+	if near != nil {
+		order += ", POWER(markers.latitude - ?, 2) + POWER((markers.longitude - ?) * ?, 2)"
+		args = append(args, near.Lat, near.Lng, math.Cos(near.Lat*math.Pi/180))
+	}
+	order += ", markers.avg_rating DESC, markers.id"
+	return clause.OrderBy{Expression: gorm.Expr(order, args...)}
+}
+
+// tagAliases memuat alias tag milik marker kandidat, dikelompokkan per ID tag.
+func (m *MarkerProvider) tagAliases(ctx context.Context, markers []models.Marker) (map[uuid.UUID][]string, error) {
+	tagIDs := []uuid.UUID{}
+	for _, marker := range markers {
+		for _, tag := range marker.Tags {
+			tagIDs = append(tagIDs, tag.ID)
+		}
+	}
+	aliases := map[uuid.UUID][]string{}
+	if len(tagIDs) == 0 {
+		return aliases, nil
+	}
+	var rows []models.MarkerTagAlias
+	if err := m.DB.WithContext(ctx).Where("tag_id IN ?", tagIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		aliases[row.TagID] = append(aliases[row.TagID], row.Alias)
+	}
+	return aliases, nil
+}
+
+// searchTokens memecah kalimat pencarian menjadi kata kunci yang dinormalisasi, tanpa kata umum.
+func searchTokens(query string) []string {
+	tokens := []string{}
+	seen := map[string]bool{}
+	for _, token := range strings.Fields(utils.NormalizeName(query)) {
+		if len([]rune(token)) < 3 || stopWords[token] || seen[token] {
+			continue
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// markerTextScore menghitung kecocokan teks 0..1: rata-rata bobot terbaik setiap kata kunci.
+func markerTextScore(marker *models.Marker, aliases map[uuid.UUID][]string, tokens []string) float64 {
+	name := utils.NormalizeName(marker.Name)
+	secondary := []string{utils.NormalizeName(marker.Category.Name)}
+	for _, tag := range marker.Tags {
+		secondary = append(secondary, utils.NormalizeName(tag.Name))
+		for _, alias := range aliases[tag.ID] {
+			secondary = append(secondary, utils.NormalizeName(alias))
+		}
+	}
+	other := ""
+	if marker.Description != nil {
+		other = utils.NormalizeName(*marker.Description)
+	}
+	if marker.City != nil {
+		other += " " + utils.NormalizeName(*marker.City)
+	}
+
+	total := 0.0
+	for _, token := range tokens {
+		switch {
+		case strings.Contains(name, token):
+			total += 1
+		case containsAny(secondary, token):
+			total += 0.7
+		case strings.Contains(other, token):
+			total += 0.4
+		}
+	}
+	return total / float64(len(tokens))
+}
+
+func containsAny(values []string, token string) bool {
+	for _, value := range values {
+		if strings.Contains(value, token) {
+			return true
+		}
+	}
+	return false
+}
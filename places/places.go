@@ -0,0 +1,105 @@
+// Package places menyediakan pencarian tempat melalui antarmuka PlacesProvider. Marker kurasi ulyngo
+// dicari lebih dulu; Google Places hanya dipakai sebagai cadangan ketika hasil lokal kurang meyakinkan.
+package places
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ulyngo/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sumber hasil pencarian tempat.
+const (
+	SourceUlyngo = "ulyngo" // Marker kurasi di database ulyngo
+	SourceGoogle = "google" // Google Places Text Search
+)
+
+// defaultLimit adalah jumlah hasil maksimum jika Request.Limit tidak diisi.
+const defaultLimit = 10
+
+// Request adalah permintaan pencarian tempat.
+type Request struct {
+	Query        string
+	Near         *utils.LatLng // Lokasi bias, opsional
+	RadiusMeters float64       // Radius pencarian di sekitar Near
+	Limit        int           // Jumlah hasil maksimum, default 10
+}
+
+// Place adalah satu hasil pencarian tempat dalam bentuk yang sama untuk semua sumber.
+type Place struct {
+	ID             string       `json:"id"`     // ID marker untuk sumber ulyngo, place_id untuk Google
+	Source         string       `json:"source"` // ulyngo atau google
+	Name           string       `json:"name"`
+	Address        string       `json:"address,omitempty"`
+	Location       utils.LatLng `json:"location"`
+	Rating         float64      `json:"rating,omitempty"`
+	MarkerID       *uuid.UUID   `json:"marker_id,omitempty"`       // Diisi untuk sumber ulyngo
+	DistanceMeters *float64     `json:"distance_meters,omitempty"` // Jarak dari lokasi bias, jika ada
+	Score          float64      `json:"score"`                     // Relevansi 0..1 untuk hasil ulyngo
+}
+
+// PlacesProvider mencari tempat berdasarkan teks dan lokasi bias.
+type PlacesProvider interface {
+	Search(ctx context.Context, req Request) ([]Place, error)
+	// Name mengembalikan nama penyedia untuk log.
+	Name() string
+}
+
+// NewFromEnv membuat PlacesProvider berdasarkan variabel lingkungan PLACES_PROVIDER:
+//   - "chain" (default): marker ulyngo lebih dulu, lalu Google Places (GOOGLE_MAPS_API_KEY) jika hasil lokal lemah.
+//     PLACES_MIN_LOCAL_RESULTS (default 2) dan PLACES_MIN_LOCAL_SCORE (default 0.6) menentukan kapan hasil lokal cukup.
+//   - "markers": hanya marker ulyngo, tanpa layanan eksternal
+//   - "google": hanya Google Places
+func NewFromEnv(db *gorm.DB) (PlacesProvider, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	markers := &MarkerProvider{DB: db}
+	google := &GoogleProvider{APIKey: os.Getenv("GOOGLE_MAPS_API_KEY"), HTTPClient: client}
+
+	switch strings.ToLower(os.Getenv("PLACES_PROVIDER")) {
+	case "", "chain":
+		chain := &ChainProvider{Primary: markers, MinResults: 2, MinScore: 0.6}
+		// Tanpa kunci API, pencarian tetap berjalan dengan marker lokal saja
+		if google.APIKey != "" {
+			chain.Fallback = google
+		}
+		if value, err := strconv.Atoi(os.Getenv("PLACES_MIN_LOCAL_RESULTS")); err == nil && value > 0 {
+			chain.MinResults = value
+		}
+		if value, err := strconv.ParseFloat(os.Getenv("PLACES_MIN_LOCAL_SCORE"), 64); err == nil && value > 0 && value <= 1 {
+			chain.MinScore = value
+		}
+		return chain, nil
+	case "markers":
+		return markers, nil
+	case "google":
+		return google, nil
+	default:
+		return nil, fmt.Errorf("unknown PLACES_PROVIDER %q (use chain, markers, or google)", os.Getenv("PLACES_PROVIDER"))
+	}
+}
+
+// limitOf mengembalikan batas jumlah hasil permintaan.
+func (r Request) limitOf() int {
+	if r.Limit <= 0 {
+		return defaultLimit
+	}
+	return r.Limit
+}
+
+// distanceFrom menghitung jarak lokasi ke titik bias, atau nil jika tidak ada bias.
+func distanceFrom(near *utils.LatLng, location utils.LatLng) *float64 {
+	if near == nil {
+		return nil
+	}
+	distance := utils.HaversineMeters(near.Lat, near.Lng, location.Lat, location.Lng)
+	return &distance
+}
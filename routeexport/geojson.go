@@ -0,0 +1,73 @@
+package routeexport
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type geoJSONFeatureCollection struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+	Features   []geoJSONFeature       `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// WriteGeoJSON menulis route sebagai FeatureCollection (RFC 7946): satu LineString untuk jalur dan
+// satu Point untuk setiap waypoint. Koordinat memakai urutan [bujur, lintang].
+func WriteGeoJSON(w io.Writer, route *Route) error {
+	properties := map[string]interface{}{
+		"name":             route.Name,
+		"origin":           route.Origin,
+		"destination":      route.Destination,
+		"distance_meters":  route.DistanceMeters,
+		"duration_seconds": route.DurationSeconds,
+	}
+	if route.ID != "" {
+		properties["id"] = route.ID
+	}
+	if route.Description != "" {
+		properties["description"] = route.Description
+	}
+	if !route.CreatedAt.IsZero() {
+		properties["created_at"] = route.CreatedAt.UTC().Format(time.RFC3339)
+	}
+
+	collection := geoJSONFeatureCollection{Type: "FeatureCollection", Properties: properties, Features: []geoJSONFeature{}}
+	if len(route.Track) > 0 {
+		coordinates := make([][2]float64, len(route.Track))
+		for i, point := range route.Track {
+			coordinates[i] = [2]float64{point.Lng, point.Lat}
+		}
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "LineString", Coordinates: coordinates},
+			Properties: map[string]interface{}{"kind": "track", "name": route.Name, "distance_meters": route.DistanceMeters, "duration_seconds": route.DurationSeconds},
+		})
+	}
+	for _, waypoint := range route.Waypoints {
+		featureProperties := map[string]interface{}{"kind": waypoint.Kind, "name": waypoint.Name}
+		if waypoint.Description != "" {
+			featureProperties["description"] = waypoint.Description
+		}
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "Point", Coordinates: [2]float64{waypoint.Lng, waypoint.Lat}},
+			Properties: featureProperties,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(collection)
+}
@@ -0,0 +1,92 @@
+package routeexport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type gpxDocument struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Namespace string        `xml:"xmlns,attr"`
+	Metadata  gpxMetadata   `xml:"metadata"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+	Track     gpxTrack      `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name        string `xml:"name"`
+	Description string `xml:"desc,omitempty"`
+	Time        string `xml:"time,omitempty"`
+}
+
+type gpxWaypoint struct {
+	Lat         string `xml:"lat,attr"`
+	Lon         string `xml:"lon,attr"`
+	Name        string `xml:"name"`
+	Description string `xml:"desc,omitempty"`
+	Type        string `xml:"type,omitempty"`
+}
+
+type gpxTrack struct {
+	Name        string          `xml:"name"`
+	Description string          `xml:"desc,omitempty"`
+	Segment     gpxTrackSegment `xml:"trkseg"`
+}
+
+type gpxTrackSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat string `xml:"lat,attr"`
+	Lon string `xml:"lon,attr"`
+}
+
+// WriteGPX menulis route sebagai GPX 1.1: satu <trk> untuk jalur dan <wpt> untuk setiap waypoint.
+func WriteGPX(w io.Writer, route *Route) error {
+	description := route.summary()
+	if route.Description != "" {
+		description = route.Description + " (" + description + ")"
+	}
+	doc := gpxDocument{
+		Version:   "1.1",
+		Creator:   "ulyngo",
+		Namespace: "http://www.topografix.com/GPX/1/1",
+		Metadata:  gpxMetadata{Name: route.Name, Description: description},
+		Track:     gpxTrack{Name: route.Name, Description: description},
+	}
+	if !route.CreatedAt.IsZero() {
+		doc.Metadata.Time = route.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	for _, waypoint := range route.Waypoints {
+		doc.Waypoints = append(doc.Waypoints, gpxWaypoint{
+			Lat:         formatCoordinate(waypoint.Lat),
+			Lon:         formatCoordinate(waypoint.Lng),
+			Name:        waypoint.Name,
+			Description: waypoint.Description,
+			Type:        waypoint.Kind,
+		})
+	}
+	for _, point := range route.Track {
+		doc.Track.Segment.Points = append(doc.Track.Segment.Points, gpxPoint{Lat: formatCoordinate(point.Lat), Lon: formatCoordinate(point.Lng)})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func formatCoordinate(value float64) string {
+	return fmt.Sprintf("%.6f", value)
+}
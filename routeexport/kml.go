@@ -0,0 +1,92 @@
+package routeexport
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type kmlDocument struct {
+	XMLName   xml.Name    `xml:"kml"`
+	Namespace string      `xml:"xmlns,attr"`
+	Document  kmlContents `xml:"Document"`
+}
+
+type kmlContents struct {
+	Name        string         `xml:"name"`
+	Description string         `xml:"description,omitempty"`
+	Data        []kmlData      `xml:"ExtendedData>Data"`
+	Placemarks  []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPlacemark struct {
+	Name        string         `xml:"name"`
+	Description string         `xml:"description,omitempty"`
+	Point       *kmlPoint      `xml:"Point,omitempty"`
+	LineString  *kmlLineString `xml:"LineString,omitempty"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlLineString struct {
+	Tessellate  int    `xml:"tessellate"`
+	Coordinates string `xml:"coordinates"`
+}
+
+// WriteKML menulis route sebagai KML 2.2: satu Placemark LineString untuk jalur dan satu Placemark Point
+// untuk setiap waypoint. Jarak dan durasi disimpan di ExtendedData.
+func WriteKML(w io.Writer, route *Route) error {
+	contents := kmlContents{
+		Name:        route.Name,
+		Description: route.Description,
+		Data: []kmlData{
+			{Name: "origin", Value: route.Origin},
+			{Name: "destination", Value: route.Destination},
+			{Name: "distance_meters", Value: itoa(route.DistanceMeters)},
+			{Name: "duration_seconds", Value: itoa(route.DurationSeconds)},
+			{Name: "summary", Value: route.summary()},
+		},
+	}
+	if len(route.Track) > 0 {
+		coordinates := make([]string, len(route.Track))
+		for i, point := range route.Track {
+			// KML memakai urutan bujur,lintang
+			coordinates[i] = formatCoordinate(point.Lng) + "," + formatCoordinate(point.Lat)
+		}
+		contents.Placemarks = append(contents.Placemarks, kmlPlacemark{
+			Name:        route.Name,
+			Description: route.summary(),
+			LineString:  &kmlLineString{Tessellate: 1, Coordinates: strings.Join(coordinates, " ")},
+		})
+	}
+	for _, waypoint := range route.Waypoints {
+		contents.Placemarks = append(contents.Placemarks, kmlPlacemark{
+			Name:        waypoint.Name,
+			Description: waypoint.Description,
+			Point:       &kmlPoint{Coordinates: formatCoordinate(waypoint.Lng) + "," + formatCoordinate(waypoint.Lat)},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(kmlDocument{Namespace: "http://www.opengis.net/kml/2.2", Document: contents}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func itoa(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
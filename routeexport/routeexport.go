@@ -0,0 +1,119 @@
+// Package routeexport menulis rute ulyngo ke format file yang dipahami aplikasi navigasi dan peta:
+// GPX (Garmin, OsmAnd), KML (Google Earth), dan GeoJSON.
+package routeexport
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"ulyngo/utils"
+)
+
+// Format ekspor yang didukung.
+const (
+	FormatGPX     = "gpx"
+	FormatKML     = "kml"
+	FormatGeoJSON = "geojson"
+)
+
+// Jenis waypoint.
+const (
+	WaypointOrigin      = "origin"
+	WaypointDestination = "destination"
+	WaypointStop        = "stop"
+	WaypointReturnShop  = "return_shop"
+)
+
+// Waypoint adalah titik bernama di sepanjang rute, misalnya asal, tujuan, atau tempat singgah yang dipilih.
+type Waypoint struct {
+	Name        string
+	Description string
+	Kind        string // origin, destination, stop, atau return_shop
+	Lat         float64
+	Lng         float64
+}
+
+// Route adalah rute yang siap diekspor: jalur (track), waypoint, dan metadata.
+type Route struct {
+	ID              string
+	Name            string
+	Description     string
+	Origin          string
+	Destination     string
+	DistanceMeters  int64
+	DurationSeconds int64
+	CreatedAt       time.Time
+	Track           []utils.LatLng
+	Waypoints       []Waypoint
+}
+
+// Formats mengembalikan daftar format yang didukung.
+func Formats() []string {
+	return []string{FormatGPX, FormatKML, FormatGeoJSON}
+}
+
+// ContentType mengembalikan MIME type dan ekstensi file untuk format.
+func ContentType(format string) (string, string, error) {
+	switch format {
+	case FormatGPX:
+		return "application/gpx+xml", "gpx", nil
+	case FormatKML:
+		return "application/vnd.google-earth.kml+xml", "kml", nil
+	case FormatGeoJSON:
+		return "application/geo+json", "geojson", nil
+	}
+	return "", "", fmt.Errorf("unsupported format %q, expected one of %s", format, strings.Join(Formats(), ", "))
+}
+
+// Write menulis route ke w dalam format yang diminta.
+func Write(w io.Writer, format string, route *Route) error {
+	switch format {
+	case FormatGPX:
+		return WriteGPX(w, route)
+	case FormatKML:
+		return WriteKML(w, route)
+	case FormatGeoJSON:
+		return WriteGeoJSON(w, route)
+	}
+	_, _, err := ContentType(format)
+	return err
+}
+
+// summary mengembalikan ringkasan jarak dan durasi yang mudah dibaca, misalnya "12.3 km, 25 min".
+func (r *Route) summary() string {
+	duration := time.Duration(r.DurationSeconds) * time.Second
+	hours := int(duration.Hours())
+	minutes := int(duration.Minutes()) % 60
+	text := fmt.Sprintf("%.1f km, ", float64(r.DistanceMeters)/1000)
+	if hours > 0 {
+		text += fmt.Sprintf("%d h %d min", hours, minutes)
+	} else {
+		text += fmt.Sprintf("%d min", minutes)
+	}
+	return text
+}
+
+// FileName mengembalikan nama file yang aman untuk header Content-Disposition.
+func FileName(name, extension string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			builder.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			if !strings.HasSuffix(builder.String(), "-") {
+				builder.WriteRune('-')
+			}
+		}
+	}
+	base := strings.Trim(builder.String(), "-")
+	if base == "" {
+		base = "route"
+	}
+	if len(base) > 80 {
+		base = base[:80]
+	}
+	return base + "." + extension
+}
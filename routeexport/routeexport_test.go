@@ -0,0 +1,329 @@
+package routeexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"ulyngo/utils"
+)
+
+func testRoute() *Route {
+	return &Route{
+		ID:              "route-1",
+		Name:            "Bandung ke Lembang",
+		Description:     "Lewat Setiabudi",
+		Origin:          "Bandung",
+		Destination:     "Lembang",
+		DistanceMeters:  12345,
+		DurationSeconds: 3900,
+		CreatedAt:       time.Date(2025, 1, 3, 19, 0, 0, 0, time.FixedZone("WIB", 7*3600)),
+		Track:           []utils.LatLng{{Lat: -6.917464, Lng: 107.609505}, {Lat: -6.8121, Lng: 107.6178}},
+		Waypoints: []Waypoint{
+			{Name: "Bandung", Kind: WaypointOrigin, Lat: -6.917464, Lng: 107.609505},
+			{Name: "Kopi Aroma", Description: "Kopi", Kind: WaypointStop, Lat: -6.9201, Lng: 107.6045},
+			{Name: "Lembang", Kind: WaypointDestination, Lat: -6.8121, Lng: 107.6178},
+		},
+	}
+}
+
+func TestWriteGPX(t *testing.T) {
+	tests := []struct {
+		name          string
+		route         *Route
+		wantDesc      string
+		wantTime      string
+		wantWaypoints int
+		wantPoints    []gpxPoint
+	}{
+		{
+			name:          "full route",
+			route:         testRoute(),
+			wantDesc:      "Lewat Setiabudi (12.3 km, 1 h 5 min)",
+			wantTime:      "2025-01-03T12:00:00Z",
+			wantWaypoints: 3,
+			wantPoints:    []gpxPoint{{Lat: "-6.917464", Lon: "107.609505"}, {Lat: "-6.812100", Lon: "107.617800"}},
+		},
+		{
+			name:          "empty route",
+			route:         &Route{Name: "Kosong", DurationSeconds: 600},
+			wantDesc:      "0.0 km, 10 min",
+			wantWaypoints: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteGPX(&buf, tt.route); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(buf.String(), xml.Header) {
+				t.Errorf("output does not start with the XML header")
+			}
+			var doc gpxDocument
+			if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatalf("invalid GPX: %v\n%s", err, buf.String())
+			}
+			if doc.Version != "1.1" || doc.XMLName.Space != "http://www.topografix.com/GPX/1/1" {
+				t.Errorf("version %q namespace %q", doc.Version, doc.XMLName.Space)
+			}
+			if doc.Metadata.Description != tt.wantDesc || doc.Track.Description != tt.wantDesc {
+				t.Errorf("desc = %q / %q, want %q", doc.Metadata.Description, doc.Track.Description, tt.wantDesc)
+			}
+			if doc.Metadata.Time != tt.wantTime {
+				t.Errorf("time = %q, want %q", doc.Metadata.Time, tt.wantTime)
+			}
+			if len(doc.Waypoints) != tt.wantWaypoints {
+				t.Fatalf("got %d waypoints, want %d", len(doc.Waypoints), tt.wantWaypoints)
+			}
+			for i, waypoint := range doc.Waypoints {
+				want := tt.route.Waypoints[i]
+				if waypoint.Name != want.Name || waypoint.Type != want.Kind || waypoint.Description != want.Description {
+					t.Errorf("waypoint %d = %+v, want %+v", i, waypoint, want)
+				}
+				if waypoint.Lat != formatCoordinate(want.Lat) || waypoint.Lon != formatCoordinate(want.Lng) {
+					t.Errorf("waypoint %d at %s,%s, want %v,%v", i, waypoint.Lat, waypoint.Lon, want.Lat, want.Lng)
+				}
+			}
+			if len(doc.Track.Segment.Points) != len(tt.wantPoints) {
+				t.Fatalf("got %d track points, want %d", len(doc.Track.Segment.Points), len(tt.wantPoints))
+			}
+			for i, point := range doc.Track.Segment.Points {
+				if point != tt.wantPoints[i] {
+					t.Errorf("track point %d = %+v, want %+v", i, point, tt.wantPoints[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteKML(t *testing.T) {
+	tests := []struct {
+		name           string
+		route          *Route
+		wantData       map[string]string
+		wantPlacemarks []kmlPlacemark
+	}{
+		{
+			name:  "full route",
+			route: testRoute(),
+			wantData: map[string]string{
+				"origin": "Bandung", "destination": "Lembang",
+				"distance_meters": "12345", "duration_seconds": "3900", "summary": "12.3 km, 1 h 5 min",
+			},
+			wantPlacemarks: []kmlPlacemark{
+				// KML memakai urutan bujur,lintang
+				{Name: "Bandung ke Lembang", Description: "12.3 km, 1 h 5 min", LineString: &kmlLineString{Tessellate: 1, Coordinates: "107.609505,-6.917464 107.617800,-6.812100"}},
+				{Name: "Bandung", Point: &kmlPoint{Coordinates: "107.609505,-6.917464"}},
+				{Name: "Kopi Aroma", Description: "Kopi", Point: &kmlPoint{Coordinates: "107.604500,-6.920100"}},
+				{Name: "Lembang", Point: &kmlPoint{Coordinates: "107.617800,-6.812100"}},
+			},
+		},
+		{
+			name:     "no track has no line string",
+			route:    &Route{Name: "Titik", Waypoints: []Waypoint{{Name: "Braga", Lat: -6.9175, Lng: 107.6095}}},
+			wantData: map[string]string{"distance_meters": "0", "summary": "0.0 km, 0 min"},
+			wantPlacemarks: []kmlPlacemark{
+				{Name: "Braga", Point: &kmlPoint{Coordinates: "107.609500,-6.917500"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteKML(&buf, tt.route); err != nil {
+				t.Fatal(err)
+			}
+			var doc kmlDocument
+			if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatalf("invalid KML: %v\n%s", err, buf.String())
+			}
+			if doc.XMLName.Space != "http://www.opengis.net/kml/2.2" {
+				t.Errorf("namespace = %q", doc.XMLName.Space)
+			}
+			data := map[string]string{}
+			for _, item := range doc.Document.Data {
+				data[item.Name] = item.Value
+			}
+			for name, want := range tt.wantData {
+				if data[name] != want {
+					t.Errorf("ExtendedData %s = %q, want %q", name, data[name], want)
+				}
+			}
+			if len(doc.Document.Placemarks) != len(tt.wantPlacemarks) {
+				t.Fatalf("got %d placemarks, want %d", len(doc.Document.Placemarks), len(tt.wantPlacemarks))
+			}
+			for i, got := range doc.Document.Placemarks {
+				want := tt.wantPlacemarks[i]
+				if got.Name != want.Name || got.Description != want.Description {
+					t.Errorf("placemark %d = %q/%q, want %q/%q", i, got.Name, got.Description, want.Name, want.Description)
+				}
+				if (got.Point == nil) != (want.Point == nil) || (got.Point != nil && *got.Point != *want.Point) {
+					t.Errorf("placemark %d point = %+v, want %+v", i, got.Point, want.Point)
+				}
+				if (got.LineString == nil) != (want.LineString == nil) || (got.LineString != nil && *got.LineString != *want.LineString) {
+					t.Errorf("placemark %d line string = %+v, want %+v", i, got.LineString, want.LineString)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	type feature struct {
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	}
+	tests := []struct {
+		name            string
+		route           *Route
+		wantProperties  map[string]interface{}
+		wantMissing     []string
+		wantGeometries  []string
+		wantCoordinates []string
+		wantKinds       []string
+	}{
+		{
+			name:  "full route",
+			route: testRoute(),
+			wantProperties: map[string]interface{}{
+				"id": "route-1", "name": "Bandung ke Lembang", "description": "Lewat Setiabudi",
+				"distance_meters": 12345.0, "duration_seconds": 3900.0, "created_at": "2025-01-03T12:00:00Z",
+			},
+			wantGeometries:  []string{"LineString", "Point", "Point", "Point"},
+			wantCoordinates: []string{"[[107.609505,-6.917464],[107.6178,-6.8121]]", "[107.609505,-6.917464]", "[107.6045,-6.9201]", "[107.6178,-6.8121]"},
+			wantKinds:       []string{"track", WaypointOrigin, WaypointStop, WaypointDestination},
+		},
+		{
+			name:           "optional properties omitted",
+			route:          &Route{Name: "Kosong"},
+			wantProperties: map[string]interface{}{"name": "Kosong", "origin": ""},
+			wantMissing:    []string{"id", "description", "created_at"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteGeoJSON(&buf, tt.route); err != nil {
+				t.Fatal(err)
+			}
+			var collection struct {
+				Type       string                 `json:"type"`
+				Properties map[string]interface{} `json:"properties"`
+				Features   []feature              `json:"features"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &collection); err != nil {
+				t.Fatalf("invalid GeoJSON: %v\n%s", err, buf.String())
+			}
+			if collection.Type != "FeatureCollection" || collection.Features == nil {
+				t.Errorf("type = %q, features = %v", collection.Type, collection.Features)
+			}
+			for key, want := range tt.wantProperties {
+				if got, ok := collection.Properties[key]; !ok || got != want {
+					t.Errorf("property %s = %v, want %v", key, got, want)
+				}
+			}
+			for _, key := range tt.wantMissing {
+				if _, ok := collection.Properties[key]; ok {
+					t.Errorf("unexpected property %s", key)
+				}
+			}
+			if len(collection.Features) != len(tt.wantGeometries) {
+				t.Fatalf("got %d features, want %d", len(collection.Features), len(tt.wantGeometries))
+			}
+			for i, feature := range collection.Features {
+				if feature.Geometry.Type != tt.wantGeometries[i] {
+					t.Errorf("feature %d geometry = %q, want %q", i, feature.Geometry.Type, tt.wantGeometries[i])
+				}
+				if string(feature.Geometry.Coordinates) != tt.wantCoordinates[i] {
+					t.Errorf("feature %d coordinates = %s, want %s", i, feature.Geometry.Coordinates, tt.wantCoordinates[i])
+				}
+				if feature.Properties["kind"] != tt.wantKinds[i] {
+					t.Errorf("feature %d kind = %v, want %q", i, feature.Properties["kind"], tt.wantKinds[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteFormats(t *testing.T) {
+	tests := []struct {
+		format        string
+		wantType      string
+		wantExtension string
+		wantPrefix    string
+		wantErr       bool
+	}{
+		{FormatGPX, "application/gpx+xml", "gpx", "<?xml", false},
+		{FormatKML, "application/vnd.google-earth.kml+xml", "kml", "<?xml", false},
+		{FormatGeoJSON, "application/geo+json", "geojson", "{", false},
+		{"shp", "", "", "", true},
+		{"", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			contentType, extension, err := ContentType(tt.format)
+			if (err != nil) != tt.wantErr || contentType != tt.wantType || extension != tt.wantExtension {
+				t.Errorf("ContentType(%q) = %q, %q, %v", tt.format, contentType, extension, err)
+			}
+			var buf bytes.Buffer
+			err = Write(&buf, tt.format, testRoute())
+			if tt.wantErr {
+				if err == nil || buf.Len() != 0 {
+					t.Errorf("Write(%q) = %v with %d bytes, want error and no output", tt.format, err, buf.Len())
+				}
+				return
+			}
+			if err != nil || !strings.HasPrefix(buf.String(), tt.wantPrefix) {
+				t.Errorf("Write(%q) = %v, output starts with %.20q", tt.format, err, buf.String())
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		distance, duration int64
+		want               string
+	}{
+		{0, 0, "0.0 km, 0 min"},
+		{12345, 1500, "12.3 km, 25 min"},
+		{950, 59, "0.9 km, 0 min"},
+		{100000, 3600, "100.0 km, 1 h 0 min"},
+		{64000, 3900, "64.0 km, 1 h 5 min"},
+	}
+	for _, tt := range tests {
+		route := &Route{DistanceMeters: tt.distance, DurationSeconds: tt.duration}
+		if got := route.summary(); got != tt.want {
+			t.Errorf("summary(%d m, %d s) = %q, want %q", tt.distance, tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Bandung ke Lembang", "bandung-ke-lembang.gpx"},
+		{"  Kopi & Teh -- Braga_2025 ", "kopi-teh-braga-2025.gpx"},
+		{"Rute \"a/b\"; rm -rf", "rute-ab-rm-rf.gpx"},
+		{"Café Ñusantara", "caf-usantara.gpx"},
+		{"", "route.gpx"},
+		{"☕☕", "route.gpx"},
+		{strings.Repeat("a", 100), strings.Repeat("a", 80) + ".gpx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FileName(tt.name, "gpx"); got != tt.want {
+				t.Errorf("FileName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package sentiment
+
+import (
+	"context"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// LexiconAnalyzer adalah analyzer sentimen berbasis kamus kata untuk bahasa Indonesia (termasuk bahasa gaul)
+// dan Inggris. Tidak membutuhkan layanan eksternal sehingga cocok sebagai default dan fallback.
+//
+// Setiap kata bernilai -3..3. Nilai kata dibalik oleh negasi di hingga tiga kata sebelumnya
+// ("tidak enak"), diperkuat oleh penguat ("sangat enak", "enak banget"), dan klausa setelah
+// kata pertentangan ("tapi", "but") diberi bobot lebih besar. Total dinormalisasi ke -1..1.
+type LexiconAnalyzer struct {
+	words        map[string]float64
+	negators     map[string]bool
+	intensifiers map[string]float64
+	contrasts    map[string]bool
+	stopwordsID  map[string]bool
+	stopwordsEN  map[string]bool
+}
+
+const (
+	negationFactor     = -0.74 // Negasi membalik dan sedikit melemahkan nilai kata
+	negationWindow     = 3     // Jumlah kata sebelumnya yang diperiksa untuk negasi
+	contrastBefore     = 0.5   // Bobot klausa sebelum kata pertentangan
+	contrastAfter      = 1.5   // Bobot klausa sesudah kata pertentangan
+	normalizationAlpha = 15.0  // Konstanta normalisasi skor (mengikuti VADER)
+	boundaryToken      = "."   // Penanda batas klausa hasil tokenize
+)
+
+// NewLexiconAnalyzer membuat LexiconAnalyzer dengan kamus bawaan.
+func NewLexiconAnalyzer() *LexiconAnalyzer {
+	return &LexiconAnalyzer{
+		words:        lexiconWords,
+		negators:     toSet(negatorWords),
+		intensifiers: intensifierWords,
+		contrasts:    toSet(contrastWords),
+		stopwordsID:  toSet(indonesianStopwords),
+		stopwordsEN:  toSet(englishStopwords),
+	}
+}
+
+// Name mengembalikan nama analyzer.
+func (l *LexiconAnalyzer) Name() string {
+	return "lexicon"
+}
+
+// Analyze menghitung skor sentimen teks menggunakan kamus kata.
+func (l *LexiconAnalyzer) Analyze(ctx context.Context, text string) (*Result, error) {
+	tokens := tokenize(text)
+	result := &Result{Language: l.detectLanguage(tokens), Analyzer: l.Name()}
+
+	var sum float64
+	clauseStart, negationStart := 0, 0
+	for i, token := range tokens {
+		if token == boundaryToken {
+			negationStart = i + 1
+			continue
+		}
+		if l.contrasts[token] {
+			// Klausa sebelum "tapi" dilemahkan, klausa sesudahnya diperkuat di bawah
+			sum *= contrastBefore
+			clauseStart, negationStart = i+1, i+1
+			continue
+		}
+		// Penguat sesudah kata sentimen ("enak parah") tidak dinilai sebagai kata tersendiri
+		if _, isIntensifier := l.intensifiers[token]; isIntensifier && i > 0 {
+			if _, _, prevScored := l.lookup(tokens[i-1]); prevScored {
+				continue
+			}
+		}
+		value, boost, ok := l.lookup(token)
+		if !ok {
+			continue
+		}
+		value *= boost
+
+		// Penguat sebelum kata ("sangat enak") atau sesudahnya ("enak banget")
+		if i > 0 {
+			if factor, ok := l.intensifiers[tokens[i-1]]; ok {
+				value *= factor
+			}
+		}
+		if i+1 < len(tokens) {
+			if factor, ok := l.intensifiers[tokens[i+1]]; ok {
+				value *= factor
+			}
+		}
+		for j := max(negationStart, i-negationWindow); j < i; j++ {
+			if l.negators[tokens[j]] {
+				value *= negationFactor
+				break
+			}
+		}
+		if clauseStart > 0 {
+			value *= contrastAfter
+		}
+		sum += value
+	}
+
+	result.Score = clampScore(sum / math.Sqrt(sum*sum+normalizationAlpha))
+	result.Label = LabelForScore(result.Score)
+	return result, nil
+}
+
+// lookup mencari nilai kata, termasuk bentuk berimbuhan sederhana bahasa Indonesia
+// ("terenak", "kemahalan", "enaknya"). boost > 1 untuk imbuhan yang bermakna "paling/terlalu".
+func (l *LexiconAnalyzer) lookup(token string) (float64, float64, bool) {
+	if value, ok := l.words[token]; ok {
+		return value, 1, true
+	}
+	for _, suffix := range []string{"nya", "lah", "kah", "in"} {
+		if base, ok := strings.CutSuffix(token, suffix); ok && len(base) > 2 {
+			if value, ok := l.words[base]; ok {
+				return value, 1, true
+			}
+		}
+	}
+	if base, ok := strings.CutPrefix(token, "ter"); ok && len(base) > 2 {
+		if value, ok := l.words[base]; ok {
+			return value, 1.3, true
+		}
+	}
+	if strings.HasPrefix(token, "ke") && strings.HasSuffix(token, "an") && len(token) > 6 {
+		if value, ok := l.words[token[2:len(token)-2]]; ok {
+			return value, 1.3, true
+		}
+	}
+	return 0, 0, false
+}
+
+// detectLanguage menebak bahasa dari jumlah stopword Indonesia dan Inggris.
+func (l *LexiconAnalyzer) detectLanguage(tokens []string) string {
+	var id, en int
+	for _, token := range tokens {
+		if l.stopwordsID[token] {
+			id++
+		}
+		if l.stopwordsEN[token] {
+			en++
+		}
+	}
+	switch {
+	case id == 0 && en == 0:
+		return ""
+	case id >= en:
+		return "id"
+	default:
+		return "en"
+	}
+}
+
+// tokenize memecah teks menjadi kata huruf kecil. Tanda baca pemisah klausa (",", ".", "!", dst.)
+// menjadi token boundaryToken agar negasi tidak melewati batas klausa. Huruf berulang ("enakkk")
+// diringkas dan kata ulang ("enak-enak") dijadikan satu kata.
+func tokenize(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		field := strings.Trim(word.String(), "-'")
+		word.Reset()
+		if left, right, ok := strings.Cut(field, "-"); ok && left == right {
+			field = left
+		}
+		for _, part := range strings.Split(field, "-") {
+			if part = squeezeRepeats(part); part != "" {
+				tokens = append(tokens, part)
+			}
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || r == '-' || r == '\'':
+			word.WriteRune(r)
+		case strings.ContainsRune(",.;:!?()\n", r):
+			flush()
+			if len(tokens) > 0 && tokens[len(tokens)-1] != boundaryToken {
+				tokens = append(tokens, boundaryToken)
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// squeezeRepeats meringkas huruf yang berulang tiga kali atau lebih menjadi satu ("mantappp" -> "mantap").
+func squeezeRepeats(word string) string {
+	runes := []rune(word)
+	out := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+		if j-i >= 3 {
+			out = append(out, runes[i])
+		} else {
+			out = append(out, runes[i:j]...)
+		}
+		i = j
+	}
+	return string(out)
+}
+
+func toSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// lexiconWords adalah nilai sentimen kata (-3..3) untuk bahasa Indonesia, bahasa gaul, dan Inggris.
+var lexiconWords = map[string]float64{
+	// Indonesia - positif
+	"enak": 2, "lezat": 2.5, "nikmat": 2, "sedap": 2, "gurih": 1.5, "mantap": 2.5, "mantab": 2.5, "mantul": 2.5,
+	"bagus": 2, "baik": 1.5, "indah": 2, "cantik": 2, "keren": 2, "asik": 2, "asyik": 2, "seru": 2,
+	"nyaman": 2, "bersih": 1.5, "rapi": 1.2, "ramah": 2, "sopan": 1.5, "murah": 1.5, "terjangkau": 1.5,
+	"worth": 1.5, "rekomendasi": 2, "rekomen": 2, "recommended": 2, "rekomended": 2, "suka": 1.8, "senang": 2,
+	"puas": 2, "memuaskan": 2.2, "cepat": 1.2, "sejuk": 1.5, "adem": 1.5, "segar": 1.5, "luas": 1,
+	"strategis": 1.2, "aman": 1.5, "lengkap": 1.2, "istimewa": 2.5, "sempurna": 3, "terbaik": 3, "juara": 2.5,
+	"sip": 1.5, "oke": 1, "ok": 1, "top": 2, "josss": 2.5, "jos": 2, "maknyus": 2.5, "endul": 2.5, "endes": 2.5,
+	"ciamik": 2.5, "kece": 2, "cakep": 2, "hits": 1.2, "instagramable": 1.5, "recommend": 2, "nagih": 2,
+	"melimpah": 1.5, "banyak": 0.5, "sabar": 1, "tenang": 1.5, "damai": 1.5, "mewah": 1.5, "menyenangkan": 2,
+	"terima": 0.3, "kasih": 0.8, "mantep": 2.5, "lumayan": 0.8, "cocok": 1.5, "fresh": 1.5, "sukses": 2,
+	// Indonesia - negatif
+	"mahal": -1.5, "kotor": -2, "jorok": -2.5, "bau": -2, "pesing": -2.5, "jelek": -2, "buruk": -2.5,
+	"kecewa": -2.5, "mengecewakan": -2.5, "lama": -1, "lambat": -1.5, "lelet": -1.8, "judes": -2, "jutek": -2,
+	"kasar": -2, "galak": -1.8, "ramai": -0.3, "sesak": -1.5, "panas": -1, "pengap": -1.8, "sempit": -1,
+	"macet": -1.5, "rusak": -2, "basi": -2.8, "hambar": -1.5, "asin": -1, "keasinan": -1.5, "pahit": -1,
+	"keras": -0.8, "dingin": -0.3, "zonk": -2.5, "parah": -2.5, "payah": -2, "ancur": -2.5, "hancur": -2.5,
+	"nyesel": -2.5, "menyesal": -2.5, "kapok": -2.5, "tipu": -3, "penipu": -3, "bohong": -2.5, "menipu": -3,
+	"kurang": -1, "antri": -0.5, "antre": -0.5, "berisik": -1.5, "bising": -1.5,
+	"licin": -1, "gelap": -0.8, "seram": -1, "angker": -1.2, "bahaya": -2, "berbahaya": -2.2, "kecil": -0.3,
+	"overprice": -2, "overpriced": -2, "pelit": -1.5, "sombong": -2, "cuek": -1.2,
+	"lumutan": -1.5, "kumuh": -2, "becek": -1.5, "males": -1.5, "malas": -1.2, "capek": -1, "ribet": -1.5,
+	"susah": -1.2, "sulit": -1.2, "mending": -0.5, "sayang": -0.3, "gagal": -2, "ampas": -2.5, "norak": -1.5,
+	// Inggris - positif
+	"good": 1.9, "great": 3, "excellent": 3, "amazing": 2.8, "awesome": 2.8, "delicious": 2.8, "tasty": 2,
+	"nice": 1.8, "love": 3, "loved": 2.9, "like": 1.5, "clean": 1.7, "friendly": 2.2, "cheap": 1,
+	"affordable": 1.5, "beautiful": 2.9, "perfect": 2.7, "best": 3, "fantastic": 2.9, "wonderful": 2.7,
+	"comfortable": 1.8, "cozy": 2, "helpful": 1.8, "fast": 1, "quick": 1, "happy": 2.7,
+	"enjoy": 2.2, "enjoyed": 2.2, "pleasant": 2.2, "lovely": 2.8, "worthwhile": 2, "fun": 2.3, "cool": 1.3,
+	"satisfied": 1.8, "superb": 3, "must": 0.5, "gem": 2.5, "authentic": 1.5,
+	// Inggris - negatif
+	"bad": -2.5, "terrible": -3, "awful": -3, "horrible": -3, "dirty": -2, "rude": -2, "expensive": -1.2,
+	"slow": -1.3, "disappointing": -2.2, "disappointed": -2.2, "worst": -3.1, "poor": -2.1, "bland": -1.5,
+	"smelly": -2, "crowded": -1, "noisy": -1.4, "hate": -2.7, "overrated": -2, "stale": -1.8, "cold": -0.5,
+	"broken": -1.8, "unfriendly": -2, "scam": -3, "avoid": -1.5, "waste": -2.2, "mediocre": -1.2,
+	"boring": -1.5, "unsafe": -2, "dangerous": -2, "annoying": -1.8, "regret": -2,
+}
+
+// negatorWords membalik nilai kata sesudahnya.
+var negatorWords = []string{
+	"tidak", "tak", "gak", "ga", "gk", "nggak", "ngga", "enggak", "engga", "tdk", "bukan", "belum", "blm",
+	"jangan", "kagak", "ndak", "nda", "bkn", "nope",
+	"not", "no", "never", "dont", "don't", "isn't", "isnt", "wasn't", "wasnt", "aren't", "didn't", "didnt",
+	"won't", "cannot", "can't", "nothing", "without", "hardly",
+}
+
+// intensifierWords memperkuat (atau melemahkan, jika < 1) kata di sebelahnya.
+var intensifierWords = map[string]float64{
+	"sangat": 1.5, "banget": 1.5, "bgt": 1.5, "bngt": 1.5, "sekali": 1.4, "amat": 1.4, "paling": 1.5,
+	"terlalu": 1.4, "super": 1.5, "pol": 1.5, "poll": 1.5, "abis": 1.3, "parah": 1.3, "bener": 1.3, "benar": 1.2,
+	"agak": 0.7, "sedikit": 0.7, "cukup": 0.8, "lumayan": 0.9,
+	"very": 1.5, "really": 1.4, "so": 1.3, "extremely": 1.7, "too": 1.3, "absolutely": 1.6,
+	"quite": 0.9, "slightly": 0.6, "somewhat": 0.7, "pretty": 1.1,
+}
+
+// contrastWords memisahkan klausa, klausa sesudahnya lebih menentukan sentimen.
+var contrastWords = []string{"tapi", "tetapi", "namun", "cuma", "cuman", "sayangnya", "but", "however", "although", "though"}
+
+var indonesianStopwords = []string{
+	"yang", "dan", "di", "ke", "dari", "ini", "itu", "untuk", "dengan", "ada", "tidak", "gak", "nya", "juga",
+	"sangat", "banget", "tempat", "tempatnya", "makanan", "makanannya", "sini", "saya", "aku", "kami", "kita",
+	"tapi", "sudah", "udah", "bisa", "lagi", "buat", "karena", "kalau", "kalo", "harga", "harganya", "pelayanan",
+}
+
+var englishStopwords = []string{
+	"the", "and", "is", "are", "was", "were", "to", "of", "in", "it", "this", "that", "for", "with", "very",
+	"place", "food", "service", "but", "not", "we", "i", "they", "you", "really", "would", "will", "have", "had",
+}
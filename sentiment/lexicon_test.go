@@ -0,0 +1,129 @@
+package sentiment
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func analyze(t *testing.T, text string) *Result {
+	t.Helper()
+	result, err := NewLexiconAnalyzer().Analyze(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Analyze(%q) failed: %v", text, err)
+	}
+	return result
+}
+
+func TestLexiconAnalyzerLabels(t *testing.T) {
+	tests := []struct {
+		text         string
+		wantLabel    string
+		wantLanguage string
+	}{
+		{"", LabelNeutral, ""},
+		{"   ", LabelNeutral, ""},
+		{"Makanannya enak dan tempatnya bersih", LabelPositive, "id"},
+		{"Tempatnya kotor, pelayanan lambat", LabelNegative, "id"},
+		{"Tidak enak", LabelNegative, "id"},
+		{"gak jelek kok", LabelPositive, "id"},
+		{"Mantappp, enak-enak semua", LabelPositive, ""},
+		{"Terenak se-Bandung", LabelPositive, ""},
+		{"Harganya kemahalan", LabelNegative, "id"},
+		{"Enak tapi mahal banget", LabelNegative, "id"},
+		{"Mahal tapi enak banget", LabelPositive, "id"},
+		{"The food was great and the service was good", LabelPositive, "en"},
+		{"The place is not good", LabelNegative, "en"},
+		{"Saya datang jam 7 pagi", LabelNeutral, "id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			result := analyze(t, tt.text)
+			if result.Label != tt.wantLabel {
+				t.Errorf("Label = %q (score %v), want %q", result.Label, result.Score, tt.wantLabel)
+			}
+			if result.Language != tt.wantLanguage {
+				t.Errorf("Language = %q, want %q", result.Language, tt.wantLanguage)
+			}
+			if result.Score < -1 || result.Score > 1 {
+				t.Errorf("Score %v outside -1..1", result.Score)
+			}
+			if result.Analyzer != "lexicon" {
+				t.Errorf("Analyzer = %q, want lexicon", result.Analyzer)
+			}
+		})
+	}
+}
+
+// TestLexiconAnalyzerModifiers membandingkan skor dua kalimat: weaker harus bernilai lebih kecil dari stronger.
+func TestLexiconAnalyzerModifiers(t *testing.T) {
+	tests := []struct {
+		name             string
+		weaker, stronger string
+	}{
+		{"intensifier before", "enak", "sangat enak"},
+		{"intensifier after", "enak", "enak banget"},
+		{"diminisher", "agak enak", "enak"},
+		{"superlative prefix", "enak", "terenak"},
+		{"negation flips", "tidak enak", "enak"},
+		{"negation flips negative", "mahal", "tidak mahal"},
+		{"negation does not cross clauses", "tidak ramah", "tidak, ramah"},
+		{"negation window is three words", "tidak terlalu pedas enak", "tidak ada yang sama sekali enak"},
+		{"clause after contrast dominates", "enak tapi jelek", "jelek tapi enak"},
+		{"repeated praise", "enak", "enak, lezat, mantap"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weaker, stronger := analyze(t, tt.weaker).Score, analyze(t, tt.stronger).Score
+			if weaker >= stronger {
+				t.Errorf("score(%q) = %v, want less than score(%q) = %v", tt.weaker, weaker, tt.stronger, stronger)
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"Enak BANGET", []string{"enak", "banget"}},
+		{"enak, murah!", []string{"enak", ".", "murah", "."}},
+		{"enak!!! murah...", []string{"enak", ".", "murah", "."}},
+		{"mantappp poll", []string{"mantap", "poll"}},
+		{"enak-enak", []string{"enak"}},
+		{"jalan-jalan santai", []string{"jalan", "santai"}},
+		{"se-Bandung", []string{"se", "bandung"}},
+		{"don't go", []string{"don't", "go"}},
+		{"rating 5/5 :)", []string{"rating", "."}},
+		{"-'enak'-", []string{"enak"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelForScore(t *testing.T) {
+	tests := []struct {
+		score float64
+		want  string
+	}{
+		{1, LabelPositive},
+		{neutralThreshold, LabelPositive},
+		{0.049, LabelNeutral},
+		{0, LabelNeutral},
+		{-0.049, LabelNeutral},
+		{-neutralThreshold, LabelNegative},
+		{-1, LabelNegative},
+	}
+	for _, tt := range tests {
+		if got := LabelForScore(tt.score); got != tt.want {
+			t.Errorf("LabelForScore(%v) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package sentiment menyediakan analisis sentimen teks ulasan melalui antarmuka SentimentAnalyzer,
+// dengan implementasi Vertex AI (Gemini) dan analyzer leksikon lokal untuk bahasa Indonesia dan Inggris.
+package sentiment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Label sentimen hasil analisis.
+const (
+	LabelPositive = "positive"
+	LabelNeutral  = "neutral"
+	LabelNegative = "negative"
+)
+
+// neutralThreshold adalah batas absolut skor di bawahnya teks dianggap netral.
+const neutralThreshold = 0.05
+
+// Result adalah hasil analisis sentimen satu teks.
+type Result struct {
+	Score    float64 `json:"score"`    // -1 (sangat negatif) sampai 1 (sangat positif)
+	Label    string  `json:"label"`    // positive, neutral, atau negative
+	Language string  `json:"language"` // Kode bahasa yang terdeteksi ("id", "en") atau kosong jika tidak diketahui
+	Analyzer string  `json:"analyzer"` // Nama analyzer yang menghasilkan skor
+}
+
+// SentimentAnalyzer menganalisis sentimen sebuah teks.
+type SentimentAnalyzer interface {
+	// Analyze menghitung sentimen teks. Teks kosong menghasilkan skor netral.
+	Analyze(ctx context.Context, text string) (*Result, error)
+	// Name mengembalikan nama analyzer, disimpan bersama skor untuk keperluan audit.
+	Name() string
+}
+
+// LabelForScore mengubah skor -1..1 menjadi label sentimen.
+func LabelForScore(score float64) string {
+	switch {
+	case score >= neutralThreshold:
+		return LabelPositive
+	case score <= -neutralThreshold:
+		return LabelNegative
+	default:
+		return LabelNeutral
+	}
+}
+
+// clampScore membatasi skor ke rentang -1..1.
+func clampScore(score float64) float64 {
+	return max(-1, min(1, score))
+}
+
+// FallbackAnalyzer mencoba Primary terlebih dahulu dan memakai Secondary jika Primary gagal,
+// sehingga ulasan tetap mendapat skor walaupun layanan eksternal sedang bermasalah.
+type FallbackAnalyzer struct {
+	Primary   SentimentAnalyzer
+	Secondary SentimentAnalyzer
+}
+
+// Analyze menjalankan Primary, lalu Secondary jika Primary mengembalikan error.
+func (f *FallbackAnalyzer) Analyze(ctx context.Context, text string) (*Result, error) {
+	result, err := f.Primary.Analyze(ctx, text)
+	if err == nil {
+		return result, nil
+	}
+	log.Printf("Sentiment analyzer %s failed, falling back to %s: %v", f.Primary.Name(), f.Secondary.Name(), err)
+	return f.Secondary.Analyze(ctx, text)
+}
+
+// Name mengembalikan nama analyzer utama.
+func (f *FallbackAnalyzer) Name() string {
+	return f.Primary.Name()
+}
+
+// NewFromEnv membuat SentimentAnalyzer berdasarkan variabel lingkungan SENTIMENT_ANALYZER:
+//   - "lexicon" (default): analyzer leksikon lokal, tanpa layanan eksternal
+//   - "vertex": Vertex AI Gemini (GOOGLE_VERTEX_AI_PROJECT_ID, GOOGLE_VERTEX_AI_LOCATION,
+//     opsional SENTIMENT_VERTEX_MODEL), dengan fallback ke analyzer leksikon
+func NewFromEnv() (SentimentAnalyzer, error) {
+	switch strings.ToLower(os.Getenv("SENTIMENT_ANALYZER")) {
+	case "", "lexicon":
+		return NewLexiconAnalyzer(), nil
+	case "vertex":
+		vertex, err := NewVertexAnalyzer(os.Getenv("GOOGLE_VERTEX_AI_PROJECT_ID"), os.Getenv("GOOGLE_VERTEX_AI_LOCATION"), os.Getenv("SENTIMENT_VERTEX_MODEL"))
+		if err != nil {
+			return nil, err
+		}
+		return &FallbackAnalyzer{Primary: vertex, Secondary: NewLexiconAnalyzer()}, nil
+	default:
+		return nil, fmt.Errorf("unknown SENTIMENT_ANALYZER %q (use lexicon or vertex)", os.Getenv("SENTIMENT_ANALYZER"))
+	}
+}
@@ -0,0 +1,136 @@
+package sentiment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const defaultVertexModel = "gemini-2.0-flash-001"
+
+// VertexAnalyzer menganalisis sentimen dengan model Gemini di Vertex AI.
+// Kredensial diambil dari Application Default Credentials, sama seperti fitur perencanaan perjalanan.
+type VertexAnalyzer struct {
+	ProjectID   string
+	Location    string
+	Model       string
+	HTTPClient  *http.Client
+	tokenSource oauth2.TokenSource
+}
+
+// NewVertexAnalyzer membuat VertexAnalyzer. model boleh kosong untuk memakai model default.
+func NewVertexAnalyzer(projectID, location, model string) (*VertexAnalyzer, error) {
+	if projectID == "" || location == "" {
+		return nil, fmt.Errorf("vertex AI environment variables not configured (GOOGLE_VERTEX_AI_PROJECT_ID, GOOGLE_VERTEX_AI_LOCATION)")
+	}
+	if model == "" {
+		model = defaultVertexModel
+	}
+	tokenSource, err := google.DefaultTokenSource(context.Background(), "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token source: %w", err)
+	}
+	return &VertexAnalyzer{
+		ProjectID:   projectID,
+		Location:    location,
+		Model:       model,
+		HTTPClient:  &http.Client{Timeout: 20 * time.Second},
+		tokenSource: tokenSource,
+	}, nil
+}
+
+// Name mengembalikan nama analyzer beserta modelnya.
+func (v *VertexAnalyzer) Name() string {
+	return "vertex:" + v.Model
+}
+
+// Analyze meminta Gemini menilai sentimen teks dan mengembalikan skor dalam format JSON.
+func (v *VertexAnalyzer) Analyze(ctx context.Context, text string) (*Result, error) {
+	if strings.TrimSpace(text) == "" {
+		return &Result{Score: 0, Label: LabelNeutral, Analyzer: v.Name()}, nil
+	}
+	token, err := v.tokenSource.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	textJSON, _ := json.Marshal(text)
+	prompt := fmt.Sprintf(`Nilai sentimen ulasan tempat berikut. Ulasan bisa berbahasa Indonesia (termasuk bahasa gaul) atau Inggris.
+Balas HANYA dengan JSON: {"score": <angka -1.0 sampai 1.0>, "language": "<id|en|lainnya>"}
+-1.0 berarti sangat negatif, 0 netral, 1.0 sangat positif.
+Ulasan: %s`, textJSON)
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{"role": "user", "parts": []map[string]string{{"text": prompt}}},
+		},
+		"generationConfig": map[string]interface{}{
+			"temperature":      0,
+			"responseMimeType": "application/json",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Vertex AI request: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/%s:generateContent",
+		v.Location, v.ProjectID, v.Location, v.Model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request for Vertex AI: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Vertex AI API: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Vertex AI response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vertex AI API returned non-OK status: %d", resp.StatusCode)
+	}
+
+	var vertexResp struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+		} `json:"candidates"`
+	}
+	if err := json.Unmarshal(body, &vertexResp); err != nil || len(vertexResp.Candidates) == 0 || len(vertexResp.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("failed to parse Vertex AI response or no candidates found")
+	}
+
+	jsonText := strings.TrimSpace(vertexResp.Candidates[0].Content.Parts[0].Text)
+	jsonText = strings.TrimPrefix(jsonText, "```json")
+	jsonText = strings.TrimSuffix(jsonText, "```")
+	var parsed struct {
+		Score    *float64 `json:"score"`
+		Language string   `json:"language"`
+	}
+	if err := json.Unmarshal([]byte(jsonText), &parsed); err != nil || parsed.Score == nil {
+		return nil, fmt.Errorf("failed to read sentiment score from Vertex AI text: %q", jsonText)
+	}
+
+	score := clampScore(*parsed.Score)
+	language := parsed.Language
+	if language != "id" && language != "en" {
+		language = ""
+	}
+	return &Result{Score: score, Label: LabelForScore(score), Language: language, Analyzer: v.Name()}, nil
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"encoding/json"
+	"time"
+
+	"ulyngo/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LogActivity mencatat satu aktivitas pengguna ke tabel user_activity_logs.
+// Parameter data akan di-marshal menjadi JSON; gunakan nil jika tidak ada data tambahan.
+// Berikan instance transaksi sebagai db agar log ikut di-rollback bersama perubahan lain.
+func LogActivity(db *gorm.DB, userID uuid.UUID, activityType string, targetID *uuid.UUID, data interface{}) error {
+	activityData := json.RawMessage([]byte("{}"))
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		activityData = raw
+	}
+
+	logEntry := models.UserActivityLog{
+		UserID:       userID,
+		ActivityType: activityType,
+		TargetID:     targetID,
+		ActivityData: activityData,
+		Timestamp:    time.Now(),
+	}
+	return db.Create(&logEntry).Error
+}
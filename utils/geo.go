@@ -0,0 +1,119 @@
+package utils
+
+import "math"
+
+// EarthRadiusMeters adalah jari-jari rata-rata bumi yang dipakai untuk perhitungan jarak.
+const EarthRadiusMeters = 6371000.0
+
+// HaversineMeters menghitung jarak lingkaran besar (great-circle) antara dua koordinat dalam meter.
+func HaversineMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// BoundingBox mengembalikan kotak lintang/bujur yang memuat lingkaran berjari-jari radiusMeters
+// di sekitar titik (lat, lng). Berguna sebagai pra-filter murah sebelum menghitung jarak sebenarnya.
+func BoundingBox(lat, lng, radiusMeters float64) (minLat, maxLat, minLng, maxLng float64) {
+	latDelta := radiusMeters / EarthRadiusMeters * 180 / math.Pi
+	cosLat := math.Cos(lat * math.Pi / 180)
+	lngDelta := 180.0
+	if cosLat > 1e-6 {
+		lngDelta = math.Min(180, latDelta/cosLat)
+	}
+	return lat - latDelta, lat + latDelta, lng - lngDelta, lng + lngDelta
+}
+
+// ProjectOntoPath memproyeksikan titik ke lintasan (polyline) dan mengembalikan jarak terpendek titik ke
+// lintasan serta jarak sepanjang lintasan dari titik awal sampai titik proyeksi, keduanya dalam meter.
+// Setiap segmen dihitung dengan proyeksi equirectangular lokal, cukup akurat untuk jarak beberapa kilometer.
+func ProjectOntoPath(path []LatLng, point LatLng) (offsetMeters, alongMeters float64) {
+	if len(path) == 0 {
+		return math.Inf(1), 0
+	}
+	if len(path) == 1 {
+		return HaversineMeters(point.Lat, point.Lng, path[0].Lat, path[0].Lng), 0
+	}
+	// Koordinat lokal dalam meter dengan titik sebagai pusat
+	metersPerDegLat := EarthRadiusMeters * math.Pi / 180
+	metersPerDegLng := metersPerDegLat * math.Cos(point.Lat*math.Pi/180)
+	local := func(p LatLng) (float64, float64) {
+		return (p.Lng - point.Lng) * metersPerDegLng, (p.Lat - point.Lat) * metersPerDegLat
+	}
+
+	offsetMeters = math.Inf(1)
+	traveled := 0.0
+	for i := 0; i+1 < len(path); i++ {
+		ax, ay := local(path[i])
+		bx, by := local(path[i+1])
+		dx, dy := bx-ax, by-ay
+		t := 0.0
+		if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSquared))
+		}
+		segment := HaversineMeters(path[i].Lat, path[i].Lng, path[i+1].Lat, path[i+1].Lng)
+		if distance := math.Hypot(ax+t*dx, ay+t*dy); distance < offsetMeters {
+			offsetMeters = distance
+			alongMeters = traveled + t*segment
+		}
+		traveled += segment
+	}
+	return offsetMeters, alongMeters
+}
+
+// PathLengthMeters menghitung panjang total lintasan dalam meter.
+func PathLengthMeters(path []LatLng) float64 {
+	total := 0.0
+	for i := 0; i+1 < len(path); i++ {
+		total += HaversineMeters(path[i].Lat, path[i].Lng, path[i+1].Lat, path[i+1].Lng)
+	}
+	return total
+}
+
+// SimplifyPath mengurangi jumlah titik lintasan dengan tetap menjaga bentuknya: titik yang lebih dekat dari
+// toleranceMeters ke titik sebelumnya dibuang, lalu sisanya disederhanakan dengan Douglas-Peucker. Setiap
+// titik lintasan asli berjarak paling jauh 2*toleranceMeters dari lintasan hasil. Titik awal dan akhir selalu dipertahankan.
+func SimplifyPath(path []LatLng, toleranceMeters float64) []LatLng {
+	if len(path) <= 2 || toleranceMeters <= 0 {
+		return append([]LatLng{}, path...)
+	}
+	radial := []LatLng{path[0]}
+	for _, point := range path[1 : len(path)-1] {
+		last := radial[len(radial)-1]
+		if HaversineMeters(last.Lat, last.Lng, point.Lat, point.Lng) >= toleranceMeters {
+			radial = append(radial, point)
+		}
+	}
+	radial = append(radial, path[len(path)-1])
+
+	keep := make([]bool, len(radial))
+	keep[0], keep[len(radial)-1] = true, true
+	// Douglas-Peucker tanpa rekursi agar lintasan panjang tidak memperdalam stack
+	type span struct{ first, last int }
+	stack := []span{{0, len(radial) - 1}}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		farthest, farthestDistance := -1, toleranceMeters
+		segment := []LatLng{radial[current.first], radial[current.last]}
+		for i := current.first + 1; i < current.last; i++ {
+			if distance, _ := ProjectOntoPath(segment, radial[i]); distance > farthestDistance {
+				farthest, farthestDistance = i, distance
+			}
+		}
+		if farthest >= 0 {
+			keep[farthest] = true
+			stack = append(stack, span{current.first, farthest}, span{farthest, current.last})
+		}
+	}
+	simplified := make([]LatLng, 0, len(radial))
+	for i, point := range radial {
+		if keep[i] {
+			simplified = append(simplified, point)
+		}
+	}
+	return simplified
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// metersPerDegree adalah panjang satu derajat busur besar dengan EarthRadiusMeters.
+const metersPerDegree = EarthRadiusMeters * math.Pi / 180
+
+func TestProjectOntoPath(t *testing.T) {
+	// Lintasan lurus di khatulistiwa sepanjang 0.01 derajat, lalu belok ke utara 0.01 derajat
+	path := []LatLng{{0, 0}, {0, 0.01}, {0.01, 0.01}}
+	tests := []struct {
+		name       string
+		path       []LatLng
+		point      LatLng
+		wantOffset float64
+		wantAlong  float64
+	}{
+		{"on the path", path, LatLng{0, 0.005}, 0, 0.005 * metersPerDegree},
+		{"beside first segment", path, LatLng{0.001, 0.004}, 0.001 * metersPerDegree, 0.004 * metersPerDegree},
+		{"beside second segment", path, LatLng{0.006, 0.011}, 0.001 * metersPerDegree, 0.016 * metersPerDegree},
+		{"before start clamps to start", path, LatLng{0, -0.002}, 0.002 * metersPerDegree, 0},
+		{"beyond end clamps to end", path, LatLng{0.013, 0.01}, 0.003 * metersPerDegree, 0.02 * metersPerDegree},
+		{"at a vertex", path, LatLng{0, 0.01}, 0, 0.01 * metersPerDegree},
+		{"repeated points", []LatLng{{0, 0}, {0, 0}, {0, 0.01}}, LatLng{0.001, 0.005}, 0.001 * metersPerDegree, 0.005 * metersPerDegree},
+		{"single point", []LatLng{{0, 0}}, LatLng{0.001, 0}, 0.001 * metersPerDegree, 0},
+		{"empty path", nil, LatLng{0, 0}, math.Inf(1), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, along := ProjectOntoPath(tt.path, tt.point)
+			// Proyeksi equirectangular lokal cukup akurat untuk jarak beberapa kilometer
+			if math.IsInf(tt.wantOffset, 1) {
+				if !math.IsInf(offset, 1) {
+					t.Errorf("offset = %v, want +Inf", offset)
+				}
+			} else if math.Abs(offset-tt.wantOffset) > 0.5 {
+				t.Errorf("offset = %v, want %v", offset, tt.wantOffset)
+			}
+			if math.Abs(along-tt.wantAlong) > 0.5 {
+				t.Errorf("along = %v, want %v", along, tt.wantAlong)
+			}
+		})
+	}
+}
+
+func TestSimplifyPath(t *testing.T) {
+	// Lintasan berkelok di Bandung: 2001 titik berjarak sekitar 11 m dengan gelombang 300 m
+	wiggly := make([]LatLng, 2001)
+	for i := range wiggly {
+		wiggly[i] = LatLng{Lat: -6.9 + 0.0027*math.Sin(float64(i)/100), Lng: 107.6 + float64(i)*0.0001}
+	}
+	straight := make([]LatLng, 101)
+	for i := range straight {
+		straight[i] = LatLng{Lat: -6.9, Lng: 107.6 + float64(i)*0.0001}
+	}
+	tests := []struct {
+		name      string
+		path      []LatLng
+		tolerance float64
+		wantLen   int // 0 berarti hanya batas atas: lebih sedikit dari lintasan asli
+	}{
+		{"straight line keeps endpoints", straight, 5, 2},
+		{"two points unchanged", straight[:2], 50, 2},
+		{"single point unchanged", straight[:1], 50, 1},
+		{"empty path", nil, 50, 0},
+		{"zero tolerance unchanged", wiggly[:50], 0, 50},
+		{"wiggly path", wiggly, 10, 0},
+		{"wiggly path loose tolerance", wiggly, 50, 0},
+		{"all points within tolerance of start", []LatLng{{-6.9, 107.6}, {-6.9, 107.60001}, {-6.90001, 107.6}, {-6.9, 107.60002}}, 50, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SimplifyPath(tt.path, tt.tolerance)
+			if tt.wantLen > 0 || len(tt.path) == 0 {
+				if len(got) != tt.wantLen {
+					t.Fatalf("got %d points, want %d", len(got), tt.wantLen)
+				}
+			} else if len(got) >= len(tt.path) {
+				t.Fatalf("got %d points, want fewer than %d", len(got), len(tt.path))
+			}
+			if len(tt.path) == 0 {
+				return
+			}
+			if got[0] != tt.path[0] || got[len(got)-1] != tt.path[len(tt.path)-1] {
+				t.Errorf("endpoints = %v..%v, want %v..%v", got[0], got[len(got)-1], tt.path[0], tt.path[len(tt.path)-1])
+			}
+			if tt.tolerance <= 0 && !reflect.DeepEqual(got, tt.path) {
+				t.Errorf("path changed with zero tolerance")
+			}
+			for i, point := range tt.path {
+				if offset, _ := ProjectOntoPath(got, point); offset > 2*tt.tolerance+0.5 {
+					t.Errorf("point %d is %.1f m from the simplified path, want at most %.1f", i, offset, 2*tt.tolerance)
+				}
+			}
+		})
+	}
+}
+
+func TestSimplifyPathDoesNotAlias(t *testing.T) {
+	path := []LatLng{{0, 0}, {0, 1}}
+	got := SimplifyPath(path, 10)
+	got[0].Lat = 5
+	if path[0].Lat != 0 {
+		t.Errorf("SimplifyPath returned a slice sharing the input array")
+	}
+}
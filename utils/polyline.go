@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+)
+
+// LatLng adalah satu titik koordinat lintang/bujur.
+type LatLng struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// DecodePolyline mendekode Google Encoded Polyline (presisi 5 digit desimal), misalnya nilai
+// overview_polyline.points dari Directions API, menjadi daftar titik.
+func DecodePolyline(encoded string) ([]LatLng, error) {
+	return DecodePolylinePrecision(encoded, 5)
+}
+
+// DecodePolylinePrecision mendekode encoded polyline dengan presisi tertentu, misalnya 6 untuk Valhalla.
+func DecodePolylinePrecision(encoded string, precision int) ([]LatLng, error) {
+	factor := math.Pow10(precision)
+	points := []LatLng{}
+	var lat, lng int64
+	for index := 0; index < len(encoded); {
+		var deltas [2]int64
+		for i := range deltas {
+			var result int64
+			shift := uint(0)
+			for {
+				if index >= len(encoded) {
+					return nil, fmt.Errorf("polyline is truncated at position %d", index)
+				}
+				b := int64(encoded[index]) - 63
+				index++
+				if b < 0 || b > 63 {
+					return nil, fmt.Errorf("invalid polyline character at position %d", index-1)
+				}
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+				if shift > 60 {
+					return nil, fmt.Errorf("polyline value is too long at position %d", index)
+				}
+			}
+			if result&1 != 0 {
+				deltas[i] = ^(result >> 1)
+			} else {
+				deltas[i] = result >> 1
+			}
+		}
+		lat += deltas[0]
+		lng += deltas[1]
+		points = append(points, LatLng{Lat: float64(lat) / factor, Lng: float64(lng) / factor})
+	}
+	return points, nil
+}
+
+// EncodePolyline mengenkode daftar titik menjadi Google Encoded Polyline (presisi 5 digit desimal).
+func EncodePolyline(points []LatLng) string {
+	buf := []byte{}
+	var prevLat, prevLng int64
+	for _, point := range points {
+		lat := int64(math.Round(point.Lat * 1e5))
+		lng := int64(math.Round(point.Lng * 1e5))
+		buf = appendPolylineValue(buf, lat-prevLat)
+		buf = appendPolylineValue(buf, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+	return string(buf)
+}
+
+// appendPolylineValue menambahkan satu selisih koordinat terenkode ke buf.
+func appendPolylineValue(buf []byte, value int64) []byte {
+	v := value << 1
+	if value < 0 {
+		v = ^v
+	}
+	for v >= 0x20 {
+		buf = append(buf, byte((0x20|(v&0x1f))+63))
+		v >>= 5
+	}
+	return append(buf, byte(v+63))
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDecodePolyline(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    []LatLng
+		wantErr string
+	}{
+		{name: "empty", encoded: "", want: []LatLng{}},
+		// Contoh dari dokumentasi Google Encoded Polyline Algorithm Format
+		{name: "google example", encoded: "_p~iF~ps|U_ulLnnqC_mqNvxq`@", want: []LatLng{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}}},
+		{name: "single point", encoded: "~sbi@_svoS", want: []LatLng{{-6.9, 107.6}}},
+		{name: "truncated", encoded: "_p~iF~ps|U_ulL", wantErr: "truncated"},
+		{name: "invalid character", encoded: "_p~iF ~ps|U", wantErr: "invalid polyline character"},
+		{name: "value too long", encoded: strings.Repeat("~", 20) + "?", wantErr: "too long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodePolyline(tt.encoded)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d points, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if math.Abs(got[i].Lat-tt.want[i].Lat) > 1e-9 || math.Abs(got[i].Lng-tt.want[i].Lng) > 1e-9 {
+					t.Errorf("point %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEncodePolyline(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []LatLng
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"google example", []LatLng{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}}, "_p~iF~ps|U_ulLnnqC_mqNvxq`@"},
+		{"rounds to precision 5", []LatLng{{38.500004, -120.199996}}, "_p~iF~ps|U"},
+		{"repeated point encodes zero delta", []LatLng{{-6.9, 107.6}, {-6.9, 107.6}}, "~sbi@_svoS??"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EncodePolyline(tt.points)
+			if got != tt.want {
+				t.Errorf("EncodePolyline = %q, want %q", got, tt.want)
+			}
+			decoded, err := DecodePolyline(got)
+			if err != nil || len(decoded) != len(tt.points) {
+				t.Fatalf("round trip = %v, %v", decoded, err)
+			}
+			for i := range decoded {
+				if math.Abs(decoded[i].Lat-tt.points[i].Lat) > 0.5e-5 || math.Abs(decoded[i].Lng-tt.points[i].Lng) > 0.5e-5 {
+					t.Errorf("round trip point %d = %+v, want %+v", i, decoded[i], tt.points[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecodePolylinePrecision(t *testing.T) {
+	tests := []struct {
+		name      string
+		encoded   string
+		precision int
+		want      LatLng
+	}{
+		{"precision 5", "_p~iF~ps|U", 5, LatLng{38.5, -120.2}},
+		{"same string at precision 6", "_p~iF~ps|U", 6, LatLng{3.85, -12.02}},
+		{"valhalla precision 6", "_izlhA~rlgdF", 6, LatLng{38.5, -120.2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodePolylinePrecision(tt.encoded, tt.precision)
+			if err != nil || len(got) != 1 {
+				t.Fatalf("DecodePolylinePrecision = %v, %v", got, err)
+			}
+			if math.Abs(got[0].Lat-tt.want.Lat) > 1e-9 || math.Abs(got[0].Lng-tt.want.Lng) > 1e-9 {
+				t.Errorf("point = %+v, want %+v", got[0], tt.want)
+			}
+		})
+	}
+}
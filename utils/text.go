@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeName menyederhanakan nama tempat untuk perbandingan: huruf kecil,
+// tanpa tanda baca, dan spasi berlebih dirapikan.
+func NormalizeName(name string) string {
+	var b strings.Builder
+	lastSpace := true
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			lastSpace = false
+		case !lastSpace:
+			b.WriteRune(' ')
+			lastSpace = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// NameSimilarity menghitung kemiripan dua nama tempat pada rentang 0..1.
+// Nilai diambil dari yang terbesar antara rasio jarak Levenshtein dan kemiripan token (Jaccard),
+// sehingga "Cimol Bojot Aa" dan "Aa Cimol Bojot" tetap dianggap mirip.
+func NameSimilarity(a, b string) float64 {
+	na, nb := NormalizeName(a), NormalizeName(b)
+	if na == "" || nb == "" {
+		return 0
+	}
+	if na == nb {
+		return 1
+	}
+
+	ra, rb := []rune(na), []rune(nb)
+	maxLen := len(ra)
+	if len(rb) > maxLen {
+		maxLen = len(rb)
+	}
+	editRatio := 1 - float64(levenshtein(ra, rb))/float64(maxLen)
+
+	tokensA := make(map[string]bool)
+	for _, t := range strings.Fields(na) {
+		tokensA[t] = true
+	}
+	tokensB := make(map[string]bool)
+	for _, t := range strings.Fields(nb) {
+		tokensB[t] = true
+	}
+	intersection := 0
+	for t := range tokensA {
+		if tokensB[t] {
+			intersection++
+		}
+	}
+	union := len(tokensA) + len(tokensB) - intersection
+	jaccard := float64(intersection) / float64(union)
+
+	if jaccard > editRatio {
+		return jaccard
+	}
+	return editRatio
+}
+
+// levenshtein menghitung jarak edit antara dua rangkaian rune.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
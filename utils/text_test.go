@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"  Cimol   Bojot  ", "cimol bojot"},
+		{"Warung Kopi (Pak Eko) - Braga!", "warung kopi pak eko braga"},
+		{"Kafé Ñoño 99", "kafé ñoño 99"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := NormalizeName(tt.in); got != tt.want {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"identical", "Cimol Bojot", "Cimol Bojot", 1},
+		{"case and punctuation only", "Cimol Bojot!", "cimol   BOJOT", 1},
+		{"reordered tokens", "Cimol Bojot Aa", "Aa Cimol Bojot", 1},
+		{"one typo", "Braga", "Brage", 0.8},
+		{"edit ratio beats jaccard", "Kopi Aroma", "Kopi Kenangan", 1 - 7.0/13}, // Jaccard hanya 1/3
+		{"unrelated", "abc", "xyz", 0},
+		{"empty a", "", "Braga", 0},
+		{"punctuation only", "!!!", "!!!", 0},
+		{"multibyte counted as runes", "kafé", "kafe", 0.75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NameSimilarity(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("NameSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if reverse := NameSimilarity(tt.b, tt.a); math.Abs(reverse-got) > 1e-9 {
+				t.Errorf("NameSimilarity is not symmetric: %v vs %v", got, reverse)
+			}
+		})
+	}
+}